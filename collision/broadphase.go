@@ -0,0 +1,28 @@
+package collision
+
+import "github.com/oakmound/oak/v4/alg/floatgeom"
+
+// A Broadphase stores spaces for a Tree and finds which spaces might collide.
+// Tree handles locking, so broadphases need not be safe for concurrent use.
+type Broadphase interface {
+	// Insert adds a space.
+	Insert(*Space)
+	// Delete removes a space, returning whether it was present. Delete is
+	// called before a space's location is changed.
+	Delete(*Space) bool
+	// SearchIntersect returns all spaces intersecting a rectangle.
+	SearchIntersect(floatgeom.Rect3) []*Space
+	// NearestNeighbor returns the space closest to a point.
+	NearestNeighbor(floatgeom.Point3) *Space
+	// NearestNeighbors returns the k spaces closest to a point, nearest first.
+	NearestNeighbors(k int, p floatgeom.Point3) []*Space
+	// Size returns how many spaces are stored.
+	Size() int
+	// Clear removes all spaces.
+	Clear()
+}
+
+var (
+	_ Broadphase = &Rtree{}
+	_ Broadphase = &SpatialHash{}
+)
@@ -77,3 +77,10 @@ func WithoutLabels(ls ...Label) Filter {
 		return true
 	})
 }
+
+// WithLayers will only return spaces that a space on the given layers, with
+// the given mask, can hit. See CanHit.
+func WithLayers(layers, mask Layers) Filter {
+	hitter := &Space{Layers: layers, Mask: mask}
+	return With(hitter.CanHit)
+}
@@ -0,0 +1,4 @@
+// Package fov computes what can be seen from a point, either as a visibility
+// polygon against occluding spaces in a collision tree, or as the visible cells
+// of a tile grid, for fog of war and AI perception.
+package fov
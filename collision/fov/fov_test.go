@@ -0,0 +1,101 @@
+package fov
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/alg/intgeom"
+	"github.com/oakmound/oak/v4/collision"
+)
+
+func TestVisibility(t *testing.T) {
+	tree := collision.NewTree()
+	wall := collision.NewLabeledSpace(20, -10, 10, 20, 1)
+	glass := collision.NewLabeledSpace(-30, -5, 5, 10, 2)
+	tree.Add(wall, glass)
+	v := NewViewer(Tree(tree), Occluders(1), Radius(100))
+	vis := v.Visibility(floatgeom.Point2{})
+
+	type testCase struct {
+		p       floatgeom.Point2
+		visible bool
+	}
+	for name, tc := range map[string]testCase{
+		"near":          {floatgeom.Point2{10, 0}, true},
+		"behind wall":   {floatgeom.Point2{50, 0}, false},
+		"wall shadow":   {floatgeom.Point2{60, 15}, false},
+		"beside wall":   {floatgeom.Point2{50, 30}, true},
+		"through glass": {floatgeom.Point2{-50, 0}, true},
+		"out of range":  {floatgeom.Point2{0, 120}, false},
+	} {
+		if vis.Contains(tc.p) != tc.visible {
+			t.Fatalf("%v: expected visible %v", name, tc.visible)
+		}
+		if v.CanSee(floatgeom.Point2{}, tc.p) != tc.visible {
+			t.Fatalf("%v: expected CanSee %v", name, tc.visible)
+		}
+	}
+	for _, p := range vis.Points {
+		if p.Magnitude() > 100+1e-9 {
+			t.Fatalf("expected visibility within radius, got %v", p)
+		}
+		if p.X() > 20+1e-9 && math.Abs(p.Y()) < 10-1e-9 {
+			t.Fatalf("expected visibility to stop at wall, got %v", p)
+		}
+	}
+}
+
+func TestVisibilityCone(t *testing.T) {
+	v := NewViewer(Tree(collision.NewTree()), Radius(50), Cone(math.Pi/2, math.Pi/2))
+	vis := v.Visibility(floatgeom.Point2{})
+	if vis.Points[0] != (floatgeom.Point2{}) {
+		t.Fatalf("expected cone to include its origin")
+	}
+	for _, tc := range []struct {
+		p       floatgeom.Point2
+		visible bool
+	}{
+		{floatgeom.Point2{0, 20}, true},
+		{floatgeom.Point2{10, 20}, true},
+		{floatgeom.Point2{0, -20}, false},
+		{floatgeom.Point2{20, 5}, false},
+	} {
+		if vis.Contains(tc.p) != tc.visible || v.CanSee(floatgeom.Point2{}, tc.p) != tc.visible {
+			t.Fatalf("expected %v to be visible %v", tc.p, tc.visible)
+		}
+	}
+}
+
+func TestShadowcast(t *testing.T) {
+	walls := map[intgeom.Point2]bool{
+		{2, 0}: true,
+	}
+	opaque := func(c intgeom.Point2) bool { return walls[c] }
+	visible := VisibleCells(intgeom.Point2{}, 5, opaque)
+	for c, expected := range map[intgeom.Point2]bool{
+		{0, 0}:  true,
+		{1, 0}:  true,
+		{2, 0}:  true,
+		{3, 0}:  false,
+		{5, 0}:  false,
+		{3, 3}:  true,
+		{-5, 0}: true,
+		{0, -5}: true,
+		{4, 4}:  false,
+	} {
+		if visible[c] != expected {
+			t.Fatalf("expected cell %v to be visible %v", c, expected)
+		}
+	}
+
+	open := VisibleCells(intgeom.Point2{10, 10}, 3, func(intgeom.Point2) bool { return false })
+	for x := 7; x <= 13; x++ {
+		for y := 7; y <= 13; y++ {
+			dx, dy := x-10, y-10
+			if open[intgeom.Point2{x, y}] != (dx*dx+dy*dy <= 9) {
+				t.Fatalf("expected open room to reveal the circle around its origin, failed at %v,%v", x, y)
+			}
+		}
+	}
+}
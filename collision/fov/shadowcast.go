@@ -0,0 +1,88 @@
+package fov
+
+import (
+	"github.com/oakmound/oak/v4/alg/intgeom"
+)
+
+// octants transform the cells scanned in the first octant into each of the
+// eight octants around an origin.
+var octants = [8][4]int{
+	{1, 0, 0, 1},
+	{0, 1, 1, 0},
+	{0, -1, 1, 0},
+	{-1, 0, 0, 1},
+	{-1, 0, 0, -1},
+	{0, -1, -1, 0},
+	{0, 1, -1, 0},
+	{1, 0, 0, -1},
+}
+
+// Shadowcast calls reveal for each cell of a tile grid visible from origin
+// within radius cells, using recursive shadowcasting. Opaque cells block sight
+// to the cells behind them, but are themselves revealed. A cell may be revealed
+// more than once.
+func Shadowcast(origin intgeom.Point2, radius int, opaque func(intgeom.Point2) bool, reveal func(intgeom.Point2)) {
+	reveal(origin)
+	for _, oct := range octants {
+		castLight(origin, radius, 1, 1.0, 0.0, oct, opaque, reveal)
+	}
+}
+
+// castLight scans the rows of one octant outward from row, between the start
+// and end slopes, recursing past each run of opaque cells. See "FOV using
+// recursive shadowcasting" by Björn Bergström.
+func castLight(origin intgeom.Point2, radius, row int, start, end float64, oct [4]int,
+	opaque func(intgeom.Point2) bool, reveal func(intgeom.Point2)) {
+
+	if start < end {
+		return
+	}
+	radius2 := radius * radius
+	for j := row; j <= radius; j++ {
+		dy := -j
+		blocked := false
+		newStart := 0.0
+		for dx := -j; dx <= 0; dx++ {
+			leftSlope := (float64(dx) - .5) / (float64(dy) + .5)
+			rightSlope := (float64(dx) + .5) / (float64(dy) - .5)
+			if start < rightSlope {
+				continue
+			}
+			if end > leftSlope {
+				break
+			}
+			c := intgeom.Point2{
+				origin.X() + dx*oct[0] + dy*oct[1],
+				origin.Y() + dx*oct[2] + dy*oct[3],
+			}
+			if dx*dx+dy*dy <= radius2 {
+				reveal(c)
+			}
+			if blocked {
+				if opaque(c) {
+					newStart = rightSlope
+					continue
+				}
+				blocked = false
+				start = newStart
+			} else if opaque(c) && j < radius {
+				blocked = true
+				castLight(origin, radius, j+1, start, leftSlope, oct, opaque, reveal)
+				newStart = rightSlope
+			}
+		}
+		if blocked {
+			return
+		}
+	}
+}
+
+// VisibleCells returns the set of cells of a tile grid visible from origin
+// within radius cells. See Shadowcast.
+func VisibleCells(origin intgeom.Point2, radius int, opaque func(intgeom.Point2) bool) map[intgeom.Point2]bool {
+	visible := make(map[intgeom.Point2]bool)
+	Shadowcast(origin, radius, opaque, func(c intgeom.Point2) {
+		visible[c] = true
+	})
+	return visible
+}
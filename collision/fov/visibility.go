@@ -0,0 +1,224 @@
+package fov
+
+import (
+	"math"
+	"sort"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+)
+
+// A Viewer computes what can be seen from points among occluding spaces.
+type Viewer struct {
+	// Tree holds the spaces that can block sight.
+	Tree *collision.Tree
+	// Filters narrow which spaces in Tree block sight.
+	Filters []collision.Filter
+	// Radius is how far a viewer can see.
+	Radius float64
+	// Resolution is how many points are used to approximate the circle at
+	// the edge of a viewer's sight.
+	Resolution int
+	// ConeAngle is the direction a viewer faces, in radians, and ConeSpread
+	// is how wide its sight is, centered on that direction. A ConeSpread of
+	// zero, or of 2π or more, sees all around.
+	ConeAngle, ConeSpread float64
+}
+
+// An Option modifies a Viewer.
+type Option func(*Viewer)
+
+// NewViewer returns a Viewer that sees 200 units all around among the spaces
+// of collision.DefaultTree, modified by the given options.
+func NewViewer(opts ...Option) *Viewer {
+	v := &Viewer{
+		Tree:       collision.DefaultTree,
+		Radius:     200,
+		Resolution: 64,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Tree sets the collision tree of a Viewer.
+func Tree(t *collision.Tree) Option {
+	return func(v *Viewer) {
+		v.Tree = t
+	}
+}
+
+// Occluders limits the spaces that block a Viewer's sight to those with the
+// given labels.
+func Occluders(ls ...collision.Label) Option {
+	return func(v *Viewer) {
+		v.Filters = append(v.Filters, collision.WithLabels(ls...))
+	}
+}
+
+// Radius sets how far a Viewer can see.
+func Radius(r float64) Option {
+	return func(v *Viewer) {
+		v.Radius = r
+	}
+}
+
+// Resolution sets how many points approximate the edge of a Viewer's sight.
+func Resolution(n int) Option {
+	return func(v *Viewer) {
+		v.Resolution = n
+	}
+}
+
+// Cone limits a Viewer to seeing within spread radians centered on angle.
+func Cone(angle, spread float64) Option {
+	return func(v *Viewer) {
+		v.ConeAngle = angle
+		v.ConeSpread = spread
+	}
+}
+
+// A Visibility is the region a Viewer can see from its origin.
+type Visibility struct {
+	Origin floatgeom.Point2
+	// Points outline the visible region, in order of increasing angle from
+	// the origin. If the viewer's sight is a cone, the origin is included.
+	Points []floatgeom.Point2
+}
+
+// Polygon converts a Visibility into a polygon.
+func (vis Visibility) Polygon() floatgeom.Polygon2 {
+	return floatgeom.Polygon2{
+		Bounding: floatgeom.NewBoundingRect2(vis.Points...),
+		Points:   vis.Points,
+	}
+}
+
+// Contains returns whether a point is visible.
+func (vis Visibility) Contains(p floatgeom.Point2) bool {
+	if len(vis.Points) < 3 {
+		return false
+	}
+	return vis.Polygon().Contains(p.X(), p.Y())
+}
+
+// angleEpsilon offsets the rays cast past each occluder corner, so they can
+// continue beyond the corner to what it does not block.
+const angleEpsilon = 1e-5
+
+type segment struct {
+	a, b floatgeom.Point2
+}
+
+// Visibility returns the region visible from origin. Rays are cast at each
+// corner of the occluders in range, so the result is exact for rectangles and
+// polygons, and rounded shapes are approximated, see collision.Space.Outline.
+func (v *Viewer) Visibility(origin floatgeom.Point2) Visibility {
+	segments := v.segments(origin)
+	cone := v.ConeSpread > 0 && v.ConeSpread < 2*math.Pi
+	start := 0.0
+	spread := 2 * math.Pi
+	if cone {
+		start = v.ConeAngle - v.ConeSpread/2
+		spread = v.ConeSpread
+	}
+
+	// Angles are kept relative to the start of the cone
+	angles := []float64{}
+	addAngle := func(a float64) {
+		rel := math.Mod(a-start, 2*math.Pi)
+		if rel < 0 {
+			rel += 2 * math.Pi
+		}
+		if rel <= spread {
+			angles = append(angles, rel)
+		}
+	}
+	for i := 0; i < v.Resolution; i++ {
+		addAngle(start + spread*float64(i)/float64(v.Resolution))
+	}
+	if cone {
+		angles = append(angles, spread)
+	}
+	for _, s := range segments {
+		a := math.Atan2(s.a.Y()-origin.Y(), s.a.X()-origin.X())
+		addAngle(a - angleEpsilon)
+		addAngle(a)
+		addAngle(a + angleEpsilon)
+	}
+	sort.Float64s(angles)
+
+	vis := Visibility{Origin: origin}
+	if cone {
+		vis.Points = append(vis.Points, origin)
+	}
+	for _, rel := range angles {
+		a := start + rel
+		dir := floatgeom.Point2{math.Cos(a), math.Sin(a)}
+		vis.Points = append(vis.Points, origin.Add(dir.MulConst(castSegments(origin, dir, v.Radius, segments))))
+	}
+	return vis
+}
+
+// CanSee returns whether target is visible from origin, without computing the
+// whole visible region.
+func (v *Viewer) CanSee(origin, target floatgeom.Point2) bool {
+	delta := target.Sub(origin)
+	dist := delta.Magnitude()
+	if dist > v.Radius {
+		return false
+	}
+	if dist == 0 {
+		return true
+	}
+	if v.ConeSpread > 0 && v.ConeSpread < 2*math.Pi {
+		diff := math.Mod(math.Atan2(delta.Y(), delta.X())-v.ConeAngle, 2*math.Pi)
+		if diff > math.Pi {
+			diff -= 2 * math.Pi
+		} else if diff < -math.Pi {
+			diff += 2 * math.Pi
+		}
+		if math.Abs(diff) > v.ConeSpread/2 {
+			return false
+		}
+	}
+	return castSegments(origin, delta.DivConst(dist), dist, v.segments(origin)) >= dist
+}
+
+// segments returns the edges of the occluders within the viewer's radius.
+func (v *Viewer) segments(origin floatgeom.Point2) []segment {
+	r := v.Radius
+	area := collision.NewUnassignedSpace(origin.X()-r, origin.Y()-r, 2*r, 2*r)
+	segments := []segment{}
+	for _, s := range v.Tree.Hit(area, v.Filters...) {
+		pts := s.Outline()
+		for i, p := range pts {
+			segments = append(segments, segment{p, pts[(i+1)%len(pts)]})
+		}
+	}
+	return segments
+}
+
+// castSegments returns how far a ray travels before hitting a segment, up to
+// dist.
+func castSegments(origin, dir floatgeom.Point2, dist float64, segments []segment) float64 {
+	for _, s := range segments {
+		e := s.b.Sub(s.a)
+		denom := cross(dir, e)
+		if denom == 0 {
+			continue
+		}
+		q := s.a.Sub(origin)
+		t := cross(q, e) / denom
+		u := cross(q, dir) / denom
+		if t >= 0 && t < dist && u >= 0 && u <= 1 {
+			dist = t
+		}
+	}
+	return dist
+}
+
+func cross(a, b floatgeom.Point2) float64 {
+	return a.X()*b.Y() - a.Y()*b.X()
+}
@@ -0,0 +1,77 @@
+package collision
+
+import "math/bits"
+
+// Layers is a bitmask of up to 32 collision layers. Spaces on layers only collide
+// with spaces on the layers in their Mask, and only when the layer matrix, see
+// SetLayersInteract, allows their layers to interact.
+type Layers uint32
+
+// AllLayers includes every collision layer.
+const AllLayers Layers = 1<<32 - 1
+
+// Layer returns the Layers containing only the i'th layer, from 0 to 31.
+func Layer(i int) Layers {
+	return 1 << uint(i)
+}
+
+// layerMatrix holds, for each layer, the layers it can interact with.
+var layerMatrix = func() (m [32]Layers) {
+	for i := range m {
+		m[i] = AllLayers
+	}
+	return m
+}()
+
+// SetLayersInteract sets whether spaces on any of the layers in a can collide
+// with spaces on any of the layers in b. By default, all layers interact. The
+// layer matrix is shared by all trees, and should be set up before collisions
+// are checked, as it is not safe to modify concurrently with those checks.
+func SetLayersInteract(a, b Layers, interact bool) {
+	forEachLayer(a, func(i int) {
+		if interact {
+			layerMatrix[i] |= b
+		} else {
+			layerMatrix[i] &^= b
+		}
+	})
+	forEachLayer(b, func(i int) {
+		if interact {
+			layerMatrix[i] |= a
+		} else {
+			layerMatrix[i] &^= a
+		}
+	})
+}
+
+// LayersInteract returns whether any layer in a can interact with any layer in b.
+func LayersInteract(a, b Layers) bool {
+	interact := false
+	forEachLayer(a, func(i int) {
+		if layerMatrix[i]&b != 0 {
+			interact = true
+		}
+	})
+	return interact
+}
+
+func forEachLayer(l Layers, fn func(int)) {
+	for l != 0 {
+		i := bits.TrailingZeros32(uint32(l))
+		fn(i)
+		l &^= 1 << uint(i)
+	}
+}
+
+// CanHit returns whether this space's layers allow it to hit another space. A
+// space on no layers is not filtered by layers, and a space with no Mask
+// collides with all layers.
+func (s *Space) CanHit(other *Space) bool {
+	if other.Layers == 0 {
+		return true
+	}
+	if s.Mask != 0 && s.Mask&other.Layers == 0 {
+		return false
+	}
+	return s.Layers == 0 || LayersInteract(s.Layers, other.Layers)
+}
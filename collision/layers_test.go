@@ -0,0 +1,53 @@
+package collision
+
+import (
+	"testing"
+)
+
+func TestLayers(t *testing.T) {
+	const (
+		player = 1 << iota
+		enemy
+		wall
+	)
+	SetLayersInteract(enemy, enemy, false)
+	defer SetLayersInteract(AllLayers, AllLayers, true)
+
+	tr := NewTree()
+	p := NewLabeledSpace(0, 0, 10, 10, 1)
+	p.Layers = player
+	e1 := NewLabeledSpace(0, 0, 10, 10, 2)
+	e1.Layers = enemy
+	e2 := NewLabeledSpace(0, 0, 10, 10, 3)
+	e2.Layers = enemy
+	w := NewLabeledSpace(0, 0, 10, 10, 4)
+	w.Layers = wall
+	plain := NewLabeledSpace(0, 0, 10, 10, 5)
+	tr.Add(p, e1, e2, w, plain)
+
+	if hits := tr.Hits(e1); len(hits) != 3 {
+		t.Fatalf("expected enemy to hit player, wall and unlayered space, got %v", hits)
+	}
+	p.Mask = wall
+	if hits := tr.Hits(p); len(hits) != 2 {
+		t.Fatalf("expected masked player to hit wall and unlayered space, got %v", hits)
+	}
+	if sp := tr.HitLabel(p, 2, 3); sp != nil {
+		t.Fatalf("expected masked player not to hit enemies, got %v", sp)
+	}
+	if hits := tr.Hit(p, WithLabels(2, 3, 4)); len(hits) != 1 || hits[0] != w {
+		t.Fatalf("expected masked player to hit only wall, got %v", hits)
+	}
+	if hits := tr.Hits(plain); len(hits) != 4 {
+		t.Fatalf("expected unlayered space to hit everything, got %v", hits)
+	}
+	if !e1.CanHit(p) || p.CanHit(e1) {
+		t.Fatalf("expected masks to only apply from the hitting space")
+	}
+	if LayersInteract(enemy, enemy) || !LayersInteract(enemy|player, enemy) {
+		t.Fatalf("unexpected layer interaction")
+	}
+	if Layer(2) != wall {
+		t.Fatalf("expected Layer(2) to be %v, got %v", wall, Layer(2))
+	}
+}
@@ -0,0 +1,92 @@
+package collision
+
+import (
+	"math"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/event"
+)
+
+// A Side is a side of a space another space is touching.
+type Side uint8
+
+// Sides of a space.
+const (
+	SideNone Side = iota
+	SideLeft
+	SideRight
+	SideTop
+	SideBottom
+)
+
+func (s Side) String() string {
+	switch s {
+	case SideLeft:
+		return "left"
+	case SideRight:
+		return "right"
+	case SideTop:
+		return "top"
+	case SideBottom:
+		return "bottom"
+	}
+	return "none"
+}
+
+// A Manifold describes how a space is touching another.
+type Manifold struct {
+	// Space is the other space touched.
+	Space *Space
+	// CID is the caller ID of the other space.
+	CID event.CallerID
+	// Overlap is the intersection of the two spaces' rectangles.
+	Overlap floatgeom.Rect2
+	// Penetration is the shortest movement which would separate this space
+	// from the other.
+	Penetration floatgeom.Point2
+	// Side is the side of this space the other space is touching.
+	Side Side
+}
+
+// NewManifold returns how the space s is touching other. Manifolds are
+// computed from the spaces' rectangles, ignoring their shapes. If the spaces
+// do not overlap, the manifold will have no Overlap and SideNone.
+func NewManifold(s, other *Space) Manifold {
+	m := Manifold{
+		Space: other,
+		CID:   other.CID,
+	}
+	a, b := s.Location.ProjectZ(), other.Location.ProjectZ()
+	min := floatgeom.Point2{
+		math.Max(a.Min.X(), b.Min.X()),
+		math.Max(a.Min.Y(), b.Min.Y()),
+	}
+	max := floatgeom.Point2{
+		math.Min(a.Max.X(), b.Max.X()),
+		math.Min(a.Max.Y(), b.Max.Y()),
+	}
+	if min.X() >= max.X() || min.Y() >= max.Y() {
+		return m
+	}
+	m.Overlap = floatgeom.Rect2{Min: min, Max: max}
+	w, h := m.Overlap.W(), m.Overlap.H()
+	ca, cb := a.Center(), b.Center()
+	if w < h {
+		if cb.X() < ca.X() {
+			m.Side = SideLeft
+			m.Penetration = floatgeom.Point2{w, 0}
+		} else {
+			m.Side = SideRight
+			m.Penetration = floatgeom.Point2{-w, 0}
+		}
+	} else {
+		if cb.Y() < ca.Y() {
+			m.Side = SideTop
+			m.Penetration = floatgeom.Point2{0, h}
+		} else {
+			m.Side = SideBottom
+			m.Penetration = floatgeom.Point2{0, -h}
+		}
+	}
+	return m
+}
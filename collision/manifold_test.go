@@ -0,0 +1,51 @@
+package collision
+
+import (
+	"testing"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+)
+
+func TestNewManifold(t *testing.T) {
+	s := NewSpace(0, 0, 10, 10, 1)
+	type testCase struct {
+		other       *Space
+		side        Side
+		penetration floatgeom.Point2
+		overlap     floatgeom.Rect2
+	}
+	tcs := map[string]testCase{
+		"left": {
+			other: NewSpace(-8, 2, 10, 4, 2), side: SideLeft,
+			penetration: floatgeom.Point2{2, 0}, overlap: floatgeom.NewRect2(0, 2, 2, 6),
+		},
+		"right": {
+			other: NewSpace(7, 0, 10, 10, 2), side: SideRight,
+			penetration: floatgeom.Point2{-3, 0}, overlap: floatgeom.NewRect2(7, 0, 10, 10),
+		},
+		"top": {
+			other: NewSpace(0, -9, 10, 10, 2), side: SideTop,
+			penetration: floatgeom.Point2{0, 1}, overlap: floatgeom.NewRect2(0, 0, 10, 1),
+		},
+		"bottom": {
+			other: NewSpace(2, 6, 20, 20, 2), side: SideBottom,
+			penetration: floatgeom.Point2{0, -4}, overlap: floatgeom.NewRect2(2, 6, 10, 10),
+		},
+		"apart": {
+			other: NewSpace(20, 20, 2, 2, 2),
+		},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			m := NewManifold(s, tc.other)
+			if m.Space != tc.other || m.CID != 2 {
+				t.Fatalf("expected manifold to reference other space")
+			}
+			if m.Side != tc.side || m.Penetration != tc.penetration || m.Overlap != tc.overlap {
+				t.Fatalf("expected %v %v %v, got %v %v %v",
+					tc.side, tc.penetration, tc.overlap, m.Side, m.Penetration, m.Overlap)
+			}
+		})
+	}
+}
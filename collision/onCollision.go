@@ -16,6 +16,8 @@ type Phase struct {
 	// we can have two constant maps that we
 	// switch between on alternating frames
 	Touching map[Label]bool
+	// Contacts holds how each space touched last frame was touched.
+	Contacts map[*Space]Manifold
 }
 
 func (cp *Phase) getCollisionPhase() *Phase {
@@ -61,6 +63,15 @@ var (
 	Stop  = event.RegisterEvent[Label]()
 )
 
+// ContactStart/Stay/Stop: when a PhaseCollision entity starts touching, is still
+// touching, or stops touching some space. Stay is triggered each frame the
+// spaces remain touching, and Stop carries the last manifold of the contact.
+var (
+	ContactStart = event.RegisterEvent[Manifold]()
+	ContactStay  = event.RegisterEvent[Manifold]()
+	ContactStop  = event.RegisterEvent[Manifold]()
+)
+
 func phaseCollisionEnter(id event.CallerID, handler event.Handler, _ interface{}) event.Response {
 	e := handler.GetCallerMap().GetEntity(id).(collisionPhase)
 	oc := e.getCollisionPhase()
@@ -68,6 +79,7 @@ func phaseCollisionEnter(id event.CallerID, handler event.Handler, _ interface{}
 	// check hits
 	hits := oc.tree.Hits(oc.OnCollisionS)
 	newTouching := map[Label]bool{}
+	newContacts := make(map[*Space]Manifold, len(hits))
 
 	// if any are new, trigger on collision
 	for _, h := range hits {
@@ -76,6 +88,14 @@ func phaseCollisionEnter(id event.CallerID, handler event.Handler, _ interface{}
 			event.TriggerForCallerOn(oc.bus, id, Start, l)
 		}
 		newTouching[l] = true
+
+		m := NewManifold(oc.OnCollisionS, h)
+		if _, ok := oc.Contacts[h]; !ok {
+			event.TriggerForCallerOn(oc.bus, id, ContactStart, m)
+		} else {
+			event.TriggerForCallerOn(oc.bus, id, ContactStay, m)
+		}
+		newContacts[h] = m
 	}
 
 	// if we lost any, trigger off collision
@@ -85,7 +105,14 @@ func phaseCollisionEnter(id event.CallerID, handler event.Handler, _ interface{}
 		}
 	}
 
+	for sp, m := range oc.Contacts {
+		if _, ok := newContacts[sp]; !ok {
+			event.TriggerForCallerOn(oc.bus, id, ContactStop, m)
+		}
+	}
+
 	oc.Touching = newTouching
+	oc.Contacts = newContacts
 
 	return 0
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/oakmound/oak/v4/alg/floatgeom"
 	"github.com/oakmound/oak/v4/event"
 )
 
@@ -52,6 +53,59 @@ func TestCollisionPhase(t *testing.T) {
 	}
 }
 
+func TestCollisionPhaseContacts(t *testing.T) {
+	b := event.NewBus(event.NewCallerMap())
+	cp := &cphase{}
+	cid := b.GetCallerMap().Register(cp)
+	s := NewSpace(10, 10, 10, 10, cid)
+	tree := NewTree()
+	err := PhaseCollisionWithBus(s, tree, b)
+	if err != nil {
+		t.Fatalf("phase collision failed: %v", err)
+	}
+	contacts := make(chan string, 5)
+	manifolds := make(chan Manifold, 5)
+	for name, ev := range map[string]event.EventID[Manifold]{
+		"start": ContactStart,
+		"stay":  ContactStay,
+		"stop":  ContactStop,
+	} {
+		name := name
+		bd := event.Bind(b, ev, cp, func(_ *cphase, m Manifold) event.Response {
+			contacts <- name
+			manifolds <- m
+			return 0
+		})
+		<-bd.Bound
+	}
+	// Let the phase binding settle
+	time.Sleep(50 * time.Millisecond)
+	expect := func(name string) Manifold {
+		t.Helper()
+		<-event.TriggerOn(b, event.Enter, event.EnterPayload{})
+		select {
+		case got := <-contacts:
+			if got != name {
+				t.Fatalf("expected contact %v, got %v", name, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected contact %v", name)
+		}
+		return <-manifolds
+	}
+	s2 := NewLabeledSpace(18, 12, 10, 4, 5)
+	tree.Add(s2)
+	m := expect("start")
+	if m.Space != s2 || m.Side != SideRight || m.Penetration != (floatgeom.Point2{-2, 0}) {
+		t.Fatalf("unexpected start manifold %v", m)
+	}
+	expect("stay")
+	tree.Remove(s2)
+	if m := expect("stop"); m.Space != s2 {
+		t.Fatalf("expected stop manifold for removed space, got %v", m)
+	}
+}
+
 func TestPhaseCollision_Unembedded(t *testing.T) {
 	t.Parallel()
 	s3 := NewSpace(10, 10, 10, 10, 5)
@@ -39,6 +39,9 @@ type Caster struct {
 	CastDistance float64
 	Tree         *collision.Tree
 	CenterPoints bool
+	// Layers and Mask act as a cast ray's collision layers and mask,
+	// see collision.Space.CanHit.
+	Layers, Mask collision.Layers
 }
 
 // A CastOption represents a transformation to a ray caster.
@@ -68,6 +71,7 @@ func (c *Caster) CastTo(origin, target floatgeom.Point2) []collision.Point {
 func (c *Caster) Cast(origin, angle floatgeom.Point2) []collision.Point {
 	points := make([]collision.Point, 0)
 	resultHash := make(map[*collision.Space]bool)
+	ray := &collision.Space{Layers: c.Layers, Mask: c.Mask}
 
 	x := origin.X()
 	y := origin.Y()
@@ -84,6 +88,12 @@ func (c *Caster) Cast(origin, angle floatgeom.Point2) []collision.Point {
 	hitLoop:
 		for k := 0; k < len(hits); k++ {
 			next := hits[k]
+			if !ray.CanHit(next) {
+				continue
+			}
+			if next.Shape != nil && !next.Collides(collision.NewUnassignedSpace(x, y, c.PointSize.X(), c.PointSize.Y())) {
+				continue
+			}
 			if _, ok := resultHash[next]; !ok {
 				resultHash[next] = true
 
@@ -108,6 +118,32 @@ func (c *Caster) Cast(origin, angle floatgeom.Point2) []collision.Point {
 	return points
 }
 
+// CastExact acts like Cast, but finds exactly where the ray meets each space and
+// the normal of its surface there, rather than sampling points along the ray.
+// Thin spaces cannot be skipped, and PointSize, PointSpan and CenterPoints do
+// not apply. See collision.Tree.Raycast.
+func (c *Caster) CastExact(origin, angle floatgeom.Point2) []collision.RayHit {
+	out := make([]collision.RayHit, 0)
+	points := make([]collision.Point, 0)
+
+hitLoop:
+	for _, hit := range c.Tree.Raycast(origin, angle, c.CastDistance, collision.WithLayers(c.Layers, c.Mask)) {
+		for _, f := range c.Filters {
+			if !f(hit.Space) {
+				continue hitLoop
+			}
+		}
+		out = append(out, hit)
+		points = append(points, collision.NewPoint(hit.Space, hit.Point.X(), hit.Point.Y()))
+		for _, l := range c.Limits {
+			if !l(points) {
+				return out
+			}
+		}
+	}
+	return out
+}
+
 // Copy copies a Caster.
 func (c *Caster) Copy() *Caster {
 	c2 := new(Caster)
@@ -141,6 +177,15 @@ func CenterPoints(on bool) CastOption {
 	}
 }
 
+// Layers sets the collision layers and mask of a Caster's rays, so they only hit
+// spaces on the layers they can collide with.
+func Layers(layers, mask collision.Layers) CastOption {
+	return func(c *Caster) {
+		c.Layers = layers
+		c.Mask = mask
+	}
+}
+
 // Distance determines how far a caster will project rays before stopping
 func Distance(dist float64) CastOption {
 	return func(c *Caster) {
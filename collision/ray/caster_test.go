@@ -229,3 +229,67 @@ func TestNewCasterDefaultTree(t *testing.T) {
 		t.Fatal("nil caster tree should have been set to default tree")
 	}
 }
+
+func TestCasterShapes(t *testing.T) {
+	tree := collision.NewTree()
+	circle := collision.NewCircleSpace(20, 20, 10, 1)
+	tree.Add(circle)
+	c := NewCaster(Tree(tree), Distance(50))
+	// This ray passes through the corner of the circle's rectangle, but not the circle
+	if pts := c.Cast(floatgeom.Point2{22, 0}, floatgeom.Point2{-1, 1}); len(pts) != 0 {
+		t.Fatalf("expected ray to miss circle, got %v", pts)
+	}
+	pts := c.Cast(floatgeom.Point2{0, 20}, floatgeom.Point2{1, 0})
+	if len(pts) != 1 || pts[0].Zone != circle {
+		t.Fatalf("expected ray to hit circle, got %v", pts)
+	}
+	if pts[0].X() < 10 {
+		t.Fatalf("expected ray to hit circle at its edge, got %v", pts[0])
+	}
+}
+
+func TestCasterLayers(t *testing.T) {
+	tree := collision.NewTree()
+	wall := collision.NewSpace(10, 0, 2, 2, 1)
+	wall.Layers = collision.Layer(1)
+	glass := collision.NewSpace(5, 0, 2, 2, 2)
+	glass.Layers = collision.Layer(2)
+	tree.Add(wall, glass)
+	c := NewCaster(Tree(tree), Distance(20))
+	if pts := c.Cast(floatgeom.Point2{0, 1}, floatgeom.Point2{1, 0}); len(pts) != 2 {
+		t.Fatalf("expected unmasked ray to hit both spaces, got %v", pts)
+	}
+	c = NewCaster(Tree(tree), Distance(20), Layers(0, collision.Layer(1)))
+	pts := c.Cast(floatgeom.Point2{0, 1}, floatgeom.Point2{1, 0})
+	if len(pts) != 1 || pts[0].Zone != wall {
+		t.Fatalf("expected masked ray to hit only wall, got %v", pts)
+	}
+}
+
+func TestCasterCastExact(t *testing.T) {
+	tree := collision.NewTree()
+	// This space lies between the points a sampled ray checks
+	thin := collision.NewLabeledSpace(10.3, 0, .01, 10, 1)
+	thin.Location.Max[0] = 10.31
+	wall := collision.NewLabeledSpace(20, 0, 2, 10, 2)
+	wall.Layers = collision.Layer(1)
+	far := collision.NewLabeledSpace(30, 0, 2, 10, 3)
+	tree.Add(thin, wall, far)
+	c := NewCaster(Tree(tree), Distance(50))
+	if pts := c.Cast(floatgeom.Point2{0, 5}, floatgeom.Point2{1, 0}); len(pts) != 2 {
+		t.Fatalf("expected sampled ray to skip thin space, got %v", pts)
+	}
+	hits := c.CastExact(floatgeom.Point2{0, 5}, floatgeom.Point2{1, 0})
+	if len(hits) != 3 || hits[0].Space != thin || hits[0].Point != (floatgeom.Point2{10.3, 5}) {
+		t.Fatalf("expected exact ray to hit thin space first, got %v", hits)
+	}
+	c = NewCaster(Tree(tree), Distance(50), IgnoreLabels(1), LimitResults(1))
+	hits = c.CastExact(floatgeom.Point2{0, 5}, floatgeom.Point2{1, 0})
+	if len(hits) != 1 || hits[0].Space != wall || hits[0].Normal != (floatgeom.Point2{-1, 0}) {
+		t.Fatalf("expected filtered, limited ray to hit wall, got %v", hits)
+	}
+	c = NewCaster(Tree(tree), Distance(50), Layers(0, collision.Layer(2)))
+	if hits := c.CastExact(floatgeom.Point2{0, 5}, floatgeom.Point2{1, 0}); len(hits) != 2 {
+		t.Fatalf("expected masked ray to skip wall, got %v", hits)
+	}
+}
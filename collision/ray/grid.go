@@ -0,0 +1,97 @@
+package ray
+
+import (
+	"math"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/alg/intgeom"
+)
+
+// A GridHit is a cell of a grid a ray passes through.
+type GridHit struct {
+	// Cell is the index of the cell, where cell 0,0 spans from the origin to
+	// the grid's cell size.
+	Cell intgeom.Point2
+	// Point is where the ray enters the cell.
+	Point floatgeom.Point2
+	// Normal points out of the side of the cell the ray entered through. The
+	// cell the ray starts in has no normal.
+	Normal floatgeom.Point2
+	// Distance is how far along the ray Point is.
+	Distance float64
+}
+
+// TraverseGrid visits every cell of a grid of cellSize cells that a ray from
+// origin in the direction dir passes through within dist, in order, until visit
+// returns false. Cells are stepped through exactly, following "A Fast Voxel
+// Traversal Algorithm for Ray Tracing" by Amanatides and Woo, so no cell the ray
+// touches is skipped.
+func TraverseGrid(origin, dir floatgeom.Point2, dist float64, cellSize floatgeom.Point2, visit func(GridHit) bool) {
+	dir = dir.Normalize()
+	if dir.Magnitude() == 0 || cellSize.X() <= 0 || cellSize.Y() <= 0 {
+		return
+	}
+	hit := GridHit{
+		Cell: intgeom.Point2{
+			int(math.Floor(origin.X() / cellSize.X())),
+			int(math.Floor(origin.Y() / cellSize.Y())),
+		},
+		Point: origin,
+	}
+	var (
+		step   [2]int
+		tMax   [2]float64
+		tDelta [2]float64
+	)
+	for axis := 0; axis < 2; axis++ {
+		d := dir[axis]
+		switch {
+		case d > 0:
+			step[axis] = 1
+			next := float64(hit.Cell[axis]+1) * cellSize[axis]
+			tMax[axis] = (next - origin[axis]) / d
+			tDelta[axis] = cellSize[axis] / d
+		case d < 0:
+			step[axis] = -1
+			next := float64(hit.Cell[axis]) * cellSize[axis]
+			tMax[axis] = (next - origin[axis]) / d
+			tDelta[axis] = -cellSize[axis] / d
+		default:
+			tMax[axis] = math.Inf(1)
+			tDelta[axis] = math.Inf(1)
+		}
+	}
+	for hit.Distance <= dist {
+		if !visit(hit) {
+			return
+		}
+		axis := 0
+		if tMax[1] < tMax[0] {
+			axis = 1
+		}
+		hit.Distance = tMax[axis]
+		hit.Cell[axis] += step[axis]
+		hit.Point = origin.Add(dir.MulConst(hit.Distance))
+		hit.Normal = floatgeom.Point2{}
+		hit.Normal[axis] = float64(-step[axis])
+		tMax[axis] += tDelta[axis]
+	}
+}
+
+// CastGrid returns the first cell of a grid of cellSize cells that a ray from
+// origin in the direction dir passes through within dist for which solid
+// returns true. It is suited for tile maps, where each solid tile is a cell.
+func CastGrid(origin, dir floatgeom.Point2, dist float64, cellSize floatgeom.Point2, solid func(intgeom.Point2) bool) (GridHit, bool) {
+	var (
+		found GridHit
+		ok    bool
+	)
+	TraverseGrid(origin, dir, dist, cellSize, func(h GridHit) bool {
+		if solid(h.Cell) {
+			found, ok = h, true
+			return false
+		}
+		return true
+	})
+	return found, ok
+}
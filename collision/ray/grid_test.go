@@ -0,0 +1,64 @@
+package ray
+
+import (
+	"testing"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/alg/intgeom"
+)
+
+func TestTraverseGrid(t *testing.T) {
+	cells := []intgeom.Point2{}
+	TraverseGrid(floatgeom.Point2{5, 5}, floatgeom.Point2{1, .5}, 25, floatgeom.Point2{10, 10}, func(h GridHit) bool {
+		cells = append(cells, h.Cell)
+		return true
+	})
+	expected := []intgeom.Point2{{0, 0}, {1, 0}, {1, 1}, {2, 1}}
+	if len(cells) != len(expected) {
+		t.Fatalf("expected cells %v, got %v", expected, cells)
+	}
+	for i := range cells {
+		if cells[i] != expected[i] {
+			t.Fatalf("expected cells %v, got %v", expected, cells)
+		}
+	}
+
+	visited := 0
+	TraverseGrid(floatgeom.Point2{-5, 5}, floatgeom.Point2{-1, 0}, 100, floatgeom.Point2{10, 10}, func(h GridHit) bool {
+		visited++
+		return h.Cell.X() > -3
+	})
+	if visited != 3 {
+		t.Fatalf("expected traversal to stop after three cells, visited %v", visited)
+	}
+}
+
+func TestCastGrid(t *testing.T) {
+	solid := map[intgeom.Point2]bool{
+		{3, 0}: true,
+		{0, 2}: true,
+	}
+	isSolid := func(c intgeom.Point2) bool { return solid[c] }
+	size := floatgeom.Point2{16, 16}
+
+	hit, ok := CastGrid(floatgeom.Point2{8, 8}, floatgeom.Point2{1, 0}, 100, size, isSolid)
+	if !ok || hit.Cell != (intgeom.Point2{3, 0}) {
+		t.Fatalf("expected to hit tile 3,0, got %v %v", hit, ok)
+	}
+	if hit.Point != (floatgeom.Point2{48, 8}) || hit.Normal != (floatgeom.Point2{-1, 0}) || hit.Distance != 40 {
+		t.Fatalf("unexpected hit %v", hit)
+	}
+	hit, ok = CastGrid(floatgeom.Point2{8, 8}, floatgeom.Point2{0, 1}, 100, size, isSolid)
+	if !ok || hit.Cell != (intgeom.Point2{0, 2}) || hit.Normal != (floatgeom.Point2{0, -1}) {
+		t.Fatalf("expected to hit tile 0,2 from above, got %v %v", hit, ok)
+	}
+	if _, ok := CastGrid(floatgeom.Point2{8, 8}, floatgeom.Point2{1, 0}, 30, size, isSolid); ok {
+		t.Fatalf("expected short ray not to reach tile")
+	}
+	if _, ok := CastGrid(floatgeom.Point2{8, 8}, floatgeom.Point2{-1, -1}, 100, size, isSolid); ok {
+		t.Fatalf("expected ray to hit nothing")
+	}
+	if hit, ok := CastGrid(floatgeom.Point2{50, 5}, floatgeom.Point2{1, 0}, 100, size, isSolid); !ok || hit.Distance != 0 {
+		t.Fatalf("expected ray starting in a tile to hit it immediately, got %v %v", hit, ok)
+	}
+}
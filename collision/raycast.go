@@ -0,0 +1,214 @@
+package collision
+
+import (
+	"math"
+	"sort"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+)
+
+// A RayHit is where a ray exactly meets a space.
+type RayHit struct {
+	// Space is the space hit.
+	Space *Space
+	// Point is where the ray meets the space's surface.
+	Point floatgeom.Point2
+	// Normal points out of the space's surface at Point. Rays cast from inside
+	// a space hit it at their origin with no normal.
+	Normal floatgeom.Point2
+	// Distance is how far along the ray Point is.
+	Distance float64
+}
+
+// RayRect returns how far along a ray from origin in the direction dir it first
+// meets a rectangle, and the normal of the rectangle's surface there. dir
+// should be normalized, and hits beyond dist are ignored.
+func RayRect(origin, dir floatgeom.Point2, dist float64, r floatgeom.Rect2) (t float64, normal floatgeom.Point2, ok bool) {
+	tMin, tMax := math.Inf(-1), math.Inf(1)
+	for axis := 0; axis < 2; axis++ {
+		o, d := origin[axis], dir[axis]
+		if d == 0 {
+			if o < r.Min[axis] || o > r.Max[axis] {
+				return 0, normal, false
+			}
+			continue
+		}
+		t1, t2 := (r.Min[axis]-o)/d, (r.Max[axis]-o)/d
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		if t1 > tMin {
+			tMin = t1
+			normal = floatgeom.Point2{}
+			normal[axis] = -sign(d)
+		}
+		tMax = math.Min(tMax, t2)
+	}
+	return clipRay(tMin, tMax, dist, normal)
+}
+
+// clipRay limits the interval a ray spends within a shape to the ray's length.
+func clipRay(tMin, tMax, dist float64, normal floatgeom.Point2) (float64, floatgeom.Point2, bool) {
+	if tMin > tMax || tMax < 0 || tMin > dist {
+		return 0, floatgeom.Point2{}, false
+	}
+	if tMin < 0 {
+		return 0, floatgeom.Point2{}, true
+	}
+	return tMin, normal, true
+}
+
+// Raycast returns where a ray from origin in the direction dir, which should
+// be normalized, first meets this space within dist. Spaces with shapes are
+// hit exactly, see Shape.
+func (s *Space) Raycast(origin, dir floatgeom.Point2, dist float64) (RayHit, bool) {
+	var (
+		t      float64
+		normal floatgeom.Point2
+		ok     bool
+	)
+	if s.Shape == nil {
+		t, normal, ok = RayRect(origin, dir, dist, s.Location.ProjectZ())
+	} else {
+		min := floatgeom.Point2{s.Location.Min.X(), s.Location.Min.Y()}
+		t, normal, ok = rayShape(origin.Sub(min), dir, dist, s.Shape)
+	}
+	if !ok {
+		return RayHit{}, false
+	}
+	return RayHit{
+		Space:    s,
+		Point:    origin.Add(dir.MulConst(t)),
+		Normal:   normal,
+		Distance: t,
+	}, true
+}
+
+// Raycast returns every space a ray from origin in the direction dir meets
+// within dist, ordered by distance. Unlike ray.Caster, hits are found exactly
+// rather than by sampling points along the ray. The spaces hit are narrowed by
+// filters, as in Hit; use WithLayers for rays to respect collision layers.
+func (t *Tree) Raycast(origin, dir floatgeom.Point2, dist float64, fs ...Filter) []RayHit {
+	dir = unit(dir)
+	end := origin.Add(dir.MulConst(dist))
+	bounds := floatgeom.NewBoundingRect2(origin, end)
+	results := t.SearchIntersect(NewRect(bounds.Min.X(), bounds.Min.Y(), bounds.W(), bounds.H()))
+	for _, f := range fs {
+		if len(results) == 0 {
+			break
+		}
+		results = f(results)
+	}
+	hits := []RayHit{}
+	for _, s := range results {
+		if hit, ok := s.Raycast(origin, dir, dist); ok {
+			hits = append(hits, hit)
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Distance < hits[j].Distance
+	})
+	return hits
+}
+
+// rayShape casts a ray against a shape, in the shape's coordinates.
+func rayShape(origin, dir floatgeom.Point2, dist float64, shape Shape) (float64, floatgeom.Point2, bool) {
+	switch sh := shape.(type) {
+	case Circle:
+		return rayCircle(origin, dir, dist, sh.Center, sh.Radius)
+	case ConvexPolygon:
+		return rayPolygon(origin, dir, dist, sh.Points)
+	case Capsule:
+		return rayCapsule(origin, dir, dist, sh)
+	}
+	return rayPolygon(origin, dir, dist, supportPolygon(shape))
+}
+
+func rayCircle(origin, dir floatgeom.Point2, dist float64, center floatgeom.Point2, radius float64) (float64, floatgeom.Point2, bool) {
+	m := origin.Sub(center)
+	b := m.Dot(dir)
+	c := m.Dot(m) - radius*radius
+	if c > 0 && b > 0 {
+		return 0, floatgeom.Point2{}, false
+	}
+	disc := b*b - c
+	if disc < 0 {
+		return 0, floatgeom.Point2{}, false
+	}
+	sq := math.Sqrt(disc)
+	tMin := -b - sq
+	normal := origin.Add(dir.MulConst(tMin)).Sub(center).DivConst(radius)
+	return clipRay(tMin, -b+sq, dist, normal)
+}
+
+// rayPolygon casts a ray against a convex polygon, following "Fast, Consistent
+// Ray-Polygon Intersection" by Cyrus and Beck.
+func rayPolygon(origin, dir floatgeom.Point2, dist float64, pts []floatgeom.Point2) (float64, floatgeom.Point2, bool) {
+	var area float64
+	for i, a := range pts {
+		b := pts[(i+1)%len(pts)]
+		area += a.X()*b.Y() - b.X()*a.Y()
+	}
+	tMin, tMax := math.Inf(-1), math.Inf(1)
+	var normal floatgeom.Point2
+	for i, a := range pts {
+		e := pts[(i+1)%len(pts)].Sub(a)
+		n := floatgeom.Point2{e.Y(), -e.X()}
+		if area < 0 {
+			n = n.MulConst(-1)
+		}
+		denom := n.Dot(dir)
+		num := n.Dot(a.Sub(origin))
+		if denom == 0 {
+			if num < 0 {
+				return 0, floatgeom.Point2{}, false
+			}
+			continue
+		}
+		t := num / denom
+		if denom < 0 {
+			if t > tMin {
+				tMin = t
+				normal = unit(n)
+			}
+		} else {
+			tMax = math.Min(tMax, t)
+		}
+	}
+	return clipRay(tMin, tMax, dist, normal)
+}
+
+func rayCapsule(origin, dir floatgeom.Point2, dist float64, c Capsule) (float64, floatgeom.Point2, bool) {
+	best, bestNormal, hit := rayCircle(origin, dir, dist, c.A, c.Radius)
+	try := func(t float64, normal floatgeom.Point2, ok bool) {
+		if ok && (!hit || t < best) {
+			best, bestNormal, hit = t, normal, true
+		}
+	}
+	try(rayCircle(origin, dir, dist, c.B, c.Radius))
+	if c.A != c.B {
+		ab := unit(c.B.Sub(c.A))
+		side := floatgeom.Point2{-ab.Y(), ab.X()}.MulConst(c.Radius)
+		try(rayPolygon(origin, dir, dist, []floatgeom.Point2{
+			c.A.Add(side), c.B.Add(side), c.B.Sub(side), c.A.Sub(side),
+		}))
+	}
+	return best, bestNormal, hit
+}
+
+// supportDirections is how many directions supportPolygon samples.
+const supportDirections = 32
+
+// supportPolygon approximates a shape with the polygon through its support
+// points in evenly spaced directions.
+func supportPolygon(shape Shape) []floatgeom.Point2 {
+	pts := make([]floatgeom.Point2, 0, supportDirections)
+	for i := 0; i < supportDirections; i++ {
+		angle := 2 * math.Pi * float64(i) / supportDirections
+		p := shape.Support(floatgeom.Point2{math.Cos(angle), math.Sin(angle)})
+		if len(pts) == 0 || (p != pts[len(pts)-1] && p != pts[0]) {
+			pts = append(pts, p)
+		}
+	}
+	return pts
+}
@@ -0,0 +1,119 @@
+package collision
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+)
+
+func TestRayRect(t *testing.T) {
+	type testCase struct {
+		origin, dir floatgeom.Point2
+		dist        float64
+		ok          bool
+		t           float64
+		normal      floatgeom.Point2
+	}
+	r := floatgeom.NewRect2(10, 10, 20, 20)
+	tcs := map[string]testCase{
+		"left":      {origin: floatgeom.Point2{0, 15}, dir: floatgeom.Point2{1, 0}, dist: 100, ok: true, t: 10, normal: floatgeom.Point2{-1, 0}},
+		"below":     {origin: floatgeom.Point2{15, 40}, dir: floatgeom.Point2{0, -1}, dist: 100, ok: true, t: 20, normal: floatgeom.Point2{0, 1}},
+		"too short": {origin: floatgeom.Point2{0, 15}, dir: floatgeom.Point2{1, 0}, dist: 5},
+		"away":      {origin: floatgeom.Point2{0, 15}, dir: floatgeom.Point2{-1, 0}, dist: 100},
+		"miss":      {origin: floatgeom.Point2{0, 0}, dir: floatgeom.Point2{0, 1}, dist: 100},
+		"inside":    {origin: floatgeom.Point2{15, 15}, dir: floatgeom.Point2{1, 0}, dist: 100, ok: true},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			toi, normal, ok := RayRect(tc.origin, tc.dir, tc.dist, r)
+			if ok != tc.ok || toi != tc.t || normal != tc.normal {
+				t.Fatalf("expected %v %v %v, got %v %v %v", tc.ok, tc.t, tc.normal, ok, toi, normal)
+			}
+		})
+	}
+}
+
+func TestSpaceRaycast(t *testing.T) {
+	pg, err := NewPolygonSpace(floatgeom.NewPolygon2(
+		floatgeom.Point2{10, 0}, floatgeom.Point2{20, 10}, floatgeom.Point2{10, 20}, floatgeom.Point2{0, 10},
+	), 0)
+	if err != nil {
+		t.Fatalf("failed to create polygon space: %v", err)
+	}
+	type testCase struct {
+		sp          *Space
+		origin, dir floatgeom.Point2
+		ok          bool
+		point       floatgeom.Point2
+		normal      floatgeom.Point2
+	}
+	diag := math.Sqrt2 / 2
+	tcs := map[string]testCase{
+		"circle": {
+			sp: NewCircleSpace(10, 10, 5, 0), origin: floatgeom.Point2{0, 10}, dir: floatgeom.Point2{1, 0},
+			ok: true, point: floatgeom.Point2{5, 10}, normal: floatgeom.Point2{-1, 0},
+		},
+		"circle corner miss": {
+			sp: NewCircleSpace(10, 10, 5, 0), origin: floatgeom.Point2{0, 11}, dir: floatgeom.Point2{1, -1},
+		},
+		"polygon": {
+			sp: pg, origin: floatgeom.Point2{0, 0}, dir: floatgeom.Point2{1, 1},
+			ok: true, point: floatgeom.Point2{5, 5}, normal: floatgeom.Point2{-diag, -diag},
+		},
+		"capsule side": {
+			sp: NewCapsuleSpace(floatgeom.Point2{10, 10}, floatgeom.Point2{30, 10}, 2, 0), origin: floatgeom.Point2{20, 0}, dir: floatgeom.Point2{0, 1},
+			ok: true, point: floatgeom.Point2{20, 8}, normal: floatgeom.Point2{0, -1},
+		},
+		"capsule end": {
+			sp: NewCapsuleSpace(floatgeom.Point2{10, 10}, floatgeom.Point2{30, 10}, 2, 0), origin: floatgeom.Point2{0, 10}, dir: floatgeom.Point2{1, 0},
+			ok: true, point: floatgeom.Point2{8, 10}, normal: floatgeom.Point2{-1, 0},
+		},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			hit, ok := tc.sp.Raycast(tc.origin, tc.dir.Normalize(), 100)
+			if ok != tc.ok {
+				t.Fatalf("expected ok %v, got %v", tc.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if hit.Point.Distance(tc.point) > 1e-9 || hit.Normal.Distance(tc.normal) > 1e-9 {
+				t.Fatalf("expected hit at %v with normal %v, got %v %v", tc.point, tc.normal, hit.Point, hit.Normal)
+			}
+			if hit.Distance != hit.Point.Distance(tc.origin) {
+				t.Fatalf("expected distance to match hit point, got %v", hit.Distance)
+			}
+		})
+	}
+}
+
+func TestTreeRaycast(t *testing.T) {
+	tr := NewTree()
+	thin := NewLabeledSpace(10, 0, .01, 10, 1)
+	thin.Location.Max[0] = 10.01
+	far := NewLabeledSpace(30, 0, 10, 10, 2)
+	circle := NewCircleSpace(50, 50, 5, 0)
+	tr.Add(thin, far, circle)
+	hits := tr.Raycast(floatgeom.Point2{0, 5}, floatgeom.Point2{4, 0}, 100)
+	if len(hits) != 2 || hits[0].Space != thin || hits[1].Space != far {
+		t.Fatalf("expected thin then far hits, got %v", hits)
+	}
+	if hits[1].Point != (floatgeom.Point2{30, 5}) || hits[1].Distance != 30 {
+		t.Fatalf("unexpected far hit %v", hits[1])
+	}
+	if hits := tr.Raycast(floatgeom.Point2{0, 5}, floatgeom.Point2{1, 0}, 100, WithLabels(2)); len(hits) != 1 {
+		t.Fatalf("expected filtered raycast to hit far space, got %v", hits)
+	}
+	if hits := tr.Raycast(floatgeom.Point2{0, 44}, floatgeom.Point2{1, 0}, 100); len(hits) != 0 {
+		t.Fatalf("expected ray to miss circle, got %v", hits)
+	}
+	thin.Layers = Layer(1)
+	far.Layers = Layer(2)
+	if hits := tr.Raycast(floatgeom.Point2{0, 5}, floatgeom.Point2{1, 0}, 100, WithLayers(0, Layer(2))); len(hits) != 1 || hits[0].Space != far {
+		t.Fatalf("expected masked raycast to hit far space, got %v", hits)
+	}
+}
@@ -26,6 +26,11 @@ func (tree *Rtree) Size() int {
 	return tree.size
 }
 
+// Clear removes all objects from the rtree.
+func (tree *Rtree) Clear() {
+	*tree = *newTree(tree.MinChildren, tree.MaxChildren)
+}
+
 // NewTree creates a new R-tree instance.
 func newTree(minChildren, maxChildren int) *Rtree {
 	rt := Rtree{MinChildren: minChildren, MaxChildren: maxChildren}
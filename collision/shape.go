@@ -0,0 +1,298 @@
+package collision
+
+import (
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/oakerr"
+)
+
+// A Shape is a convex shape a Space can hold to collide more precisely than its
+// rectangle. A shape's coordinates are relative to the minimum point of its
+// space's rectangle, and the shape should fit within that rectangle, which acts
+// as its broadphase bounds.
+type Shape interface {
+	// Bounds returns the smallest rectangle containing the shape.
+	Bounds() floatgeom.Rect2
+	// Support returns the point of the shape farthest in the given direction.
+	Support(dir floatgeom.Point2) floatgeom.Point2
+}
+
+var (
+	_ Shape = Circle{}
+	_ Shape = Capsule{}
+	_ Shape = ConvexPolygon{}
+)
+
+// A Circle is a round Shape.
+type Circle struct {
+	Center floatgeom.Point2
+	Radius float64
+}
+
+// Bounds satisfies Shape.
+func (c Circle) Bounds() floatgeom.Rect2 {
+	return floatgeom.NewRect2(
+		c.Center.X()-c.Radius, c.Center.Y()-c.Radius,
+		c.Center.X()+c.Radius, c.Center.Y()+c.Radius,
+	)
+}
+
+// Support satisfies Shape.
+func (c Circle) Support(dir floatgeom.Point2) floatgeom.Point2 {
+	return c.Center.Add(unit(dir).MulConst(c.Radius))
+}
+
+// A Capsule is a Shape formed of every point within Radius of the line from A
+// to B.
+type Capsule struct {
+	A, B   floatgeom.Point2
+	Radius float64
+}
+
+// Bounds satisfies Shape.
+func (c Capsule) Bounds() floatgeom.Rect2 {
+	r := floatgeom.NewBoundingRect2(c.A, c.B)
+	r.Min = r.Min.Sub(floatgeom.Point2{c.Radius, c.Radius})
+	r.Max = r.Max.Add(floatgeom.Point2{c.Radius, c.Radius})
+	return r
+}
+
+// Support satisfies Shape.
+func (c Capsule) Support(dir floatgeom.Point2) floatgeom.Point2 {
+	end := c.A
+	if c.B.Dot(dir) > c.A.Dot(dir) {
+		end = c.B
+	}
+	return end.Add(unit(dir).MulConst(c.Radius))
+}
+
+// A ConvexPolygon is a Shape with straight edges and no concave corners.
+type ConvexPolygon struct {
+	Points []floatgeom.Point2
+}
+
+// NewConvexPolygon converts a polygon into a ConvexPolygon, or returns an error
+// if the polygon is concave.
+func NewConvexPolygon(pg floatgeom.Polygon2) (ConvexPolygon, error) {
+	if !isConvex(pg.Points) {
+		return ConvexPolygon{}, oakerr.InvalidInput{InputName: "pg"}
+	}
+	pts := make([]floatgeom.Point2, len(pg.Points))
+	copy(pts, pg.Points)
+	return ConvexPolygon{Points: pts}, nil
+}
+
+func isConvex(pts []floatgeom.Point2) bool {
+	if len(pts) < 3 {
+		return false
+	}
+	var sign float64
+	for i := range pts {
+		a := pts[i]
+		b := pts[(i+1)%len(pts)]
+		c := pts[(i+2)%len(pts)]
+		ab, bc := b.Sub(a), c.Sub(b)
+		cross := ab.X()*bc.Y() - ab.Y()*bc.X()
+		if cross == 0 {
+			continue
+		}
+		if sign == 0 {
+			sign = cross
+		} else if (cross > 0) != (sign > 0) {
+			return false
+		}
+	}
+	return sign != 0
+}
+
+// Bounds satisfies Shape.
+func (cp ConvexPolygon) Bounds() floatgeom.Rect2 {
+	return floatgeom.NewBoundingRect2(cp.Points...)
+}
+
+// Support satisfies Shape.
+func (cp ConvexPolygon) Support(dir floatgeom.Point2) floatgeom.Point2 {
+	best := cp.Points[0]
+	bestDot := best.Dot(dir)
+	for _, p := range cp.Points[1:] {
+		if d := p.Dot(dir); d > bestDot {
+			best, bestDot = p, d
+		}
+	}
+	return best
+}
+
+// unit normalizes a direction, treating the zero direction as +x.
+func unit(dir floatgeom.Point2) floatgeom.Point2 {
+	mag := dir.Magnitude()
+	if mag == 0 {
+		return floatgeom.Point2{1, 0}
+	}
+	return dir.DivConst(mag)
+}
+
+// NewShapedSpace returns a space at x,y holding the given shape, whose
+// coordinates are relative to x,y. The space's rectangle spans from x,y to the
+// maximum point of the shape's bounds.
+func NewShapedSpace(x, y float64, shape Shape, cID event.CallerID) *Space {
+	max := shape.Bounds().Max
+	s := NewSpace(x, y, max.X(), max.Y(), cID)
+	s.Shape = shape
+	return s
+}
+
+// NewCircleSpace returns a space holding a circle centered at x,y.
+func NewCircleSpace(x, y, radius float64, cID event.CallerID) *Space {
+	return NewShapedSpace(x-radius, y-radius, Circle{
+		Center: floatgeom.Point2{radius, radius},
+		Radius: radius,
+	}, cID)
+}
+
+// NewCapsuleSpace returns a space holding a capsule around the line from a to b.
+func NewCapsuleSpace(a, b floatgeom.Point2, radius float64, cID event.CallerID) *Space {
+	min := floatgeom.NewBoundingRect2(a, b).Min.Sub(floatgeom.Point2{radius, radius})
+	return NewShapedSpace(min.X(), min.Y(), Capsule{
+		A:      a.Sub(min),
+		B:      b.Sub(min),
+		Radius: radius,
+	}, cID)
+}
+
+// NewPolygonSpace returns a space holding a convex polygon, or an error if the
+// polygon is concave.
+func NewPolygonSpace(pg floatgeom.Polygon2, cID event.CallerID) (*Space, error) {
+	cp, err := NewConvexPolygon(pg)
+	if err != nil {
+		return nil, err
+	}
+	min := cp.Bounds().Min
+	for i, p := range cp.Points {
+		cp.Points[i] = p.Sub(min)
+	}
+	return NewShapedSpace(min.X(), min.Y(), cp, cID), nil
+}
+
+// Collides returns whether two spaces overlap. If either space holds a shape,
+// the shapes are compared precisely, otherwise only their rectangles are.
+func (s *Space) Collides(other *Space) bool {
+	if !s.Location.Intersects(other.Location) {
+		return false
+	}
+	return narrowCollides(s, other)
+}
+
+// Outline returns the corners of this space's shape, or of its rectangle if it
+// has no shape. Round shapes are approximated.
+func (s *Space) Outline() []floatgeom.Point2 {
+	min := floatgeom.Point2{s.Location.Min.X(), s.Location.Min.Y()}
+	var pts []floatgeom.Point2
+	switch sh := s.Shape.(type) {
+	case nil:
+		max := floatgeom.Point2{s.Location.Max.X(), s.Location.Max.Y()}
+		return []floatgeom.Point2{
+			min, {max.X(), min.Y()}, max, {min.X(), max.Y()},
+		}
+	case ConvexPolygon:
+		pts = make([]floatgeom.Point2, len(sh.Points))
+		copy(pts, sh.Points)
+	default:
+		pts = supportPolygon(sh)
+	}
+	for i, p := range pts {
+		pts[i] = p.Add(min)
+	}
+	return pts
+}
+
+// narrowCollides returns whether two spaces whose rectangles are known to
+// intersect also have intersecting shapes.
+func narrowCollides(s, other *Space) bool {
+	if s.Shape == nil && other.Shape == nil {
+		return true
+	}
+	return gjk(s.support, other.support)
+}
+
+// support returns the point of a space farthest in a direction, using its
+// shape if it has one.
+func (s *Space) support(dir floatgeom.Point2) floatgeom.Point2 {
+	min := s.Location.Min
+	if s.Shape != nil {
+		return floatgeom.Point2{min.X(), min.Y()}.Add(s.Shape.Support(dir))
+	}
+	x, y := min.X(), min.Y()
+	if dir.X() > 0 {
+		x = s.Location.Max.X()
+	}
+	if dir.Y() > 0 {
+		y = s.Location.Max.Y()
+	}
+	return floatgeom.Point2{x, y}
+}
+
+// gjkIterations bounds the GJK search, which could otherwise loop on floating
+// point error for shapes that are barely touching.
+const gjkIterations = 32
+
+// gjk returns whether two convex shapes, described by their support functions,
+// intersect. See "A fast procedure for computing the distance between complex
+// objects in three-dimensional space", Gilbert, Johnson, and Keerthi.
+func gjk(a, b func(floatgeom.Point2) floatgeom.Point2) bool {
+	minkowski := func(d floatgeom.Point2) floatgeom.Point2 {
+		return a(d).Sub(b(d.MulConst(-1)))
+	}
+	dir := floatgeom.Point2{1, 0}
+	simplex := []floatgeom.Point2{minkowski(dir)}
+	dir = simplex[0].MulConst(-1)
+	for i := 0; i < gjkIterations; i++ {
+		if dir == (floatgeom.Point2{}) {
+			// The origin is on the simplex
+			return true
+		}
+		p := minkowski(dir)
+		if p.Dot(dir) <= 0 {
+			return false
+		}
+		simplex = append(simplex, p)
+		var contains bool
+		simplex, dir, contains = nextSimplex(simplex)
+		if contains {
+			return true
+		}
+	}
+	return false
+}
+
+// nextSimplex reduces a simplex to the feature closest to the origin and
+// returns the direction toward the origin from it, or whether the simplex
+// contains the origin.
+func nextSimplex(simplex []floatgeom.Point2) ([]floatgeom.Point2, floatgeom.Point2, bool) {
+	a := simplex[len(simplex)-1]
+	ao := a.MulConst(-1)
+	if len(simplex) == 2 {
+		ab := simplex[0].Sub(a)
+		if ab.Dot(ao) > 0 {
+			return simplex, tripleProduct(ab, ao, ab), false
+		}
+		return []floatgeom.Point2{a}, ao, false
+	}
+	b, c := simplex[1], simplex[0]
+	ab := b.Sub(a)
+	ac := c.Sub(a)
+	abPerp := tripleProduct(ac, ab, ab)
+	if abPerp.Dot(ao) > 0 {
+		return []floatgeom.Point2{b, a}, abPerp, false
+	}
+	acPerp := tripleProduct(ab, ac, ac)
+	if acPerp.Dot(ao) > 0 {
+		return []floatgeom.Point2{c, a}, acPerp, false
+	}
+	return simplex, floatgeom.Point2{}, true
+}
+
+// tripleProduct returns (a x b) x c, treating a, b and c as 3D vectors on the
+// z=0 plane.
+func tripleProduct(a, b, c floatgeom.Point2) floatgeom.Point2 {
+	return b.MulConst(a.Dot(c)).Sub(a.MulConst(b.Dot(c)))
+}
@@ -0,0 +1,109 @@
+package collision
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+)
+
+func TestSpaceCollides(t *testing.T) {
+	type testCase struct {
+		a, b    *Space
+		collide bool
+	}
+	tri, err := NewPolygonSpace(floatgeom.NewPolygon2(
+		floatgeom.Point2{0, 0}, floatgeom.Point2{10, 0}, floatgeom.Point2{0, 10},
+	), 0)
+	if err != nil {
+		t.Fatalf("unexpected error creating polygon space: %v", err)
+	}
+	tcs := map[string]testCase{
+		"circles apart": {
+			a: NewCircleSpace(0, 0, 5, 0), b: NewCircleSpace(9, 9, 5, 0),
+		},
+		"circles touching boxes": {
+			a: NewCircleSpace(0, 0, 5, 0), b: NewCircleSpace(8, 0, 5, 0), collide: true,
+		},
+		"circle in box corner": {
+			a: NewCircleSpace(0, 0, 5, 0), b: NewUnassignedSpace(4, 4, 5, 5),
+		},
+		"circle on box edge": {
+			a: NewCircleSpace(0, 0, 5, 0), b: NewUnassignedSpace(4, -1, 5, 2), collide: true,
+		},
+		"triangle hypotenuse": {
+			a: tri, b: NewUnassignedSpace(6, 6, 3, 3),
+		},
+		"triangle corner": {
+			a: tri, b: NewUnassignedSpace(1, 1, 3, 3), collide: true,
+		},
+		"capsule": {
+			a:       NewCapsuleSpace(floatgeom.Point2{0, 0}, floatgeom.Point2{20, 20}, 2, 0),
+			b:       NewCircleSpace(10, 10, 1, 0),
+			collide: true,
+		},
+		"beside capsule": {
+			a: NewCapsuleSpace(floatgeom.Point2{0, 0}, floatgeom.Point2{20, 20}, 2, 0),
+			b: NewCircleSpace(16, 4, 1, 0),
+		},
+		"boxes": {
+			a: NewUnassignedSpace(0, 0, 5, 5), b: NewUnassignedSpace(4, 4, 5, 5), collide: true,
+		},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			if tc.a.Collides(tc.b) != tc.collide {
+				t.Fatalf("expected a collides with b to be %v", tc.collide)
+			}
+			if tc.b.Collides(tc.a) != tc.collide {
+				t.Fatalf("expected b collides with a to be %v", tc.collide)
+			}
+		})
+	}
+}
+
+func TestNewPolygonSpaceConcave(t *testing.T) {
+	_, err := NewPolygonSpace(floatgeom.NewPolygon2(
+		floatgeom.Point2{0, 0}, floatgeom.Point2{10, 0}, floatgeom.Point2{2, 2}, floatgeom.Point2{0, 10},
+	), 0)
+	if err == nil {
+		t.Fatalf("expected error creating concave polygon space")
+	}
+}
+
+func TestTreeHitsShapes(t *testing.T) {
+	tr := NewTree()
+	circle := NewCircleSpace(0, 0, 5, 0)
+	corner := NewLabeledSpace(4, 4, 5, 5, 1)
+	edge := NewLabeledSpace(4, -1, 5, 2, 2)
+	tr.Add(circle, corner, edge)
+	if hits := tr.Hits(circle); len(hits) != 1 || hits[0] != edge {
+		t.Fatalf("expected only edge space to hit circle, got %v", hits)
+	}
+	if hits := tr.Hit(circle, WithLabels(1, 2)); len(hits) != 1 || hits[0] != edge {
+		t.Fatalf("expected only edge space to hit circle, got %v", hits)
+	}
+	if tr.HitLabel(circle, 1) != nil {
+		t.Fatalf("expected corner space not to hit circle")
+	}
+	if tr.HitLabel(circle, 2) != edge {
+		t.Fatalf("expected edge space to hit circle")
+	}
+}
+
+func TestSpaceOutline(t *testing.T) {
+	rect := NewUnassignedSpace(1, 2, 3, 4).Outline()
+	if len(rect) != 4 || rect[0] != (floatgeom.Point2{1, 2}) || rect[2] != (floatgeom.Point2{4, 6}) {
+		t.Fatalf("unexpected rectangle outline %v", rect)
+	}
+	circle := NewCircleSpace(10, 10, 5, 0).Outline()
+	if len(circle) < 8 {
+		t.Fatalf("expected circle to be approximated, got %v", circle)
+	}
+	for _, p := range circle {
+		if d := p.Distance(floatgeom.Point2{10, 10}); math.Abs(d-5) > 1e-9 {
+			t.Fatalf("expected circle outline on its edge, got %v", p)
+		}
+	}
+}
@@ -27,6 +27,14 @@ type Space struct {
 	// Type represents which ID space the above ID
 	// corresponds to.
 	Type int
+	// Shape, if set, is the precise shape of this space
+	// within its Location. See Shape.
+	Shape Shape
+	// Layers are the collision layers this space is on.
+	Layers Layers
+	// Mask is the collision layers this space will hit.
+	// A zero Mask will hit all layers. See CanHit.
+	Mask Layers
 }
 
 // Bounds satisfies the rtreego.Spatial interface.
@@ -219,6 +227,9 @@ func NewFullSpace(x, y, w, h float64, l Label, cID event.CallerID) *Space {
 		l,
 		cID,
 		IDTypeCID,
+		nil,
+		0,
+		0,
 	}
 }
 
@@ -234,6 +245,9 @@ func NewRectSpace(rect floatgeom.Rect3, l Label, cID event.CallerID) *Space {
 		l,
 		cID,
 		IDTypeCID,
+		nil,
+		0,
+		0,
 	}
 }
 
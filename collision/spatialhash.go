@@ -0,0 +1,214 @@
+package collision
+
+import (
+	"math"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/oakerr"
+)
+
+// A SpatialHash is a Broadphase which buckets spaces into a uniform grid of
+// square cells. Inserting, deleting and moving spaces is cheap, which suits
+// many similarly sized, constantly moving spaces, like bullets. Spaces
+// covering more than a few cells are kept in a list checked on every search,
+// so should be rare.
+type SpatialHash struct {
+	cellSize float64
+	cells    map[cell][]*Space
+	spaces   map[*Space]cellRange
+	// large holds spaces covering more than maxSpaceCells cells, which are
+	// not stored in cells.
+	large map[*Space]struct{}
+}
+
+// maxSpaceCells is how many cells a space can cover before it is kept in the
+// large list instead.
+const maxSpaceCells = 16
+
+// maxCell bounds cell coordinates, so huge or infinite rectangles convert
+// to cells predictably.
+const maxCell = 1 << 30
+
+type cell struct {
+	x, y int
+}
+
+type cellRange struct {
+	min, max cell
+}
+
+// NewSpatialHash returns a SpatialHash with cells of the given width and
+// height. Cells should be around the size of the spaces stored.
+func NewSpatialHash(cellSize float64) (*SpatialHash, error) {
+	if cellSize <= 0 {
+		return nil, oakerr.InvalidInput{InputName: "cellSize"}
+	}
+	return &SpatialHash{
+		cellSize: cellSize,
+		cells:    make(map[cell][]*Space),
+		spaces:   make(map[*Space]cellRange),
+		large:    make(map[*Space]struct{}),
+	}, nil
+}
+
+func (sh *SpatialHash) cellsOf(r floatgeom.Rect3) cellRange {
+	return cellRange{
+		min: cell{
+			x: sh.cellCoord(r.Min.X()),
+			y: sh.cellCoord(r.Min.Y()),
+		},
+		max: cell{
+			x: sh.cellCoord(r.Max.X()),
+			y: sh.cellCoord(r.Max.Y()),
+		},
+	}
+}
+
+// cellCoord returns the cell coordinate of v, clamped to maxCell.
+func (sh *SpatialHash) cellCoord(v float64) int {
+	c := math.Floor(v / sh.cellSize)
+	switch {
+	case c != c:
+		// NaN
+		return 0
+	case c > maxCell:
+		return maxCell
+	case c < -maxCell:
+		return -maxCell
+	}
+	return int(c)
+}
+
+// count returns how many cells the range covers.
+func (cr cellRange) count() int64 {
+	return int64(cr.max.x-cr.min.x+1) * int64(cr.max.y-cr.min.y+1)
+}
+
+// Insert satisfies Broadphase. Inserting a space already in the hash moves it to
+// its current location.
+func (sh *SpatialHash) Insert(s *Space) {
+	sh.Delete(s)
+	cr := sh.cellsOf(s.Location)
+	sh.spaces[s] = cr
+	if cr.count() > maxSpaceCells {
+		sh.large[s] = struct{}{}
+		return
+	}
+	for x := cr.min.x; x <= cr.max.x; x++ {
+		for y := cr.min.y; y <= cr.max.y; y++ {
+			c := cell{x, y}
+			sh.cells[c] = append(sh.cells[c], s)
+		}
+	}
+}
+
+// Delete satisfies Broadphase.
+func (sh *SpatialHash) Delete(s *Space) bool {
+	cr, ok := sh.spaces[s]
+	if !ok {
+		return false
+	}
+	delete(sh.spaces, s)
+	if _, ok := sh.large[s]; ok {
+		delete(sh.large, s)
+		return true
+	}
+	for x := cr.min.x; x <= cr.max.x; x++ {
+		for y := cr.min.y; y <= cr.max.y; y++ {
+			c := cell{x, y}
+			sps := sh.cells[c]
+			for i, s2 := range sps {
+				if s2 == s {
+					sps[i] = sps[len(sps)-1]
+					sps[len(sps)-1] = nil
+					sps = sps[:len(sps)-1]
+					break
+				}
+			}
+			if len(sps) == 0 {
+				delete(sh.cells, c)
+			} else {
+				sh.cells[c] = sps
+			}
+		}
+	}
+	return true
+}
+
+// SearchIntersect satisfies Broadphase. Searches covering more cells than
+// there are spaces check every space instead.
+func (sh *SpatialHash) SearchIntersect(bb floatgeom.Rect3) []*Space {
+	results := []*Space{}
+	for s := range sh.large {
+		if s.Location.Intersects(bb) {
+			results = append(results, s)
+		}
+	}
+	q := sh.cellsOf(bb)
+	if q.count() > int64(len(sh.spaces)) {
+		for s := range sh.spaces {
+			if _, ok := sh.large[s]; !ok && s.Location.Intersects(bb) {
+				results = append(results, s)
+			}
+		}
+		return results
+	}
+	for x := q.min.x; x <= q.max.x; x++ {
+		for y := q.min.y; y <= q.max.y; y++ {
+			for _, s := range sh.cells[cell{x, y}] {
+				// Spaces covering several cells are only reported from the
+				// first cell they share with the search
+				cr := sh.spaces[s]
+				if x != maxInt(cr.min.x, q.min.x) || y != maxInt(cr.min.y, q.min.y) {
+					continue
+				}
+				if s.Location.Intersects(bb) {
+					results = append(results, s)
+				}
+			}
+		}
+	}
+	return results
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// NearestNeighbor satisfies Broadphase. It checks every space in the hash.
+func (sh *SpatialHash) NearestNeighbor(p floatgeom.Point3) *Space {
+	var nearest *Space
+	d := math.MaxFloat64
+	for s := range sh.spaces {
+		if dist := minDist(p, s.Location); dist < d {
+			d = dist
+			nearest = s
+		}
+	}
+	return nearest
+}
+
+// NearestNeighbors satisfies Broadphase. It checks every space in the hash.
+func (sh *SpatialHash) NearestNeighbors(k int, p floatgeom.Point3) []*Space {
+	dists := make([]float64, 0, k)
+	nearest := make([]*Space, 0, k)
+	for s := range sh.spaces {
+		dists, nearest = insertNearest(k, dists, nearest, minDist(p, s.Location), s)
+	}
+	return nearest
+}
+
+// Size satisfies Broadphase.
+func (sh *SpatialHash) Size() int {
+	return len(sh.spaces)
+}
+
+// Clear satisfies Broadphase.
+func (sh *SpatialHash) Clear() {
+	sh.cells = make(map[cell][]*Space)
+	sh.spaces = make(map[*Space]cellRange)
+	sh.large = make(map[*Space]struct{})
+}
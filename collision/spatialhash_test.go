@@ -0,0 +1,232 @@
+package collision
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+)
+
+func TestNewSpatialHash(t *testing.T) {
+	if _, err := NewSpatialHash(0); err == nil {
+		t.Fatalf("expected error for zero cell size")
+	}
+}
+
+func TestSpatialHashMatchesRtree(t *testing.T) {
+	sh, err := NewSpatialHash(8)
+	if err != nil {
+		t.Fatalf("failed to create spatial hash: %v", err)
+	}
+	hashTree := NewBroadphaseTree(sh)
+	rTree := NewTree()
+	rng := rand.New(rand.NewSource(1))
+	spaces := make([]*Space, 200)
+	rSpaces := make([]*Space, len(spaces))
+	for i := range spaces {
+		// A few spaces span many cells, and some lie on negative cells
+		w := 1 + rng.Float64()*6
+		if i%20 == 0 {
+			w = 30
+		}
+		spaces[i] = NewLabeledSpace(rng.Float64()*200-100, rng.Float64()*200-100, w, w, Label(i))
+		cp := *spaces[i]
+		rSpaces[i] = &cp
+	}
+	hashTree.Add(spaces...)
+	rTree.Add(rSpaces...)
+	for i := 0; i < 100; i++ {
+		j := rng.Intn(len(spaces))
+		dx, dy := rng.Float64()*20-10, rng.Float64()*20-10
+		if err := hashTree.ShiftSpace(dx, dy, spaces[j]); err != nil {
+			t.Fatalf("failed to shift space: %v", err)
+		}
+		if err := rTree.ShiftSpace(dx, dy, rSpaces[j]); err != nil {
+			t.Fatalf("failed to shift space: %v", err)
+		}
+	}
+	if hashTree.Size() != rTree.Size() {
+		t.Fatalf("expected sizes to match, got %v vs %v", hashTree.Size(), rTree.Size())
+	}
+	for i := 0; i < 100; i++ {
+		q := NewSpace(rng.Float64()*200-100, rng.Float64()*200-100, rng.Float64()*40, rng.Float64()*40, 0)
+		got, expected := labels(hashTree.Hits(q)), labels(rTree.Hits(q))
+		if len(got) != len(expected) {
+			t.Fatalf("expected hits %v, got %v", expected, got)
+		}
+		for j := range got {
+			if got[j] != expected[j] {
+				t.Fatalf("expected hits %v, got %v", expected, got)
+			}
+		}
+	}
+	p := floatgeom.Point3{3, 4, 0}
+	if hashTree.NearestNeighbor(p).Label != rTree.NearestNeighbor(p).Label {
+		t.Fatalf("expected nearest neighbors to match")
+	}
+	if nn := hashTree.NearestNeighbors(3, p); len(nn) != 3 || nn[0] != hashTree.NearestNeighbor(p) {
+		t.Fatalf("expected three nearest neighbors, got %v", nn)
+	}
+	if hashTree.Remove(spaces...) != len(spaces) || hashTree.Size() != 0 {
+		t.Fatalf("expected all spaces to be removed")
+	}
+	if len(sh.cells) != 0 {
+		t.Fatalf("expected removed spaces to leave no cells, got %v", len(sh.cells))
+	}
+	hashTree.Add(spaces...)
+	hashTree.Clear()
+	if hashTree.Size() != 0 || len(hashTree.Hits(NewSpace(-200, -200, 400, 400, 0))) != 0 {
+		t.Fatalf("expected cleared hash to be empty")
+	}
+}
+
+func TestSpatialHashHugeRects(t *testing.T) {
+	sh, err := NewSpatialHash(1)
+	if err != nil {
+		t.Fatalf("failed to create spatial hash: %v", err)
+	}
+	tree := NewBroadphaseTree(sh)
+	if tree.Rtree != nil {
+		t.Fatalf("expected hash backed tree to have no rtree")
+	}
+	small := NewLabeledSpace(5, 5, 1, 1, 1)
+	level := NewLabeledSpace(-1e9, -1e9, 2e9, 2e9, 2)
+	tree.Add(small, level)
+	if len(sh.large) != 1 || len(sh.cells) == 0 {
+		t.Fatalf("expected the level sized space to be kept out of cells")
+	}
+	done := make(chan []*Space)
+	go func() {
+		inf := math.Inf(1)
+		done <- tree.SearchIntersect(floatgeom.Rect3{
+			Min: floatgeom.Point3{-inf, -inf, -inf},
+			Max: floatgeom.Point3{inf, inf, inf},
+		})
+	}()
+	select {
+	case got := <-done:
+		if ls := labels(got); len(ls) != 2 {
+			t.Fatalf("expected both spaces in an infinite search, got %v", ls)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("infinite search did not finish")
+	}
+	if ls := labels(tree.Hits(NewSpace(-1e8, -1e8, 2e8, 2e8, 0))); len(ls) != 2 {
+		t.Fatalf("expected both spaces in a huge search, got %v", ls)
+	}
+	if ls := labels(tree.Hits(NewSpace(100, 100, 1, 1, 0))); len(ls) != 1 || ls[0] != 2 {
+		t.Fatalf("expected only the level in a small search, got %v", ls)
+	}
+	if tree.Remove(level) != 1 || len(sh.large) != 0 || tree.Size() != 1 {
+		t.Fatalf("expected large space to be removed")
+	}
+}
+
+func TestTreeKeepsRtree(t *testing.T) {
+	tree := NewTree()
+	tree.Add(NewLabeledSpace(0, 0, 1, 1, 1))
+	if tree.Rtree == nil || tree.Rtree.Size() != 1 || tree.Size() != 1 {
+		t.Fatalf("expected default trees to store spaces in their rtree")
+	}
+	tree.Clear()
+	if tree.Rtree.Size() != 0 || tree.Size() != 0 {
+		t.Fatalf("expected cleared rtree to be empty")
+	}
+	rt := newTree(2, 4)
+	if NewBroadphaseTree(rt).Rtree != rt {
+		t.Fatalf("expected rtree broadphases to be the tree's rtree")
+	}
+}
+
+func labels(sps []*Space) []int {
+	ls := make([]int, len(sps))
+	for i, s := range sps {
+		ls[i] = int(s.Label)
+	}
+	sort.Ints(ls)
+	return ls
+}
+
+func benchBroadphases() map[string]func() *Tree {
+	return map[string]func() *Tree{
+		"rtree": NewTree,
+		"hash": func() *Tree {
+			sh, _ := NewSpatialHash(16)
+			return NewBroadphaseTree(sh)
+		},
+	}
+}
+
+func benchSpaces(n int) []*Space {
+	rng := rand.New(rand.NewSource(1))
+	spaces := make([]*Space, n)
+	for i := range spaces {
+		spaces[i] = NewSpace(rng.Float64()*1000, rng.Float64()*1000, 4, 4, 0)
+	}
+	return spaces
+}
+
+func BenchmarkBroadphaseInsert(b *testing.B) {
+	spaces := benchSpaces(2000)
+	for name, newTree := range benchBroadphases() {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				newTree().Add(spaces...)
+			}
+		})
+	}
+}
+
+func BenchmarkBroadphaseUpdate(b *testing.B) {
+	spaces := benchSpaces(2000)
+	for name, newTree := range benchBroadphases() {
+		b.Run(name, func(b *testing.B) {
+			tree := newTree()
+			tree.Add(spaces...)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sp := spaces[i%len(spaces)]
+				dx := 3.0
+				if (i/len(spaces))%2 == 1 {
+					dx = -3
+				}
+				tree.ShiftSpace(dx, 0, sp)
+			}
+		})
+	}
+}
+
+func BenchmarkBroadphaseQuery(b *testing.B) {
+	spaces := benchSpaces(2000)
+	for name, newTree := range benchBroadphases() {
+		b.Run(name, func(b *testing.B) {
+			tree := newTree()
+			tree.Add(spaces...)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				tree.Hits(spaces[i%len(spaces)])
+			}
+		})
+	}
+}
+
+func BenchmarkBroadphaseMixed(b *testing.B) {
+	spaces := benchSpaces(2000)
+	for name, newTree := range benchBroadphases() {
+		b.Run(name, func(b *testing.B) {
+			tree := newTree()
+			tree.Add(spaces...)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// Each frame, every space moves and checks what it hits
+				for _, sp := range spaces {
+					tree.ShiftSpace(1, 1, sp)
+					tree.Hits(sp)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package collision
+
+import (
+	"math"
+	"sort"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+)
+
+// A Contact is where a moving space first touches another.
+type Contact struct {
+	// Space is the space touched.
+	Space *Space
+	// Time is how far through the movement the contact occurs, from 0 to 1.
+	Time float64
+	// Normal points out of the touched space's surface where it was touched.
+	Normal floatgeom.Point2
+}
+
+// maxSlides limits how many times Slide will redirect a movement.
+const maxSlides = 4
+
+// SweepRect returns when, as a fraction of delta from 0 to 1, a rectangle moving
+// by delta first touches a stationary target, and the normal of the target's
+// surface at that point. Rectangles which already overlap, or which pass by
+// touching only at a corner, do not make contact.
+func SweepRect(r, target floatgeom.Rect2, delta floatgeom.Point2) (t float64, normal floatgeom.Point2, ok bool) {
+	entryX, exitX, ok := sweepAxis(r.Min.X(), r.Max.X(), target.Min.X(), target.Max.X(), delta.X())
+	if !ok {
+		return 0, normal, false
+	}
+	entryY, exitY, ok := sweepAxis(r.Min.Y(), r.Max.Y(), target.Min.Y(), target.Max.Y(), delta.Y())
+	if !ok {
+		return 0, normal, false
+	}
+	entry := math.Max(entryX, entryY)
+	exit := math.Min(exitX, exitY)
+	if entry >= exit || entry < 0 || entry > 1 {
+		return 0, normal, false
+	}
+	if entryX >= entryY {
+		normal = floatgeom.Point2{-sign(delta.X()), 0}
+	} else {
+		normal = floatgeom.Point2{0, -sign(delta.Y())}
+	}
+	return entry, normal, true
+}
+
+// sweepAxis returns the times a span moving by d enters and exits another span.
+// If the span does not move and does not overlap the other, ok is false.
+func sweepAxis(min, max, targetMin, targetMax, d float64) (entry, exit float64, ok bool) {
+	switch {
+	case d > 0:
+		return (targetMin - max) / d, (targetMax - min) / d, true
+	case d < 0:
+		return (targetMax - min) / d, (targetMin - max) / d, true
+	}
+	if max <= targetMin || min >= targetMax {
+		return 0, 0, false
+	}
+	return math.Inf(-1), math.Inf(1), true
+}
+
+func sign(f float64) float64 {
+	if f < 0 {
+		return -1
+	}
+	return 1
+}
+
+// Sweep returns the first contact the space s would make moving by delta. Like
+// Hits, s will not collide with itself, and like Hit, the spaces s can collide
+// with are narrowed by its layers and by filters. Spaces s already overlaps are
+// ignored. Spaces are swept as their rectangles, ignoring their shapes.
+func (t *Tree) Sweep(s *Space, delta floatgeom.Point2, fs ...Filter) (Contact, bool) {
+	contacts := t.SweepAll(s, delta, fs...)
+	if len(contacts) == 0 {
+		return Contact{}, false
+	}
+	return contacts[0], true
+}
+
+// SweepAll acts like Sweep, but returns every contact s would make moving by
+// delta, ordered by time.
+func (t *Tree) SweepAll(s *Space, delta floatgeom.Point2, fs ...Filter) []Contact {
+	return sweepSpaces(s.Location.ProjectZ(), delta, t.sweepCandidates(s, delta, fs))
+}
+
+func sweepSpaces(r floatgeom.Rect2, delta floatgeom.Point2, candidates []*Space) []Contact {
+	contacts := []Contact{}
+	for _, c := range candidates {
+		if toi, normal, ok := SweepRect(r, c.Location.ProjectZ(), delta); ok {
+			contacts = append(contacts, Contact{
+				Space:  c,
+				Time:   toi,
+				Normal: normal,
+			})
+		}
+	}
+	sort.SliceStable(contacts, func(i, j int) bool {
+		return contacts[i].Time < contacts[j].Time
+	})
+	return contacts
+}
+
+// sweepCandidates returns the spaces which could be touched by s moving by delta.
+func (t *Tree) sweepCandidates(s *Space, delta floatgeom.Point2, fs []Filter) []*Space {
+	end := s.Location.Shift(floatgeom.Point3{delta.X(), delta.Y(), 0})
+	results := t.SearchIntersect(boundingBox(s.Location, end))
+	out := make([]*Space, 0, len(results))
+	for _, v := range results {
+		if v != s && s.CanHit(v) {
+			out = append(out, v)
+		}
+	}
+	for _, f := range fs {
+		if len(out) == 0 {
+			break
+		}
+		out = f(out)
+	}
+	return out
+}
+
+// Slide returns how far the space s can move by delta, stopping at the spaces
+// it would contact and sliding along their surfaces, and the contacts made along
+// the way. The space is not moved. The spaces s can contact are narrowed by
+// filters, as in Sweep.
+func (t *Tree) Slide(s *Space, delta floatgeom.Point2, fs ...Filter) (floatgeom.Point2, []Contact) {
+	candidates := t.sweepCandidates(s, delta, fs)
+	start := s.Location.ProjectZ()
+	r := start
+	contacts := []Contact{}
+	for i := 0; i < maxSlides && delta != (floatgeom.Point2{}); i++ {
+		hits := sweepSpaces(r, delta, candidates)
+		if len(hits) == 0 {
+			r = r.Shift(delta)
+			break
+		}
+		c := hits[0]
+		r = r.Shift(delta.MulConst(c.Time))
+		// Snap to the touched surface, so floating point error does not leave
+		// the space overlapping it
+		target := c.Space.Location.ProjectZ()
+		switch c.Normal {
+		case floatgeom.Point2{-1, 0}:
+			r = r.Shift(floatgeom.Point2{target.Min.X() - r.Max.X(), 0})
+		case floatgeom.Point2{1, 0}:
+			r = r.Shift(floatgeom.Point2{target.Max.X() - r.Min.X(), 0})
+		case floatgeom.Point2{0, -1}:
+			r = r.Shift(floatgeom.Point2{0, target.Min.Y() - r.Max.Y()})
+		case floatgeom.Point2{0, 1}:
+			r = r.Shift(floatgeom.Point2{0, target.Max.Y() - r.Min.Y()})
+		}
+		contacts = append(contacts, c)
+		// Continue with what remains of the movement along the surface
+		delta = delta.MulConst(1 - c.Time)
+		delta = delta.Sub(c.Normal.MulConst(delta.Dot(c.Normal)))
+	}
+	return r.Min.Sub(start.Min), contacts
+}
+
+// MoveAndSlide acts like Slide, then moves s by the returned distance.
+func (t *Tree) MoveAndSlide(s *Space, delta floatgeom.Point2, fs ...Filter) (floatgeom.Point2, []Contact) {
+	moved, contacts := t.Slide(s, delta, fs...)
+	t.ShiftSpace(moved.X(), moved.Y(), s)
+	return moved, contacts
+}
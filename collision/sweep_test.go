@@ -0,0 +1,125 @@
+package collision
+
+import (
+	"testing"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+)
+
+func TestSweepRect(t *testing.T) {
+	type testCase struct {
+		r, target floatgeom.Rect2
+		delta     floatgeom.Point2
+		ok        bool
+		toi       float64
+		normal    floatgeom.Point2
+	}
+	box := floatgeom.NewRect2WH(0, 0, 2, 2)
+	tcs := map[string]testCase{
+		"right": {
+			r: box, target: floatgeom.NewRect2WH(10, 0, 2, 2), delta: floatgeom.Point2{16, 0},
+			ok: true, toi: .5, normal: floatgeom.Point2{-1, 0},
+		},
+		"up": {
+			r: box, target: floatgeom.NewRect2WH(0, -6, 2, 2), delta: floatgeom.Point2{1, -8},
+			ok: true, toi: .5, normal: floatgeom.Point2{0, 1},
+		},
+		"tunneling": {
+			r: box, target: floatgeom.NewRect2WH(50, -10, 1, 20), delta: floatgeom.Point2{100, 0},
+			ok: true, toi: .48, normal: floatgeom.Point2{-1, 0},
+		},
+		"too short": {
+			r: box, target: floatgeom.NewRect2WH(10, 0, 2, 2), delta: floatgeom.Point2{4, 0},
+		},
+		"miss": {
+			r: box, target: floatgeom.NewRect2WH(10, 5, 2, 2), delta: floatgeom.Point2{16, 0},
+		},
+		"away": {
+			r: box, target: floatgeom.NewRect2WH(10, 0, 2, 2), delta: floatgeom.Point2{-16, 0},
+		},
+		"touching along": {
+			r: box, target: floatgeom.NewRect2WH(2, 0, 2, 2), delta: floatgeom.Point2{0, 5},
+		},
+		"touching into": {
+			r: box, target: floatgeom.NewRect2WH(2, 0, 2, 2), delta: floatgeom.Point2{1, 0},
+			ok: true, toi: 0, normal: floatgeom.Point2{-1, 0},
+		},
+		"corner": {
+			r: box, target: floatgeom.NewRect2WH(4, 4, 2, 2), delta: floatgeom.Point2{4, 4},
+			ok: true, toi: .5, normal: floatgeom.Point2{-1, 0},
+		},
+		"grazing corner": {
+			r: box, target: floatgeom.NewRect2WH(2, -2, 2, 2), delta: floatgeom.Point2{4, 4},
+		},
+		"overlapping": {
+			r: box, target: floatgeom.NewRect2WH(1, 1, 2, 2), delta: floatgeom.Point2{1, 0},
+		},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			toi, normal, ok := SweepRect(tc.r, tc.target, tc.delta)
+			if ok != tc.ok {
+				t.Fatalf("expected ok %v, got %v", tc.ok, ok)
+			}
+			if toi != tc.toi || normal != tc.normal {
+				t.Fatalf("expected contact at %v with normal %v, got %v %v", tc.toi, tc.normal, toi, normal)
+			}
+		})
+	}
+}
+
+func TestTreeSweep(t *testing.T) {
+	tr := NewTree()
+	s := NewLabeledSpace(0, 0, 2, 2, 1)
+	near := NewLabeledSpace(10, 0, 2, 2, 2)
+	far := NewLabeledSpace(20, 0, 2, 2, 3)
+	tr.Add(s, near, far)
+	c, ok := tr.Sweep(s, floatgeom.Point2{30, 0})
+	if !ok || c.Space != near || c.Time != 8.0/30 {
+		t.Fatalf("expected to contact near space first, got %v %v", c, ok)
+	}
+	if cs := tr.SweepAll(s, floatgeom.Point2{30, 0}); len(cs) != 2 || cs[1].Space != far {
+		t.Fatalf("expected to contact both spaces, got %v", cs)
+	}
+	c, ok = tr.Sweep(s, floatgeom.Point2{30, 0}, WithoutLabels(2))
+	if !ok || c.Space != far {
+		t.Fatalf("expected filtered sweep to contact far space, got %v %v", c, ok)
+	}
+	if _, ok := tr.Sweep(s, floatgeom.Point2{0, 30}); ok {
+		t.Fatalf("expected no contact moving away")
+	}
+}
+
+func TestTreeMoveAndSlide(t *testing.T) {
+	tr := NewTree()
+	s := NewLabeledSpace(0, 0, 2, 2, 1)
+	floor := NewLabeledSpace(-10, 4, 40, 2, 2)
+	wall := NewLabeledSpace(10, -10, 2, 14, 2)
+	ghost := NewLabeledSpace(5, 0, 2, 2, 3)
+	tr.Add(s, floor, wall, ghost)
+
+	moved, contacts := tr.MoveAndSlide(s, floatgeom.Point2{6, 6}, WithLabels(2))
+	if moved != (floatgeom.Point2{6, 2}) {
+		t.Fatalf("expected to slide along floor, moved %v", moved)
+	}
+	if len(contacts) != 1 || contacts[0].Space != floor || contacts[0].Normal != (floatgeom.Point2{0, -1}) {
+		t.Fatalf("expected one floor contact, got %v", contacts)
+	}
+	if s.X() != 6 || s.Y() != 2 {
+		t.Fatalf("expected space to be moved, got %v", s.Location)
+	}
+
+	moved, contacts = tr.MoveAndSlide(s, floatgeom.Point2{10, 10}, WithLabels(2))
+	if moved != (floatgeom.Point2{2, 0}) {
+		t.Fatalf("expected to stop in floor corner, moved %v", moved)
+	}
+	if len(contacts) != 2 {
+		t.Fatalf("expected floor and wall contacts, got %v", contacts)
+	}
+
+	moved, _ = tr.MoveAndSlide(s, floatgeom.Point2{0, -5}, WithLabels(2))
+	if moved != (floatgeom.Point2{0, -5}) {
+		t.Fatalf("expected to slide freely along wall, moved %v", moved)
+	}
+}
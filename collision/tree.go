@@ -8,10 +8,14 @@ import (
 	"github.com/oakmound/oak/v4/oakerr"
 )
 
-// A Tree provides a space for managing collisions between rectangles
+// A Tree provides a space for managing collisions between rectangles. Trees
+// store their spaces in an Rtree unless made with NewBroadphaseTree, in which
+// case Rtree is nil.
 type Tree struct {
 	*Rtree
 	sync.Mutex
+	// bp, if set, stores spaces in place of Rtree.
+	bp Broadphase
 }
 
 const (
@@ -28,6 +32,21 @@ func NewTree() *Tree {
 	}
 }
 
+// NewBroadphaseTree returns a new collision Tree which stores its spaces in the
+// given broadphase, like a SpatialHash.
+func NewBroadphaseTree(bp Broadphase) *Tree {
+	if rt, ok := bp.(*Rtree); ok {
+		return &Tree{
+			Rtree: rt,
+			Mutex: sync.Mutex{},
+		}
+	}
+	return &Tree{
+		bp:    bp,
+		Mutex: sync.Mutex{},
+	}
+}
+
 // NewCustomTree returns a new collision Tree with custom node sizes.
 // minChildren must be less than maxChildren.
 func NewCustomTree(minChildren, maxChildren int) (*Tree, error) {
@@ -40,12 +59,56 @@ func NewCustomTree(minChildren, maxChildren int) (*Tree, error) {
 	}, nil
 }
 
+// broadphase returns where the tree stores its spaces.
+func (t *Tree) broadphase() Broadphase {
+	if t.bp != nil {
+		return t.bp
+	}
+	return t.Rtree
+}
+
 // Clear resets a tree's contents to be empty
 func (t *Tree) Clear() {
+	if t.bp != nil {
+		t.bp.Clear()
+		return
+	}
 	t.Rtree = newTree(t.Rtree.MinChildren, t.Rtree.MaxChildren)
 }
 
-// Add adds a set of spaces to the rtree
+// Insert adds a space to the tree's broadphase without locking the tree.
+func (t *Tree) Insert(sp *Space) {
+	t.broadphase().Insert(sp)
+}
+
+// Delete removes a space from the tree's broadphase without locking the tree,
+// returning whether it was present.
+func (t *Tree) Delete(sp *Space) bool {
+	return t.broadphase().Delete(sp)
+}
+
+// SearchIntersect returns all spaces in the tree intersecting a rectangle.
+func (t *Tree) SearchIntersect(bb floatgeom.Rect3) []*Space {
+	return t.broadphase().SearchIntersect(bb)
+}
+
+// NearestNeighbor returns the space in the tree closest to a point.
+func (t *Tree) NearestNeighbor(p floatgeom.Point3) *Space {
+	return t.broadphase().NearestNeighbor(p)
+}
+
+// NearestNeighbors returns the k spaces in the tree closest to a point,
+// nearest first.
+func (t *Tree) NearestNeighbors(k int, p floatgeom.Point3) []*Space {
+	return t.broadphase().NearestNeighbors(k, p)
+}
+
+// Size returns how many spaces are in the tree.
+func (t *Tree) Size() int {
+	return t.broadphase().Size()
+}
+
+// Add adds a set of spaces to the tree
 func (t *Tree) Add(sps ...*Space) {
 	t.Lock()
 	for _, sp := range sps {
@@ -56,7 +119,7 @@ func (t *Tree) Add(sps ...*Space) {
 	t.Unlock()
 }
 
-// Remove removes spaces from the rtree and
+// Remove removes spaces from the tree and
 // returns the number of spaces removed.
 func (t *Tree) Remove(sps ...*Space) int {
 	removed := 0
@@ -123,16 +186,21 @@ func (t *Tree) ShiftSpace(x, y float64, s *Space) error {
 // Hits returns the set of spaces which are colliding
 // with the passed in space. All spaces collide with
 // themselves, if they exist in the tree, but self-collision
-// will not be reported by Hits.
+// will not be reported by Hits. Spaces with shapes are
+// checked precisely, see Space.Collides, and spaces sp's
+// layers do not allow it to hit are ignored, see CanHit.
 func (t *Tree) Hits(sp *Space) []*Space {
 	results := t.SearchIntersect(sp.Bounds())
 	hitSelf := -1
-	out := make([]*Space, len(results))
-	for i, v := range results {
+	out := make([]*Space, 0, len(results))
+	for _, v := range results {
+		if !sp.CanHit(v) || !narrowCollides(sp, v) {
+			continue
+		}
 		if v == sp {
-			hitSelf = i
+			hitSelf = len(out)
 		}
-		out[i] = v
+		out = append(out, v)
 	}
 	if hitSelf != -1 {
 		out[hitSelf], out[len(out)-1] = out[len(out)-1], out[hitSelf]
@@ -149,7 +217,7 @@ func (t *Tree) HitLabel(sp *Space, labels ...Label) *Space {
 	results := t.SearchIntersect(sp.Bounds())
 	for _, v := range results {
 		for _, label := range labels {
-			if v != sp && v.Label == label {
+			if v != sp && v.Label == label && sp.CanHit(v) && narrowCollides(sp, v) {
 				return v
 			}
 		}
@@ -161,6 +229,11 @@ func (t *Tree) HitLabel(sp *Space, labels ...Label) *Space {
 // relative to Hits/HitLabel, see filters.go
 func (t *Tree) Hit(sp *Space, fs ...Filter) []*Space {
 	results := t.SearchIntersect(sp.Bounds())
+	if sp.Shape != nil || sp.Mask != 0 || anyShapedOrLayered(results) {
+		results = With(func(v *Space) bool {
+			return sp.CanHit(v) && narrowCollides(sp, v)
+		})(results)
+	}
 	for _, f := range fs {
 		if len(results) == 0 {
 			return results
@@ -169,3 +242,12 @@ func (t *Tree) Hit(sp *Space, fs ...Filter) []*Space {
 	}
 	return results
 }
+
+func anyShapedOrLayered(sps []*Space) bool {
+	for _, s := range sps {
+		if s.Shape != nil || s.Layers != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -194,6 +194,16 @@ func (e *Entity) HitLabel(label collision.Label) *collision.Space {
 	return e.Tree.HitLabel(e.Space, label)
 }
 
+// MoveAndSlide shifts the entity by delta, stopping at and sliding along the
+// spaces in its tree it would contact, as narrowed by filters. Spaces sharing the
+// entity's caller ID are ignored. It returns the contacts made.
+func (e *Entity) MoveAndSlide(delta floatgeom.Point2, fs ...collision.Filter) []collision.Contact {
+	fs = append([]collision.Filter{collision.WithoutCIDs(e.CID())}, fs...)
+	moved, contacts := e.Tree.Slide(e.Space, delta, fs...)
+	e.Shift(moved)
+	return contacts
+}
+
 func (e *Entity) Destroy() {
 	e.Renderable.Undraw()
 	e.Tree.Remove(e.Space)
@@ -244,6 +254,7 @@ func New(ctx *scene.Context, opts ...Option) *Entity {
 		Renderable: g.Renderable,
 		Speed:      g.Speed,
 		Children:   children,
+		metadata:   make(map[string]string),
 	}
 
 	if g.Renderable == nil && g.Color != nil {
@@ -0,0 +1,299 @@
+package entities
+
+import (
+	"image/draw"
+	"sync"
+	"sync/atomic"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/dlog"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/oakerr"
+	"github.com/oakmound/oak/v4/render"
+	"github.com/oakmound/oak/v4/scene"
+)
+
+// A Pool reuses entities built from a template of options, to avoid the cost of
+// creating and destroying many short lived entities, like projectiles.
+//
+// A released entity is hidden, its spaces are removed from their tree, and its
+// caller ID is removed from the scene's caller map, so none of its bindings are
+// triggered. Its bindings are kept, and apply again once it is acquired.
+//
+// Pooled entities should be released, not destroyed, and a Pool is only valid for
+// the scene it was created in. Templates should not use explicit children.
+type Pool struct {
+	// MaxIdle is the most released entities kept for reuse. Entities released
+	// past this limit are destroyed. If zero, there is no limit.
+	MaxIdle int
+
+	ctx      *scene.Context
+	template []Option
+
+	lock  sync.Mutex
+	live  map[*Entity]*pooled
+	idle  []*pooled
+	stats PoolStats
+}
+
+// PoolStats reports how a Pool has been used.
+type PoolStats struct {
+	// Hits counts acquisitions which reused a released entity.
+	Hits int
+	// Misses counts acquisitions which had to create a new entity.
+	Misses int
+	// Releases counts entities returned to the pool.
+	Releases int
+	// Live is how many entities are acquired and not yet released.
+	Live int
+	// Idle is how many released entities are waiting to be reused.
+	Idle int
+}
+
+// HitRate returns the fraction of acquisitions which reused a released entity.
+func (ps PoolStats) HitRate() float64 {
+	if ps.Hits+ps.Misses == 0 {
+		return 0
+	}
+	return float64(ps.Hits) / float64(ps.Hits+ps.Misses)
+}
+
+// MissRate returns the fraction of acquisitions which had to create a new entity.
+func (ps PoolStats) MissRate() float64 {
+	if ps.Hits+ps.Misses == 0 {
+		return 0
+	}
+	return float64(ps.Misses) / float64(ps.Hits+ps.Misses)
+}
+
+// pooled tracks an entity and its children, in creation order, alongside what
+// each looked like when created.
+type pooled struct {
+	root      *Entity
+	entities  []*Entity
+	rects     []floatgeom.Rect2
+	speeds    []floatgeom.Point2
+	drawn     []*poolRenderable
+	cidOwners []bool
+}
+
+// poolRenderable is drawn in place of a pooled entity's renderable, so the entity
+// can be hidden while released without leaving the draw heap.
+type poolRenderable struct {
+	render.Renderable
+	hidden int32
+}
+
+func (pr *poolRenderable) Draw(buff draw.Image, xOff, yOff float64) {
+	if atomic.LoadInt32(&pr.hidden) == 1 {
+		return
+	}
+	pr.Renderable.Draw(buff, xOff, yOff)
+}
+
+func (pr *poolRenderable) setHidden(hidden bool) {
+	if hidden {
+		atomic.StoreInt32(&pr.hidden, 1)
+	} else {
+		atomic.StoreInt32(&pr.hidden, 0)
+	}
+}
+
+// NewPool creates a pool of entities created with the given options. Modifiable
+// renderables given as options are copied for each entity the pool creates.
+func NewPool(ctx *scene.Context, template ...Option) *Pool {
+	return &Pool{
+		ctx:      ctx,
+		template: template,
+		live:     make(map[*Entity]*pooled),
+	}
+}
+
+// Acquire returns an entity from the pool, creating a new one if none are idle.
+// Reused entities are reset to the position, speed, and empty metadata they were
+// created with.
+func (p *Pool) Acquire() *Entity {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.acquire(nil)
+}
+
+// AcquireAt acts as Acquire, placing the entity at the given position.
+func (p *Pool) AcquireAt(pos floatgeom.Point2) *Entity {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.acquire(&pos)
+}
+
+func (p *Pool) acquire(pos *floatgeom.Point2) *Entity {
+	var pe *pooled
+	if len(p.idle) != 0 {
+		p.stats.Hits++
+		pe = p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		p.reset(pe, pos)
+	} else {
+		p.stats.Misses++
+		pe = p.create()
+		if pos != nil {
+			p.detach(pe)
+			p.reset(pe, pos)
+		}
+	}
+	p.live[pe.root] = pe
+	return pe.root
+}
+
+// Fill creates entities until the pool has at least n idle entities.
+func (p *Pool) Fill(n int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for len(p.idle) < n {
+		pe := p.create()
+		p.detach(pe)
+		p.idle = append(p.idle, pe)
+	}
+}
+
+// Release returns an acquired entity to the pool.
+func (p *Pool) Release(e *Entity) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	pe, ok := p.live[e]
+	if !ok {
+		return oakerr.NotFound{InputName: "e"}
+	}
+	delete(p.live, e)
+	p.stats.Releases++
+	p.detach(pe)
+	if p.MaxIdle > 0 && len(p.idle) >= p.MaxIdle {
+		p.destroy(pe)
+		return nil
+	}
+	p.idle = append(p.idle, pe)
+	return nil
+}
+
+// Clear destroys all idle entities in the pool.
+func (p *Pool) Clear() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, pe := range p.idle {
+		p.destroy(pe)
+	}
+	p.idle = nil
+}
+
+// Stats reports how this pool has been used.
+func (p *Pool) Stats() PoolStats {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	stats := p.stats
+	stats.Live = len(p.live)
+	stats.Idle = len(p.idle)
+	return stats
+}
+
+func (p *Pool) create() *pooled {
+	// Entities are created depth first, so the layers each entity should be
+	// drawn to are recorded in the same order as the entities are walked below.
+	var layers [][]int
+	var prepare Option
+	prepare = func(g Generator) Generator {
+		layers = append(layers, g.DrawLayers)
+		g.DrawLayers = nil
+		if m, ok := g.Renderable.(render.Modifiable); ok {
+			g.Renderable = m.Copy()
+		}
+		children := make([][]Option, len(g.Children))
+		for i, childOpts := range g.Children {
+			children[i] = append(childOpts[:len(childOpts):len(childOpts)], prepare)
+		}
+		g.Children = children
+		g.ExplicitChildren = nil
+		return g
+	}
+	opts := append(p.template[:len(p.template):len(p.template)], prepare)
+	root := New(p.ctx, opts...)
+
+	pe := &pooled{root: root}
+	var walk func(e *Entity, parent event.CallerID)
+	walk = func(e *Entity, parent event.CallerID) {
+		i := len(pe.entities)
+		pe.entities = append(pe.entities, e)
+		pe.rects = append(pe.rects, e.Rect)
+		pe.speeds = append(pe.speeds, e.Speed)
+		pe.cidOwners = append(pe.cidOwners, e.CallerID != parent)
+		var drawn *poolRenderable
+		if e.Renderable != nil && len(layers[i]) != 0 {
+			drawn = &poolRenderable{Renderable: e.Renderable}
+			p.ctx.Draw(drawn, layers[i]...)
+		}
+		pe.drawn = append(pe.drawn, drawn)
+		for _, c := range e.Children {
+			walk(c, e.CallerID)
+		}
+	}
+	walk(root, 0)
+	return pe
+}
+
+// detach hides a pooled entity and removes it from its tree and caller map.
+func (p *Pool) detach(pe *pooled) {
+	for i, e := range pe.entities {
+		if pe.drawn[i] != nil {
+			pe.drawn[i].setHidden(true)
+		}
+		if e.Tree != nil {
+			e.Tree.Remove(e.Space)
+		}
+		if pe.cidOwners[i] {
+			p.ctx.CallerMap.RemoveEntity(e.CallerID)
+		}
+	}
+}
+
+// reset restores a detached entity to how it was created, optionally moved to
+// a new position, and shows it again.
+func (p *Pool) reset(pe *pooled, pos *floatgeom.Point2) {
+	var delta floatgeom.Point2
+	if pos != nil {
+		delta = pos.Sub(pe.rects[0].Min)
+	}
+	for i, e := range pe.entities {
+		e.Rect = pe.rects[i].Shift(delta)
+		e.Speed = pe.speeds[i]
+		e.Delta = floatgeom.Point2{}
+		for k := range e.metadata {
+			delete(e.metadata, k)
+		}
+		if e.Renderable != nil {
+			e.Renderable.SetPos(e.X(), e.Y())
+		}
+		if pe.cidOwners[i] {
+			if err := p.ctx.CallerMap.Restore(e.CallerID, e); err != nil {
+				dlog.Error("pooled entity could not be restored to the caller map:", err)
+			}
+		}
+		if e.Tree != nil {
+			e.Space.Location = collision.NewRect(e.X(), e.Y(), e.W(), e.H())
+			e.Tree.Add(e.Space)
+		}
+		if pe.drawn[i] != nil {
+			pe.drawn[i].setHidden(false)
+		}
+	}
+}
+
+// destroy permanently removes a detached entity.
+func (p *Pool) destroy(pe *pooled) {
+	for i, e := range pe.entities {
+		if pe.drawn[i] != nil {
+			pe.drawn[i].Undraw()
+		}
+		if pe.cidOwners[i] {
+			p.ctx.UnbindAllFrom(e.CallerID)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package entities
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/render"
+	"github.com/oakmound/oak/v4/scene"
+)
+
+var hit = event.RegisterEvent[struct{}]()
+
+func TestPool(t *testing.T) {
+	cm := event.NewCallerMap()
+	ctx := &scene.Context{
+		CallerMap:     cm,
+		Handler:       event.NewBus(cm),
+		DrawStack:     render.NewDrawStack(render.NewDynamicHeap()),
+		CollisionTree: collision.NewTree(),
+	}
+	p := NewPool(ctx,
+		WithRect(floatgeom.NewRect2WH(1, 1, 4, 4)),
+		WithSpeed(floatgeom.Point2{2, 2}),
+		WithColor(color.RGBA{255, 0, 0, 255}),
+		WithLabel(1),
+		WithChild(WithDimensions(floatgeom.Point2{2, 2}), WithColor(color.RGBA{0, 255, 0, 255})),
+	)
+	e := p.Acquire()
+	hits := make(chan struct{}, 4)
+	event.Bind(ctx, hit, e, func(*Entity, struct{}) event.Response {
+		hits <- struct{}{}
+		return 0
+	})
+	// Let bindings settle
+	time.Sleep(50 * time.Millisecond)
+
+	e.Delta = floatgeom.Point2{1, 0}
+	e.SetMetadata("k", "v")
+	e.ShiftPos(10, 10)
+	if err := p.Release(e); err != nil {
+		t.Fatalf("unexpected error releasing entity: %v", err)
+	}
+	if err := p.Release(e); err == nil {
+		t.Fatalf("expected error releasing entity twice")
+	}
+	if ctx.CollisionTree.HitLabel(collision.NewUnassignedSpace(0, 0, 20, 20), 1) != nil {
+		t.Fatalf("released entity's space should be removed from its tree")
+	}
+	<-event.TriggerForCallerOn(ctx, e.CID(), hit, struct{}{})
+	if len(hits) != 0 {
+		t.Fatalf("released entity should not receive events")
+	}
+
+	e2 := p.AcquireAt(floatgeom.Point2{5, 5})
+	if e2 != e {
+		t.Fatalf("expected released entity to be reused")
+	}
+	if e.Rect != floatgeom.NewRect2WH(5, 5, 4, 4) || e.Renderable.X() != 5 {
+		t.Fatalf("expected reused entity to be placed at 5,5, got %v", e.Rect)
+	}
+	if e.Children[0].Rect.Min != (floatgeom.Point2{5, 5}) {
+		t.Fatalf("expected child to move with reused entity, got %v", e.Children[0].Rect)
+	}
+	if e.Delta != (floatgeom.Point2{}) || e.Speed != (floatgeom.Point2{2, 2}) {
+		t.Fatalf("expected reused entity's movement to be reset")
+	}
+	if _, ok := e.Metadata("k"); ok {
+		t.Fatalf("expected reused entity's metadata to be cleared")
+	}
+	if ctx.CollisionTree.HitLabel(collision.NewUnassignedSpace(0, 0, 20, 20), 1) == nil {
+		t.Fatalf("reused entity's space should be in its tree")
+	}
+	<-event.TriggerForCallerOn(ctx, e.CID(), hit, struct{}{})
+	if len(hits) != 1 {
+		t.Fatalf("reused entity should keep its bindings")
+	}
+
+	e3 := p.Acquire()
+	if e3 == e || e3.Renderable == e.Renderable {
+		t.Fatalf("expected a new entity with its own renderable")
+	}
+	p.MaxIdle = 1
+	p.Release(e)
+	p.Release(e3)
+	p.Fill(3)
+	stats := p.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Releases != 3 || stats.Live != 0 || stats.Idle != 3 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats.HitRate() != 1.0/3 || stats.MissRate() != 2.0/3 {
+		t.Fatalf("unexpected rates: %v %v", stats.HitRate(), stats.MissRate())
+	}
+	p.Clear()
+	if p.Stats().Idle != 0 {
+		t.Fatalf("expected no idle entities after clear")
+	}
+}
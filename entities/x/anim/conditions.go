@@ -0,0 +1,70 @@
+package anim
+
+import (
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/entities"
+)
+
+// Not inverts a condition.
+func Not(c Condition) Condition {
+	return func(e *entities.Entity) bool {
+		return !c(e)
+	}
+}
+
+// And is true when all of the input conditions are true.
+func And(cs ...Condition) Condition {
+	return func(e *entities.Entity) bool {
+		for _, c := range cs {
+			if !c(e) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or is true when any of the input conditions are true.
+func Or(cs ...Condition) Condition {
+	return func(e *entities.Entity) bool {
+		for _, c := range cs {
+			if c(e) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Moving is true when an entity's Delta is not zero.
+func Moving(e *entities.Entity) bool {
+	return e.Delta != (floatgeom.Point2{})
+}
+
+// MovingLeft is true when an entity's Delta points left.
+func MovingLeft(e *entities.Entity) bool {
+	return e.Delta.X() < 0
+}
+
+// MovingRight is true when an entity's Delta points right.
+func MovingRight(e *entities.Entity) bool {
+	return e.Delta.X() > 0
+}
+
+// MovingUp is true when an entity's Delta points up.
+func MovingUp(e *entities.Entity) bool {
+	return e.Delta.Y() < 0
+}
+
+// MovingDown is true when an entity's Delta points down.
+func MovingDown(e *entities.Entity) bool {
+	return e.Delta.Y() > 0
+}
+
+// Metadata is true when an entity's metadata for key k is v.
+func Metadata(k, v string) Condition {
+	return func(e *entities.Entity) bool {
+		v2, ok := e.Metadata(k)
+		return ok && v2 == v
+	}
+}
@@ -0,0 +1,3 @@
+// Package anim provides a declarative animation state machine, which chooses what
+// an entity's render.Switch shows from the entity's state and events.
+package anim
@@ -0,0 +1,345 @@
+package anim
+
+import (
+	"sync"
+
+	"github.com/oakmound/oak/v4/entities"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/oakerr"
+	"github.com/oakmound/oak/v4/render"
+	"github.com/oakmound/oak/v4/scene"
+)
+
+// Any can be used as the source state of a transition to allow that transition
+// from every state. Transitions from specific states are checked before
+// transitions from Any.
+const Any = "*"
+
+// A Change is sent with the Changed event when a Machine changes states.
+type Change struct {
+	From, To string
+}
+
+// Changed: when a Machine changes its entity's state. Triggered on the entity.
+var Changed = event.RegisterEvent[Change]()
+
+// A Condition decides whether a transition should be taken from an entity's state.
+type Condition func(*entities.Entity) bool
+
+// A State is a named state of an animation Machine, shown by a key of the
+// Machine's Switch.
+type State struct {
+	Name string
+	// Key is the Switch key shown in this state.
+	Key string
+	// Return, if set, is the state to return to once this state's animation ends.
+	Return string
+	// Restart causes this state's animation to start from its first frame
+	// whenever the state is entered.
+	Restart bool
+	// Uninterruptible states can only be left once their animation ends, unless
+	// they are left through a transition that waits for the animation to end.
+	Uninterruptible bool
+
+	transitions []*Transition
+}
+
+// A StateOption modifies a State as it is added to a Machine.
+type StateOption func(*State)
+
+// Key sets the Switch key shown in a state, if it differs from the state's name.
+func Key(k string) StateOption {
+	return func(s *State) {
+		s.Key = k
+	}
+}
+
+// PlayOnce causes a state's animation to restart whenever it is entered and to
+// return to the given state when it ends.
+func PlayOnce(returnTo string) StateOption {
+	return func(s *State) {
+		s.Return = returnTo
+		s.Restart = true
+	}
+}
+
+// Restart causes a state's animation to start from its first frame whenever the
+// state is entered.
+func Restart() StateOption {
+	return func(s *State) {
+		s.Restart = true
+	}
+}
+
+// Uninterruptible prevents a state from being left until its animation ends.
+func Uninterruptible() StateOption {
+	return func(s *State) {
+		s.Uninterruptible = true
+	}
+}
+
+// A Transition moves a Machine from one state to another.
+type Transition struct {
+	To string
+	// Condition must be true for the transition to be taken. A nil Condition is
+	// always true.
+	Condition Condition
+	// AfterEnd transitions wait for the current animation to end.
+	AfterEnd bool
+
+	// event transitions are taken when their event fires, rather than polled.
+	event bool
+}
+
+// A TransitionOption modifies a Transition as it is added to a Machine.
+type TransitionOption func(*Transition)
+
+// AfterEnd causes a transition to wait until the current state's animation ends.
+func AfterEnd() TransitionOption {
+	return func(t *Transition) {
+		t.AfterEnd = true
+	}
+}
+
+// A Machine is an animation state machine which chooses what an entity's Switch
+// shows. Each frame, the Machine checks the transitions out of its current state
+// in the order they were added and takes the first one that is allowed.
+//
+// A Machine takes over the trigger IDs of the Switch's animations, so only the
+// animation currently shown triggers AnimationEnd on the entity, and has them
+// trigger on the Machine's scene.
+type Machine struct {
+	Entity *entities.Entity
+	Switch *render.Switch
+
+	ctx      *scene.Context
+	lock     sync.Mutex
+	states   map[string]*State
+	current  *State
+	ended    bool
+	pending  []string
+	bindings []event.Binding
+}
+
+// NewMachine creates an animation state machine for an entity whose Renderable is
+// a *render.Switch.
+func NewMachine(ctx *scene.Context, e *entities.Entity) (*Machine, error) {
+	sw, ok := e.Renderable.(*render.Switch)
+	if !ok {
+		return nil, oakerr.InvalidInput{InputName: "e.Renderable"}
+	}
+	return &Machine{
+		Entity: e,
+		Switch: sw,
+		ctx:    ctx,
+		states: make(map[string]*State),
+	}, nil
+}
+
+// AddState adds a state to this machine. By default, the state shows the Switch
+// key matching its name.
+func (m *Machine) AddState(name string, opts ...StateOption) error {
+	s := &State{Name: name, Key: name}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if m.Switch.GetSub(s.Key) == nil {
+		return oakerr.NotFound{InputName: "Key:" + s.Key}
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.states[name]; ok {
+		return oakerr.ExistingElement{InputName: name, InputType: "State"}
+	}
+	m.states[name] = s
+	return nil
+}
+
+// AddTransition adds a transition from one state, or Any, to another, taken when
+// cond is true.
+func (m *Machine) AddTransition(from, to string, cond Condition, opts ...TransitionOption) error {
+	t := &Transition{To: to, Condition: cond}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return m.addTransition(from, t)
+}
+
+func (m *Machine) addTransition(from string, t *Transition) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.states[t.To]; !ok {
+		return oakerr.NotFound{InputName: "to:" + t.To}
+	}
+	if from == Any {
+		if _, ok := m.states[Any]; !ok {
+			m.states[Any] = &State{Name: Any}
+		}
+	} else if _, ok := m.states[from]; !ok {
+		return oakerr.NotFound{InputName: "from:" + from}
+	}
+	m.states[from].transitions = append(m.states[from].transitions, t)
+	return nil
+}
+
+// AddEventTransition adds a transition from one state, or Any, to another, taken
+// on the frame after ev is triggered on the machine's entity, if accept returns
+// true for its payload. A nil accept accepts every payload.
+func AddEventTransition[T any](m *Machine, from, to string, ev event.EventID[T], accept func(T) bool, opts ...TransitionOption) error {
+	t := &Transition{To: to, event: true}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if err := m.addTransition(from, t); err != nil {
+		return err
+	}
+	b := event.Bind(m.ctx, ev, m.Entity, func(_ *entities.Entity, payload T) event.Response {
+		if accept != nil && !accept(payload) {
+			return 0
+		}
+		m.lock.Lock()
+		if m.current != nil && (from == Any || m.current.Name == from) {
+			m.pending = append(m.pending, to)
+		}
+		m.lock.Unlock()
+		return 0
+	})
+	m.lock.Lock()
+	m.bindings = append(m.bindings, b)
+	m.lock.Unlock()
+	return nil
+}
+
+// Start enters the initial state and begins updating this machine every frame.
+func (m *Machine) Start(initial string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	s, ok := m.states[initial]
+	if !ok || initial == Any {
+		return oakerr.NotFound{InputName: "initial:" + initial}
+	}
+	m.Switch.SetTriggerID(0)
+	m.Switch.SetTriggerBus(m.ctx)
+	m.enter(s)
+	m.bindings = append(m.bindings,
+		event.Bind(m.ctx, event.Enter, m.Entity, func(_ *entities.Entity, _ event.EnterPayload) event.Response {
+			m.Update()
+			return 0
+		}),
+		event.Bind(m.ctx, render.AnimationEnd, m.Entity, func(_ *entities.Entity, _ struct{}) event.Response {
+			m.lock.Lock()
+			m.ended = true
+			m.lock.Unlock()
+			return 0
+		}),
+	)
+	return nil
+}
+
+// Stop stops updating this machine and unbinds its event transitions. Its Switch
+// keeps showing the current state. A stopped machine cannot be restarted.
+func (m *Machine) Stop() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, b := range m.bindings {
+		b.Unbind()
+	}
+	m.bindings = nil
+}
+
+// Current returns the name of the machine's current state, or the empty string
+// if the machine has not started.
+func (m *Machine) Current() string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.current == nil {
+		return ""
+	}
+	return m.current.Name
+}
+
+// Set immediately moves the machine to the named state, ignoring transitions.
+func (m *Machine) Set(name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	s, ok := m.states[name]
+	if !ok || name == Any {
+		return oakerr.NotFound{InputName: "name:" + name}
+	}
+	m.enter(s)
+	return nil
+}
+
+// Update takes at most one transition from the machine's current state. It is
+// called every frame once the machine has started.
+func (m *Machine) Update() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.current == nil {
+		return
+	}
+	pending := m.pending
+	m.pending = nil
+	interruptible := m.ended || (!m.current.Uninterruptible && m.Switch.IsInterruptable())
+	for _, src := range []*State{m.current, m.states[Any]} {
+		if src == nil {
+			continue
+		}
+		for _, t := range src.transitions {
+			if !m.allowed(t, interruptible, pending) {
+				continue
+			}
+			if t.To != m.current.Name || t.event {
+				m.enter(m.states[t.To])
+			}
+			return
+		}
+	}
+	if m.ended && m.current.Return != "" {
+		if s, ok := m.states[m.current.Return]; ok {
+			m.enter(s)
+		}
+	}
+}
+
+func (m *Machine) allowed(t *Transition, interruptible bool, pending []string) bool {
+	if t.AfterEnd {
+		if !m.ended {
+			return false
+		}
+	} else if !interruptible {
+		return false
+	}
+	if t.event {
+		for _, to := range pending {
+			if to == t.To {
+				return true
+			}
+		}
+		return false
+	}
+	return t.Condition == nil || t.Condition(m.Entity)
+}
+
+// enter shows a state's key and resets the machine's tracking of whether its
+// animation has ended.
+func (m *Machine) enter(s *State) {
+	from := ""
+	if m.current != nil {
+		from = m.current.Name
+		if t, ok := m.Switch.GetSub(m.current.Key).(render.Triggerable); ok {
+			t.SetTriggerID(0)
+		}
+	}
+	m.current = s
+	m.ended = false
+	m.Switch.Set(s.Key)
+	sub := m.Switch.GetSub(s.Key)
+	if r, ok := sub.(interface{ Reset() }); ok && s.Restart {
+		r.Reset()
+	}
+	if t, ok := sub.(render.Triggerable); ok {
+		t.SetTriggerID(m.Entity.CID())
+	}
+	event.TriggerForCallerOn(m.ctx, m.Entity.CID(), Changed, Change{From: from, To: s.Name})
+}
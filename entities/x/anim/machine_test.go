@@ -0,0 +1,156 @@
+package anim
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/entities"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/render"
+	"github.com/oakmound/oak/v4/scene"
+)
+
+func testSequence() *render.Sequence {
+	return render.NewSequence(1,
+		render.NewColorBox(1, 1, color.RGBA{255, 0, 0, 255}),
+		render.NewColorBox(1, 1, color.RGBA{0, 255, 0, 255}),
+	)
+}
+
+var attack = event.RegisterEvent[int]()
+
+func TestMachine(t *testing.T) {
+	cm := event.NewCallerMap()
+	ctx := &scene.Context{CallerMap: cm, Handler: event.NewBus(cm)}
+	sw := render.NewSwitch("idle", map[string]render.Modifiable{
+		"idle":   testSequence(),
+		"run":    testSequence(),
+		"attack": testSequence(),
+	})
+	e := entities.New(ctx, entities.WithRenderable(sw), entities.WithDrawLayers(nil), entities.WithWithoutCollision(true))
+	m, err := NewMachine(ctx, e)
+	if err != nil {
+		t.Fatalf("unexpected error creating machine: %v", err)
+	}
+	if err := m.AddState("missing"); err == nil {
+		t.Fatalf("expected error adding state without switch key")
+	}
+	for _, err := range []error{
+		m.AddState("idle"),
+		m.AddState("walk", Key("run")),
+		m.AddState("attack", PlayOnce("idle"), Uninterruptible()),
+		m.AddTransition("idle", "walk", Moving),
+		m.AddTransition("walk", "idle", Not(Moving)),
+		AddEventTransition(m, Any, "attack", attack, func(power int) bool { return power > 0 }),
+	} {
+		if err != nil {
+			t.Fatalf("unexpected error building machine: %v", err)
+		}
+	}
+	if err := m.AddTransition("idle", "fly", nil); err == nil {
+		t.Fatalf("expected error adding transition to missing state")
+	}
+	if err := m.Start("walk"); err != nil {
+		t.Fatalf("unexpected error starting machine: %v", err)
+	}
+	if sw.Get() != "run" {
+		t.Fatalf("expected switch to show run, got %v", sw.Get())
+	}
+	m.Update()
+	if m.Current() != "idle" || sw.Get() != "idle" {
+		t.Fatalf("expected idle when not moving, got %v", m.Current())
+	}
+	e.Delta = floatgeom.Point2{1, 0}
+	m.Update()
+	if m.Current() != "walk" {
+		t.Fatalf("expected walk when moving, got %v", m.Current())
+	}
+	// Let bindings settle
+	time.Sleep(50 * time.Millisecond)
+	<-event.TriggerForCallerOn(ctx, e.CID(), attack, 0)
+	m.Update()
+	if m.Current() != "walk" {
+		t.Fatalf("expected rejected event not to transition, got %v", m.Current())
+	}
+	<-event.TriggerForCallerOn(ctx, e.CID(), attack, 1)
+	m.Update()
+	if m.Current() != "attack" {
+		t.Fatalf("expected event transition to attack, got %v", m.Current())
+	}
+	// Uninterruptible states wait for their animation to end
+	<-event.TriggerForCallerOn(ctx, e.CID(), attack, 1)
+	m.Update()
+	if m.Current() != "attack" || sw.Get() != "attack" {
+		t.Fatalf("expected attack to be uninterruptible, got %v", m.Current())
+	}
+	<-event.TriggerForCallerOn(ctx, e.CID(), render.AnimationEnd, struct{}{})
+	m.Update()
+	if m.Current() != "idle" {
+		t.Fatalf("expected attack to return to idle, got %v", m.Current())
+	}
+	m.Stop()
+}
+
+func TestMachineAnimationEndOnSceneBus(t *testing.T) {
+	// The scene has its own bus, not event.DefaultBus
+	cm := event.NewCallerMap()
+	ctx := &scene.Context{CallerMap: cm, Handler: event.NewBus(cm)}
+	sw := render.NewSwitch("idle", map[string]render.Modifiable{
+		"idle": testSequence(),
+		"attack": render.NewSequence(100,
+			render.NewColorBox(1, 1, color.RGBA{255, 0, 0, 255}),
+			render.NewColorBox(1, 1, color.RGBA{0, 255, 0, 255}),
+		),
+	})
+	e := entities.New(ctx, entities.WithRenderable(sw), entities.WithDrawLayers(nil), entities.WithWithoutCollision(true))
+	m, err := NewMachine(ctx, e)
+	if err != nil {
+		t.Fatalf("unexpected error creating machine: %v", err)
+	}
+	if err := m.AddState("idle"); err != nil {
+		t.Fatalf("unexpected error building machine: %v", err)
+	}
+	if err := m.AddState("attack", PlayOnce("idle"), Uninterruptible()); err != nil {
+		t.Fatalf("unexpected error building machine: %v", err)
+	}
+	if err := m.Start("attack"); err != nil {
+		t.Fatalf("unexpected error starting machine: %v", err)
+	}
+	defer m.Stop()
+	buff := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	deadline := time.Now().Add(2 * time.Second)
+	for m.Current() != "idle" {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected attack to end and return to idle")
+		}
+		// Drawing advances the attack animation
+		sw.Draw(buff, 0, 0)
+		time.Sleep(15 * time.Millisecond)
+		m.Update()
+	}
+}
+
+func TestNewMachineInvalid(t *testing.T) {
+	cm := event.NewCallerMap()
+	ctx := &scene.Context{CallerMap: cm, Handler: event.NewBus(cm)}
+	e := entities.New(ctx, entities.WithColor(color.RGBA{255, 0, 0, 255}), entities.WithDrawLayers(nil), entities.WithWithoutCollision(true))
+	if _, err := NewMachine(ctx, e); err == nil {
+		t.Fatalf("expected error creating machine without a switch")
+	}
+}
+
+func TestConditions(t *testing.T) {
+	e := &entities.Entity{Delta: floatgeom.Point2{-1, 1}}
+	if !And(Moving, MovingLeft, MovingDown)(e) {
+		t.Fatalf("expected moving left and down")
+	}
+	if Or(MovingRight, MovingUp)(e) {
+		t.Fatalf("expected not moving right or up")
+	}
+	if Metadata("k", "v")(e) {
+		t.Fatalf("expected missing metadata to be false")
+	}
+}
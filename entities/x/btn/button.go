@@ -28,6 +28,7 @@ type Generator struct {
 	R1           render.Modifiable
 	R2           render.Modifiable
 	RS           []render.Modifiable
+	NineSlice    *render.NineSlice
 	Cid          event.CallerID
 	Font         *render.Font
 	Layers       []int
@@ -71,6 +72,10 @@ func (g Generator) Generate(ctx *scene.Context) *entities.Entity {
 	switch {
 	case g.R != nil:
 		box = g.R
+	case g.NineSlice != nil:
+		ns := g.NineSlice.Copy().(*render.NineSlice)
+		ns.Resize(int(g.W), int(g.H))
+		box = ns
 	case g.ProgressFunc != nil:
 		box = render.NewGradientBox(int(g.W), int(g.H), g.Color, g.Color2, g.ProgressFunc)
 		if g.Shape != nil {
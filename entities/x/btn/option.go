@@ -128,6 +128,16 @@ func Renderable(r render.Modifiable) Option {
 	}
 }
 
+// NineSlice sets a nine slice to use as the background of the button, resized
+// to the button's width and height. The nine slice given is copied, so it can
+// be shared between buttons of different sizes. Not compatible with Renderable.
+func NineSlice(ns *render.NineSlice) Option {
+	return func(g Generator) Generator {
+		g.NineSlice = ns
+		return g
+	}
+}
+
 // Binding appends a function to be called when a specific event
 // is triggered.
 func Binding[Payload any](ev event.EventID[Payload], bnd event.Bindable[*entities.Entity, Payload]) Option {
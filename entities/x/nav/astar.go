@@ -0,0 +1,133 @@
+package nav
+
+import (
+	"container/heap"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+)
+
+// openNode is an entry in the open set of a search.
+type openNode struct {
+	idx int
+	f   float64
+}
+
+// openSet is a min-heap of nodes by estimated total cost. Stale entries are not
+// removed when a node's cost improves; they are skipped when popped.
+type openSet []openNode
+
+func (o openSet) Len() int            { return len(o) }
+func (o openSet) Less(i, j int) bool  { return o[i].f < o[j].f }
+func (o openSet) Swap(i, j int)       { o[i], o[j] = o[j], o[i] }
+func (o *openSet) Push(x interface{}) { *o = append(*o, x.(openNode)) }
+func (o *openSet) Pop() interface{} {
+	old := *o
+	n := old[len(old)-1]
+	*o = old[:len(old)-1]
+	return n
+}
+
+// searchState tracks the per-node bookkeeping of a search over n nodes.
+type searchState struct {
+	open   openSet
+	g      []float64
+	parent []int
+	closed []bool
+}
+
+func newSearchState(n int) *searchState {
+	s := &searchState{
+		g:      make([]float64, n),
+		parent: make([]int, n),
+		closed: make([]bool, n),
+	}
+	for i := range s.parent {
+		s.parent[i] = -1
+	}
+	return s
+}
+
+// relax records a path to idx through from with total cost g, if it is better than
+// any path to idx seen so far. It returns whether the path was recorded.
+func (s *searchState) relax(from, idx int, g, h float64) bool {
+	if s.closed[idx] {
+		return false
+	}
+	if s.parent[idx] != -1 && g >= s.g[idx] {
+		return false
+	}
+	s.g[idx] = g
+	s.parent[idx] = from
+	heap.Push(&s.open, openNode{idx: idx, f: g + h})
+	return true
+}
+
+// next pops the cheapest open node which has not yet been closed.
+func (s *searchState) next() (int, bool) {
+	for s.open.Len() > 0 {
+		n := heap.Pop(&s.open).(openNode)
+		if s.closed[n.idx] {
+			continue
+		}
+		s.closed[n.idx] = true
+		return n.idx, true
+	}
+	return 0, false
+}
+
+// trace returns the nodes from goal back to the search's start, inclusive.
+func (s *searchState) trace(goal int) []int {
+	out := []int{goal}
+	for cur := goal; s.parent[cur] != cur; cur = s.parent[cur] {
+		out = append(out, s.parent[cur])
+	}
+	return out
+}
+
+var neighborDirs = [8][2]int{
+	{1, 0}, {-1, 0}, {0, 1}, {0, -1},
+	{1, 1}, {1, -1}, {-1, 1}, {-1, -1},
+}
+
+// AStar finds a path between two world points by searching each neighboring cell
+// with A*. The start cell may be blocked, but the goal cell may not.
+func (g *Grid) AStar(from, to floatgeom.Point2) ([]floatgeom.Point2, error) {
+	start, goal, err := g.endpoints(from, to)
+	if err != nil {
+		return nil, err
+	}
+	gx, gy := goal%g.w, goal/g.w
+	dirs := neighborDirs[:]
+	if g.Diagonal == DiagonalNever {
+		dirs = dirs[:4]
+	}
+	s := newSearchState(len(g.blocked))
+	s.relax(start, start, 0, g.heuristic(start%g.w, start/g.w, gx, gy))
+	for {
+		cur, ok := s.next()
+		if !ok {
+			return nil, ErrNoPath
+		}
+		if cur == goal {
+			return g.toPath(s.trace(goal), to), nil
+		}
+		x, y := cur%g.w, cur/g.w
+		for _, d := range dirs {
+			nx, ny := x+d[0], y+d[1]
+			if !g.walkable(nx, ny) || !g.canStep(x, y, d[0], d[1]) {
+				continue
+			}
+			cost := s.g[cur] + g.heuristic(x, y, nx, ny)
+			s.relax(cur, ny*g.w+nx, cost, g.heuristic(nx, ny, gx, gy))
+		}
+	}
+}
+
+// endpoints converts two world points into cell indices for a search.
+func (g *Grid) endpoints(from, to floatgeom.Point2) (start, goal int, err error) {
+	sc, gc := g.Cell(from), g.Cell(to)
+	if !g.InBounds(sc) || g.Blocked(gc) {
+		return 0, 0, ErrNoPath
+	}
+	return sc.Y()*g.w + sc.X(), gc.Y()*g.w + gc.X(), nil
+}
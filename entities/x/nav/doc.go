@@ -0,0 +1,3 @@
+// Package nav provides grid and navmesh pathfinding over collision trees,
+// along with a mover that walks entities along the resulting paths.
+package nav
@@ -0,0 +1,348 @@
+package nav
+
+import (
+	"math"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/alg/intgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/oakerr"
+)
+
+// ErrNoPath is returned by Finders when no path exists between two points.
+var ErrNoPath = oakerr.NotFound{InputName: "path"}
+
+// A Finder can find paths between two points. Paths do not include their starting
+// point and end on their target point.
+type Finder interface {
+	FindPath(from, to floatgeom.Point2) ([]floatgeom.Point2, error)
+}
+
+// A Refresher is a Finder whose navigation data can be rebuilt from its source
+// collision tree, e.g. after obstacles have moved.
+type Refresher interface {
+	Finder
+	RefreshRect(floatgeom.Rect2)
+}
+
+var (
+	_ Refresher = &Grid{}
+	_ Finder    = &NavMesh{}
+)
+
+// A Diagonal rule determines when a path may move diagonally between two grid cells.
+type Diagonal int
+
+const (
+	// DiagonalNever disallows diagonal movement entirely.
+	DiagonalNever Diagonal = iota
+	// DiagonalNoObstacles allows diagonal movement only when both orthogonally
+	// adjacent cells are open, so paths never cut corners.
+	DiagonalNoObstacles
+	// DiagonalOneObstacle allows diagonal movement when at least one of the
+	// orthogonally adjacent cells is open.
+	DiagonalOneObstacle
+	// DiagonalAlways allows diagonal movement whenever the destination cell is open.
+	DiagonalAlways
+)
+
+// A Search is an algorithm a Grid uses to find paths.
+type Search int
+
+const (
+	// SearchAStar searches every neighboring cell with A*.
+	SearchAStar Search = iota
+	// SearchJumpPoint searches with jump point search, which prunes symmetric
+	// paths and is generally faster on large, open grids.
+	SearchJumpPoint
+)
+
+// A Grid is a navigation grid rasterized from the spaces in a collision tree.
+// Any cell overlapping a blocking space cannot be walked through.
+type Grid struct {
+	// Bounds is the area of the world this grid covers.
+	Bounds floatgeom.Rect2
+	// CellSize is the world size of each cell.
+	CellSize floatgeom.Point2
+	// Tree is the collision tree the grid is built from.
+	Tree *collision.Tree
+	// Labels are the labels of spaces which block movement. If empty, every space
+	// in Tree blocks movement.
+	Labels []collision.Label
+	// Clearance grows each blocking space by this amount on every side when
+	// rasterizing, to keep agents larger than a point away from walls.
+	Clearance float64
+	Diagonal  Diagonal
+	Search    Search
+
+	w, h    int
+	blocked []bool
+}
+
+// A GridOption modifies a Grid before it is first rasterized.
+type GridOption func(*Grid)
+
+// WithTree sets the collision tree a Grid is built from.
+func WithTree(t *collision.Tree) GridOption {
+	return func(g *Grid) {
+		g.Tree = t
+	}
+}
+
+// BlockingLabels sets which labeled spaces block movement on a Grid.
+func BlockingLabels(ls ...collision.Label) GridOption {
+	return func(g *Grid) {
+		g.Labels = ls
+	}
+}
+
+// WithClearance sets how far a Grid keeps paths away from blocking spaces.
+func WithClearance(clearance float64) GridOption {
+	return func(g *Grid) {
+		g.Clearance = clearance
+	}
+}
+
+// WithDiagonal sets the diagonal movement rule of a Grid.
+func WithDiagonal(d Diagonal) GridOption {
+	return func(g *Grid) {
+		g.Diagonal = d
+	}
+}
+
+// WithSearch sets the algorithm a Grid uses in FindPath.
+func WithSearch(s Search) GridOption {
+	return func(g *Grid) {
+		g.Search = s
+	}
+}
+
+// NewGrid creates a navigation grid covering bounds, split into cells of cellSize,
+// and rasterizes it from its collision tree. By default the grid is built from
+// collision.DefaultTree, searches with A*, and does not allow paths to cut corners.
+func NewGrid(bounds floatgeom.Rect2, cellSize floatgeom.Point2, opts ...GridOption) (*Grid, error) {
+	if cellSize.X() <= 0 || cellSize.Y() <= 0 {
+		return nil, oakerr.InvalidInput{InputName: "cellSize"}
+	}
+	g := &Grid{
+		Bounds:   bounds,
+		CellSize: cellSize,
+		Tree:     collision.DefaultTree,
+		Diagonal: DiagonalNoObstacles,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.w = int(math.Ceil(bounds.W() / cellSize.X()))
+	g.h = int(math.Ceil(bounds.H() / cellSize.Y()))
+	if g.w <= 0 || g.h <= 0 {
+		return nil, oakerr.InvalidInput{InputName: "bounds"}
+	}
+	g.blocked = make([]bool, g.w*g.h)
+	g.Refresh()
+	return g, nil
+}
+
+// Size returns the width and height of the grid in cells.
+func (g *Grid) Size() (w, h int) {
+	return g.w, g.h
+}
+
+// Refresh rebuilds the entire grid from its collision tree.
+func (g *Grid) Refresh() {
+	g.RefreshRect(g.Bounds)
+}
+
+// RefreshRect rebuilds the cells of the grid overlapping the given world rectangle
+// from its collision tree.
+func (g *Grid) RefreshRect(r floatgeom.Rect2) {
+	min, max, ok := g.cellRange(r)
+	if !ok {
+		return
+	}
+	for y := min.Y(); y <= max.Y(); y++ {
+		for x := min.X(); x <= max.X(); x++ {
+			g.blocked[y*g.w+x] = false
+		}
+	}
+	if g.Tree == nil {
+		return
+	}
+	// Search the full extent of the cells being rebuilt, grown by clearance, so
+	// inflated spaces just outside of r still affect it.
+	search := floatgeom.Rect2{
+		Min: g.cellRect(min).Min.Sub(floatgeom.Point2{g.Clearance, g.Clearance}),
+		Max: g.cellRect(max).Max.Add(floatgeom.Point2{g.Clearance, g.Clearance}),
+	}
+	for _, s := range g.Tree.SearchIntersect(flatRect(search)) {
+		if !g.blocks(s) {
+			continue
+		}
+		loc := s.Location.ProjectZ()
+		loc.Min = loc.Min.Sub(floatgeom.Point2{g.Clearance, g.Clearance})
+		loc.Max = loc.Max.Add(floatgeom.Point2{g.Clearance, g.Clearance})
+		smin, smax, ok := g.cellRange(loc)
+		if !ok {
+			continue
+		}
+		smin = smin.GreaterOf(min)
+		smax = smax.LesserOf(max)
+		for y := smin.Y(); y <= smax.Y(); y++ {
+			for x := smin.X(); x <= smax.X(); x++ {
+				g.blocked[y*g.w+x] = true
+			}
+		}
+	}
+}
+
+func (g *Grid) blocks(s *collision.Space) bool {
+	if len(g.Labels) == 0 {
+		return true
+	}
+	for _, l := range g.Labels {
+		if s.Label == l {
+			return true
+		}
+	}
+	return false
+}
+
+// cellRange returns the inclusive range of cells strictly overlapping r, and whether
+// any cells overlap r at all.
+func (g *Grid) cellRange(r floatgeom.Rect2) (min, max intgeom.Point2, ok bool) {
+	x0 := int(math.Floor((r.Min.X() - g.Bounds.Min.X()) / g.CellSize.X()))
+	y0 := int(math.Floor((r.Min.Y() - g.Bounds.Min.Y()) / g.CellSize.Y()))
+	x1 := int(math.Ceil((r.Max.X()-g.Bounds.Min.X())/g.CellSize.X())) - 1
+	y1 := int(math.Ceil((r.Max.Y()-g.Bounds.Min.Y())/g.CellSize.Y())) - 1
+	if x0 < 0 {
+		x0 = 0
+	}
+	if y0 < 0 {
+		y0 = 0
+	}
+	if x1 >= g.w {
+		x1 = g.w - 1
+	}
+	if y1 >= g.h {
+		y1 = g.h - 1
+	}
+	if x0 > x1 || y0 > y1 {
+		return min, max, false
+	}
+	return intgeom.Point2{x0, y0}, intgeom.Point2{x1, y1}, true
+}
+
+func (g *Grid) cellRect(c intgeom.Point2) floatgeom.Rect2 {
+	return floatgeom.NewRect2WH(
+		g.Bounds.Min.X()+float64(c.X())*g.CellSize.X(),
+		g.Bounds.Min.Y()+float64(c.Y())*g.CellSize.Y(),
+		g.CellSize.X(),
+		g.CellSize.Y(),
+	)
+}
+
+// flatRect converts a Rect2 to a Rect3 covering every z layer.
+func flatRect(r floatgeom.Rect2) floatgeom.Rect3 {
+	return floatgeom.Rect3{
+		Min: floatgeom.Point3{r.Min.X(), r.Min.Y(), math.Inf(-1)},
+		Max: floatgeom.Point3{r.Max.X(), r.Max.Y(), math.Inf(1)},
+	}
+}
+
+// Cell returns the cell containing the given world point. The returned cell may
+// be outside of the grid.
+func (g *Grid) Cell(p floatgeom.Point2) intgeom.Point2 {
+	return intgeom.Point2{
+		int(math.Floor((p.X() - g.Bounds.Min.X()) / g.CellSize.X())),
+		int(math.Floor((p.Y() - g.Bounds.Min.Y()) / g.CellSize.Y())),
+	}
+}
+
+// CellCenter returns the world position of the center of the given cell.
+func (g *Grid) CellCenter(c intgeom.Point2) floatgeom.Point2 {
+	return g.cellRect(c).Center()
+}
+
+// InBounds returns whether a cell is within the grid.
+func (g *Grid) InBounds(c intgeom.Point2) bool {
+	return c.X() >= 0 && c.Y() >= 0 && c.X() < g.w && c.Y() < g.h
+}
+
+// Blocked returns whether a cell cannot be walked through. Cells outside of
+// the grid are always blocked.
+func (g *Grid) Blocked(c intgeom.Point2) bool {
+	return !g.walkable(c.X(), c.Y())
+}
+
+// SetBlocked manually marks a cell as blocked or open. Manual changes are lost
+// when the cell is refreshed.
+func (g *Grid) SetBlocked(c intgeom.Point2, blocked bool) {
+	if !g.InBounds(c) {
+		return
+	}
+	g.blocked[c.Y()*g.w+c.X()] = blocked
+}
+
+func (g *Grid) walkable(x, y int) bool {
+	if x < 0 || y < 0 || x >= g.w || y >= g.h {
+		return false
+	}
+	return !g.blocked[y*g.w+x]
+}
+
+// canStep returns whether the grid's diagonal rule allows moving from x,y by dx,dy,
+// assuming the destination is walkable.
+func (g *Grid) canStep(x, y, dx, dy int) bool {
+	if dx == 0 || dy == 0 {
+		return true
+	}
+	switch g.Diagonal {
+	case DiagonalAlways:
+		return true
+	case DiagonalOneObstacle:
+		return g.walkable(x+dx, y) || g.walkable(x, y+dy)
+	case DiagonalNoObstacles:
+		return g.walkable(x+dx, y) && g.walkable(x, y+dy)
+	}
+	return false
+}
+
+// heuristic returns the cost of the shortest unobstructed path between two cells
+// under this grid's diagonal rule.
+func (g *Grid) heuristic(x1, y1, x2, y2 int) float64 {
+	dx := math.Abs(float64(x2 - x1))
+	dy := math.Abs(float64(y2 - y1))
+	if g.Diagonal == DiagonalNever {
+		return dx*g.CellSize.X() + dy*g.CellSize.Y()
+	}
+	diag := math.Min(dx, dy)
+	return diag*g.diagonalCost() + (dx-diag)*g.CellSize.X() + (dy-diag)*g.CellSize.Y()
+}
+
+func (g *Grid) diagonalCost() float64 {
+	return math.Hypot(g.CellSize.X(), g.CellSize.Y())
+}
+
+// FindPath finds a path between two world points with the grid's search algorithm.
+// The path passes through cell centers and ends exactly on the target point.
+func (g *Grid) FindPath(from, to floatgeom.Point2) ([]floatgeom.Point2, error) {
+	if g.Search == SearchJumpPoint {
+		return g.JumpPoint(from, to)
+	}
+	return g.AStar(from, to)
+}
+
+// toPath converts a list of cell indices, ordered from goal to start and including
+// the start, into a world path from start to goal.
+func (g *Grid) toPath(reversed []int, to floatgeom.Point2) []floatgeom.Point2 {
+	path := make([]floatgeom.Point2, 0, len(reversed))
+	for i := len(reversed) - 2; i >= 0; i-- {
+		idx := reversed[i]
+		path = append(path, g.CellCenter(intgeom.Point2{idx % g.w, idx / g.w}))
+	}
+	if len(path) == 0 {
+		return []floatgeom.Point2{to}
+	}
+	path[len(path)-1] = to
+	return path
+}
@@ -0,0 +1,158 @@
+package nav
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/alg/intgeom"
+	"github.com/oakmound/oak/v4/collision"
+)
+
+func pathLength(from floatgeom.Point2, path []floatgeom.Point2) float64 {
+	total := 0.0
+	for _, p := range path {
+		total += from.Distance(p)
+		from = p
+	}
+	return total
+}
+
+func TestGridRasterize(t *testing.T) {
+	tree := collision.NewTree()
+	tree.Add(
+		collision.NewLabeledSpace(16, 0, 16, 32, 1),
+		collision.NewLabeledSpace(64, 64, 4, 4, 2),
+	)
+	g, err := NewGrid(floatgeom.NewRect2(0, 0, 80, 80), floatgeom.Point2{16, 16},
+		WithTree(tree), BlockingLabels(1))
+	if err != nil {
+		t.Fatalf("unexpected error creating grid: %v", err)
+	}
+	if w, h := g.Size(); w != 5 || h != 5 {
+		t.Fatalf("expected 5x5 grid, got %dx%d", w, h)
+	}
+	for _, c := range []intgeom.Point2{{1, 0}, {1, 1}} {
+		if !g.Blocked(c) {
+			t.Fatalf("expected %v to be blocked", c)
+		}
+	}
+	for _, c := range []intgeom.Point2{{0, 0}, {2, 0}, {1, 2}, {4, 4}} {
+		if g.Blocked(c) {
+			t.Fatalf("expected %v to be open", c)
+		}
+	}
+	if !g.Blocked(intgeom.Point2{-1, 0}) {
+		t.Fatalf("expected out of bounds cell to be blocked")
+	}
+	g.Clearance = 1
+	g.Refresh()
+	if !g.Blocked(intgeom.Point2{0, 0}) || !g.Blocked(intgeom.Point2{1, 2}) {
+		t.Fatalf("expected clearance to block neighboring cells")
+	}
+	if _, err := NewGrid(floatgeom.NewRect2(0, 0, 80, 80), floatgeom.Point2{0, 16}); err == nil {
+		t.Fatalf("expected error on zero cell size")
+	}
+}
+
+func TestGridAStarWall(t *testing.T) {
+	tree := collision.NewTree()
+	// A wall with a gap at the bottom
+	tree.Add(collision.NewUnassignedSpace(4, 0, 1, 8))
+	g, _ := NewGrid(floatgeom.NewRect2(0, 0, 10, 10), floatgeom.Point2{1, 1}, WithTree(tree))
+	from, to := floatgeom.Point2{1.5, 1.5}, floatgeom.Point2{8.5, 1.5}
+	for _, diag := range []Diagonal{DiagonalNever, DiagonalNoObstacles, DiagonalOneObstacle, DiagonalAlways} {
+		g.Diagonal = diag
+		path, err := g.AStar(from, to)
+		if err != nil {
+			t.Fatalf("diagonal %v: unexpected error: %v", diag, err)
+		}
+		if path[len(path)-1] != to {
+			t.Fatalf("diagonal %v: path did not end at target", diag)
+		}
+		below := false
+		for _, p := range path {
+			if p.Y() > 8 {
+				below = true
+			}
+			if g.Blocked(g.Cell(p)) {
+				t.Fatalf("diagonal %v: path crossed blocked cell %v", diag, p)
+			}
+		}
+		if !below {
+			t.Fatalf("diagonal %v: path did not route through gap", diag)
+		}
+	}
+	tree.Add(collision.NewUnassignedSpace(4, 8, 1, 2))
+	g.Refresh()
+	if _, err := g.AStar(from, to); err != ErrNoPath {
+		t.Fatalf("expected no path through sealed wall, got %v", err)
+	}
+}
+
+func TestGridJumpPointMatchesAStar(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		g, _ := NewGrid(floatgeom.NewRect2(0, 0, 30, 30), floatgeom.Point2{1, 1}, WithTree(nil))
+		for j := 0; j < 250; j++ {
+			g.SetBlocked(intgeom.Point2{rng.Intn(30), rng.Intn(30)}, true)
+		}
+		from := floatgeom.Point2{0.5, 0.5}
+		to := floatgeom.Point2{29.5, 29.5}
+		g.SetBlocked(g.Cell(from), false)
+		g.SetBlocked(g.Cell(to), false)
+		for _, diag := range []Diagonal{DiagonalNever, DiagonalNoObstacles, DiagonalOneObstacle, DiagonalAlways} {
+			g.Diagonal = diag
+			aPath, aErr := g.AStar(from, to)
+			jPath, jErr := g.JumpPoint(from, to)
+			if aErr != jErr {
+				t.Fatalf("grid %d diagonal %v: errors differed: %v vs %v", i, diag, aErr, jErr)
+			}
+			if aErr != nil {
+				continue
+			}
+			aLen, jLen := pathLength(from, aPath), pathLength(from, jPath)
+			if math.Abs(aLen-jLen) > 1e-6 {
+				t.Fatalf("grid %d diagonal %v: path lengths differed: %v vs %v", i, diag, aLen, jLen)
+			}
+			if len(jPath) > len(aPath) {
+				t.Fatalf("grid %d diagonal %v: jump point path was not compressed", i, diag)
+			}
+		}
+	}
+}
+
+func TestGridDiagonalCorners(t *testing.T) {
+	g, _ := NewGrid(floatgeom.NewRect2(0, 0, 2, 2), floatgeom.Point2{1, 1}, WithTree(nil))
+	g.SetBlocked(intgeom.Point2{1, 0}, true)
+	from, to := floatgeom.Point2{0.5, 0.5}, floatgeom.Point2{1.5, 1.5}
+	type testCase struct {
+		diag   Diagonal
+		length int
+	}
+	for _, tc := range []testCase{
+		{DiagonalNever, 2},
+		{DiagonalNoObstacles, 2},
+		{DiagonalOneObstacle, 1},
+		{DiagonalAlways, 1},
+	} {
+		g.Diagonal = tc.diag
+		path, err := g.AStar(from, to)
+		if err != nil {
+			t.Fatalf("diagonal %v: unexpected error: %v", tc.diag, err)
+		}
+		if len(path) != tc.length {
+			t.Fatalf("diagonal %v: expected path of %d, got %v", tc.diag, tc.length, path)
+		}
+	}
+	g.SetBlocked(intgeom.Point2{0, 1}, true)
+	g.Diagonal = DiagonalOneObstacle
+	if _, err := g.AStar(from, to); err != ErrNoPath {
+		t.Fatalf("expected no path squeezing between two corners")
+	}
+	g.Diagonal = DiagonalAlways
+	if _, err := g.JumpPoint(from, to); err != nil {
+		t.Fatalf("expected path squeezing between corners: %v", err)
+	}
+}
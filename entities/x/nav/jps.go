@@ -0,0 +1,171 @@
+package nav
+
+import (
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+)
+
+// JumpPoint finds a path between two world points with jump point search. The
+// returned path only contains the cells where the path changes direction, joined
+// by straight or diagonal lines of open cells. The start cell may be blocked, but
+// the goal cell may not.
+//
+// See "Online Graph Pruning for Pathfinding on Grid Maps" by D. Harabor and
+// A. Grastien, AAAI 2011.
+func (g *Grid) JumpPoint(from, to floatgeom.Point2) ([]floatgeom.Point2, error) {
+	start, goal, err := g.endpoints(from, to)
+	if err != nil {
+		return nil, err
+	}
+	gx, gy := goal%g.w, goal/g.w
+	s := newSearchState(len(g.blocked))
+	s.relax(start, start, 0, g.heuristic(start%g.w, start/g.w, gx, gy))
+	for {
+		cur, ok := s.next()
+		if !ok {
+			return nil, ErrNoPath
+		}
+		if cur == goal {
+			return g.toPath(s.trace(goal), to), nil
+		}
+		x, y := cur%g.w, cur/g.w
+		for _, d := range g.prunedDirs(x, y, s.parent[cur]) {
+			jx, jy, ok := g.jump(x+d[0], y+d[1], d[0], d[1], gx, gy)
+			if !ok {
+				continue
+			}
+			cost := s.g[cur] + g.heuristic(x, y, jx, jy)
+			s.relax(cur, jy*g.w+jx, cost, g.heuristic(jx, jy, gx, gy))
+		}
+	}
+}
+
+func sign(i int) int {
+	switch {
+	case i > 0:
+		return 1
+	case i < 0:
+		return -1
+	}
+	return 0
+}
+
+// prunedDirs returns the directions worth searching from x,y given the jump point
+// it was reached from. Directions whose first step is invalid are pruned by jump.
+func (g *Grid) prunedDirs(x, y, parent int) [][2]int {
+	px, py := parent%g.w, parent/g.w
+	dx, dy := sign(x-px), sign(y-py)
+	if dx == 0 && dy == 0 {
+		if g.Diagonal == DiagonalNever {
+			return neighborDirs[:4]
+		}
+		return neighborDirs[:]
+	}
+	dirs := make([][2]int, 0, 5)
+	switch g.Diagonal {
+	case DiagonalNever:
+		if dx != 0 {
+			dirs = append(dirs, [2]int{dx, 0}, [2]int{0, 1}, [2]int{0, -1})
+		} else {
+			dirs = append(dirs, [2]int{0, dy}, [2]int{1, 0}, [2]int{-1, 0})
+		}
+	case DiagonalNoObstacles:
+		switch {
+		case dx != 0 && dy != 0:
+			dirs = append(dirs, [2]int{dx, 0}, [2]int{0, dy}, [2]int{dx, dy})
+		case dx != 0:
+			dirs = append(dirs, [2]int{dx, 0}, [2]int{dx, 1}, [2]int{dx, -1}, [2]int{0, 1}, [2]int{0, -1})
+		default:
+			dirs = append(dirs, [2]int{0, dy}, [2]int{1, dy}, [2]int{-1, dy}, [2]int{1, 0}, [2]int{-1, 0})
+		}
+	default:
+		switch {
+		case dx != 0 && dy != 0:
+			dirs = append(dirs, [2]int{dx, 0}, [2]int{0, dy}, [2]int{dx, dy})
+			if !g.walkable(x-dx, y) {
+				dirs = append(dirs, [2]int{-dx, dy})
+			}
+			if !g.walkable(x, y-dy) {
+				dirs = append(dirs, [2]int{dx, -dy})
+			}
+		case dx != 0:
+			dirs = append(dirs, [2]int{dx, 0})
+			if !g.walkable(x, y+1) {
+				dirs = append(dirs, [2]int{dx, 1})
+			}
+			if !g.walkable(x, y-1) {
+				dirs = append(dirs, [2]int{dx, -1})
+			}
+		default:
+			dirs = append(dirs, [2]int{0, dy})
+			if !g.walkable(x+1, y) {
+				dirs = append(dirs, [2]int{1, dy})
+			}
+			if !g.walkable(x-1, y) {
+				dirs = append(dirs, [2]int{-1, dy})
+			}
+		}
+	}
+	return dirs
+}
+
+// jump steps from x-dx,y-dy in the direction dx,dy until it finds the goal or a
+// cell with a forced neighbor, returning that cell. It fails if it runs into a
+// blocked cell first.
+func (g *Grid) jump(x, y, dx, dy, gx, gy int) (int, int, bool) {
+	w := g.walkable
+	for {
+		if !w(x, y) || !g.canStep(x-dx, y-dy, dx, dy) {
+			return 0, 0, false
+		}
+		if x == gx && y == gy {
+			return x, y, true
+		}
+		switch g.Diagonal {
+		case DiagonalNever, DiagonalNoObstacles:
+			if dx != 0 && dy == 0 {
+				if (w(x, y-1) && !w(x-dx, y-1)) || (w(x, y+1) && !w(x-dx, y+1)) {
+					return x, y, true
+				}
+			} else if dx == 0 && dy != 0 {
+				if (w(x-1, y) && !w(x-1, y-dy)) || (w(x+1, y) && !w(x+1, y-dy)) {
+					return x, y, true
+				}
+				// Without diagonals, turns off of vertical lines are only found by
+				// looking for horizontal jump points along the way.
+				if g.Diagonal == DiagonalNever {
+					if _, _, ok := g.jump(x+1, y, 1, 0, gx, gy); ok {
+						return x, y, true
+					}
+					if _, _, ok := g.jump(x-1, y, -1, 0, gx, gy); ok {
+						return x, y, true
+					}
+				}
+			}
+		default:
+			switch {
+			case dx != 0 && dy != 0:
+				if (w(x-dx, y+dy) && !w(x-dx, y)) || (w(x+dx, y-dy) && !w(x, y-dy)) {
+					return x, y, true
+				}
+			case dx != 0:
+				if (w(x+dx, y+1) && !w(x, y+1)) || (w(x+dx, y-1) && !w(x, y-1)) {
+					return x, y, true
+				}
+			default:
+				if (w(x+1, y+dy) && !w(x+1, y)) || (w(x-1, y+dy) && !w(x-1, y)) {
+					return x, y, true
+				}
+			}
+		}
+		if dx != 0 && dy != 0 {
+			if _, _, ok := g.jump(x+dx, y, dx, 0, gx, gy); ok {
+				return x, y, true
+			}
+			if _, _, ok := g.jump(x, y+dy, 0, dy, gx, gy); ok {
+				return x, y, true
+			}
+		}
+		x += dx
+		y += dy
+	}
+}
@@ -0,0 +1,171 @@
+package nav
+
+import (
+	"sync"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/entities"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/scene"
+)
+
+// Mover events, triggered on the moving entity.
+var (
+	// Arrived: when a Mover's entity reaches its goal. The payload is the goal.
+	Arrived = event.RegisterEvent[floatgeom.Point2]()
+	// NoPath: when a Mover cannot find a path to its goal, and stops moving. The
+	// payload is the goal.
+	NoPath = event.RegisterEvent[floatgeom.Point2]()
+)
+
+// A Mover walks an entity along paths from a Finder, re-planning its path when
+// the entity's next step is blocked.
+type Mover struct {
+	Entity *entities.Entity
+	Finder Finder
+	// Blocking are the labels of spaces the entity cannot move into. If empty,
+	// the entity never considers itself blocked.
+	Blocking []collision.Label
+	// Speed is how far the entity moves along its path each frame. It defaults to
+	// the x component of the entity's speed.
+	Speed float64
+	// ReplanFrames is the minimum number of frames between re-plans while blocked.
+	ReplanFrames int
+
+	ctx         *scene.Context
+	lock        sync.Mutex
+	goal        floatgeom.Point2
+	path        []floatgeom.Point2
+	binding     event.Binding
+	moving      bool
+	sinceReplan int
+}
+
+// NewMover creates a Mover for an entity. The entity will not move until MoveTo
+// is called.
+func NewMover(ctx *scene.Context, e *entities.Entity, f Finder, blocking ...collision.Label) *Mover {
+	return &Mover{
+		Entity:       e,
+		Finder:       f,
+		Blocking:     blocking,
+		Speed:        e.Speed.X(),
+		ReplanFrames: 15,
+		ctx:          ctx,
+	}
+}
+
+// MoveTo plans a path from the center of the mover's entity to goal and begins
+// walking it. If no path can be found, the entity does not move.
+func (m *Mover) MoveTo(goal floatgeom.Point2) error {
+	path, err := m.Finder.FindPath(m.Entity.Rect.Center(), goal)
+	if err != nil {
+		return err
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.goal = goal
+	m.path = path
+	m.sinceReplan = 0
+	if !m.moving {
+		m.moving = true
+		m.binding = event.Bind(m.ctx, event.Enter, m.Entity, m.enter)
+	}
+	return nil
+}
+
+// Stop stops the mover's entity where it is.
+func (m *Mover) Stop() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.stop()
+}
+
+func (m *Mover) stop() {
+	m.path = nil
+	m.Entity.Delta = floatgeom.Point2{}
+	if m.moving {
+		m.moving = false
+		m.binding.Unbind()
+	}
+}
+
+// Moving returns whether the mover's entity is walking a path.
+func (m *Mover) Moving() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.moving
+}
+
+// Path returns the remaining points the mover's entity will walk through.
+func (m *Mover) Path() []floatgeom.Point2 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	out := make([]floatgeom.Point2, len(m.path))
+	copy(out, m.path)
+	return out
+}
+
+func (m *Mover) enter(e *entities.Entity, _ event.EnterPayload) event.Response {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if !m.moving {
+		return 0
+	}
+	if len(m.path) == 0 {
+		m.stop()
+		event.TriggerForCallerOn(m.ctx, e.CID(), Arrived, m.goal)
+		return 0
+	}
+	m.sinceReplan++
+	next := m.path[0]
+	delta := next.Sub(e.Rect.Center())
+	reached := true
+	if dist := delta.Magnitude(); dist > m.Speed {
+		delta = delta.MulConst(m.Speed / dist)
+		reached = false
+	}
+	if m.blocked(e, delta) {
+		e.Delta = floatgeom.Point2{}
+		if m.sinceReplan >= m.ReplanFrames {
+			m.replan(e)
+		}
+		return 0
+	}
+	e.Delta = delta
+	e.ShiftDelta()
+	// The waypoint is only passed once the entity has actually stepped onto it
+	if reached {
+		m.path = m.path[1:]
+	}
+	return 0
+}
+
+// blocked returns whether shifting e by delta would move it into a blocking space.
+func (m *Mover) blocked(e *entities.Entity, delta floatgeom.Point2) bool {
+	if len(m.Blocking) == 0 || e.Tree == nil {
+		return false
+	}
+	next := e.Rect.Shift(delta)
+	test := collision.NewUnassignedSpace(next.Min.X(), next.Min.Y(), next.W(), next.H())
+	return len(e.Tree.Hit(test, collision.WithLabels(m.Blocking...), collision.WithoutCIDs(e.CID()))) != 0
+}
+
+func (m *Mover) replan(e *entities.Entity) {
+	m.sinceReplan = 0
+	if r, ok := m.Finder.(Refresher); ok {
+		// Rebuild the area around the entity, in case whatever blocked it is new
+		reach := floatgeom.Point2{e.W() + m.Speed, e.H() + m.Speed}
+		r.RefreshRect(floatgeom.Rect2{
+			Min: e.Rect.Min.Sub(reach),
+			Max: e.Rect.Max.Add(reach),
+		})
+	}
+	path, err := m.Finder.FindPath(e.Rect.Center(), m.goal)
+	if err != nil {
+		m.stop()
+		event.TriggerForCallerOn(m.ctx, e.CID(), NoPath, m.goal)
+		return
+	}
+	m.path = path
+}
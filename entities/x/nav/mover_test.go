@@ -0,0 +1,149 @@
+package nav
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/entities"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/scene"
+)
+
+// stubFinder returns its paths in order, one per call, repeating the last.
+type stubFinder struct {
+	paths [][]floatgeom.Point2
+	calls int
+}
+
+func (f *stubFinder) FindPath(from, to floatgeom.Point2) ([]floatgeom.Point2, error) {
+	p := f.paths[len(f.paths)-1]
+	if f.calls < len(f.paths) {
+		p = f.paths[f.calls]
+	}
+	f.calls++
+	return append([]floatgeom.Point2(nil), p...), nil
+}
+
+func testMover(ctx *scene.Context, f Finder, blocking ...collision.Label) (*Mover, *entities.Entity) {
+	e := entities.New(ctx,
+		entities.WithRect(floatgeom.NewRect2WH(0, 0, 2, 2)),
+		entities.WithColor(color.RGBA{255, 0, 0, 255}),
+		entities.WithDrawLayers(nil),
+		entities.WithSpeed(floatgeom.Point2{2, 2}),
+	)
+	return NewMover(ctx, e, f, blocking...), e
+}
+
+// step runs the mover's per-frame logic n times.
+func step(m *Mover, n int) {
+	for i := 0; i < n; i++ {
+		m.enter(m.Entity, event.EnterPayload{})
+	}
+}
+
+func TestMoverFollowsPath(t *testing.T) {
+	cm := event.NewCallerMap()
+	ctx := &scene.Context{CallerMap: cm, Handler: event.NewBus(cm), CollisionTree: collision.NewTree()}
+	goal := floatgeom.Point2{5, 5}
+	m, e := testMover(ctx, &stubFinder{paths: [][]floatgeom.Point2{{{5, 1}, goal}}})
+
+	arrived := make(chan floatgeom.Point2, 1)
+	b := event.Bind(ctx, Arrived, e, func(_ *entities.Entity, p floatgeom.Point2) event.Response {
+		arrived <- p
+		return 0
+	})
+	<-b.Bound
+
+	if err := m.MoveTo(goal); err != nil {
+		t.Fatalf("unexpected error moving: %v", err)
+	}
+	step(m, 2)
+	if c := e.Rect.Center(); c != (floatgeom.Point2{5, 1}) {
+		t.Fatalf("expected to reach the first waypoint, got %v", c)
+	}
+	if len(m.Path()) != 1 {
+		t.Fatalf("expected one waypoint left, got %v", m.Path())
+	}
+	step(m, 2)
+	if c := e.Rect.Center(); c != goal {
+		t.Fatalf("expected to reach the goal, got %v", c)
+	}
+	if !m.Moving() {
+		t.Fatalf("mover should not stop until the frame after arriving")
+	}
+	step(m, 1)
+	if m.Moving() {
+		t.Fatalf("mover should stop after arriving")
+	}
+	select {
+	case p := <-arrived:
+		if p != goal {
+			t.Fatalf("expected arrival at %v, got %v", goal, p)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected arrived to trigger")
+	}
+}
+
+func TestMoverBlockedKeepsWaypoint(t *testing.T) {
+	cm := event.NewCallerMap()
+	ctx := &scene.Context{CallerMap: cm, Handler: event.NewBus(cm), CollisionTree: collision.NewTree()}
+	const wall collision.Label = 1
+	goal := floatgeom.Point2{3, 1}
+	f := &stubFinder{paths: [][]floatgeom.Point2{{goal}}}
+	m, e := testMover(ctx, f, wall)
+	m.ReplanFrames = 100
+
+	blocker := collision.NewLabeledSpace(3.5, 0, 2, 2, wall)
+	ctx.CollisionTree.Add(blocker)
+	if err := m.MoveTo(goal); err != nil {
+		t.Fatalf("unexpected error moving: %v", err)
+	}
+	step(m, 3)
+	if c := e.Rect.Center(); c != (floatgeom.Point2{1, 1}) {
+		t.Fatalf("blocked entity should not move, got %v", c)
+	}
+	if len(m.Path()) != 1 {
+		t.Fatalf("blocked entity should keep its final waypoint, got %v", m.Path())
+	}
+
+	ctx.CollisionTree.Remove(blocker)
+	step(m, 1)
+	if c := e.Rect.Center(); c != goal {
+		t.Fatalf("unblocked entity should reach its waypoint, got %v", c)
+	}
+}
+
+func TestMoverReplansWhenBlocked(t *testing.T) {
+	cm := event.NewCallerMap()
+	ctx := &scene.Context{CallerMap: cm, Handler: event.NewBus(cm), CollisionTree: collision.NewTree()}
+	const wall collision.Label = 1
+	goal := floatgeom.Point2{9, 1}
+	detour := []floatgeom.Point2{{1, 5}, {9, 5}, goal}
+	f := &stubFinder{paths: [][]floatgeom.Point2{{goal}, detour}}
+	m, e := testMover(ctx, f, wall)
+	m.ReplanFrames = 3
+
+	ctx.CollisionTree.Add(collision.NewLabeledSpace(3, 0, 2, 3, wall))
+	if err := m.MoveTo(goal); err != nil {
+		t.Fatalf("unexpected error moving: %v", err)
+	}
+	step(m, 2)
+	if f.calls != 1 {
+		t.Fatalf("should not replan before ReplanFrames, got %d plans", f.calls)
+	}
+	step(m, 1)
+	if f.calls != 2 {
+		t.Fatalf("should replan once blocked for ReplanFrames, got %d plans", f.calls)
+	}
+	if got := m.Path(); len(got) != len(detour) {
+		t.Fatalf("expected the detour path, got %v", got)
+	}
+	step(m, 20)
+	if c := e.Rect.Center(); c != goal {
+		t.Fatalf("expected to reach the goal around the wall, got %v", c)
+	}
+}
@@ -0,0 +1,287 @@
+package nav
+
+import (
+	"math"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/alg/intgeom"
+)
+
+const navEpsilon = 1e-6
+
+// A NavMesh is a set of convex polygons which can be walked within. Polygons are
+// connected wherever their edges overlap.
+type NavMesh struct {
+	Polygons []floatgeom.Polygon2
+
+	portals [][]portal
+}
+
+// A portal is the shared edge between two polygons of a NavMesh.
+type portal struct {
+	to   int
+	a, b floatgeom.Point2
+}
+
+func (p portal) midpoint() floatgeom.Point2 {
+	return p.a.Add(p.b).DivConst(2)
+}
+
+// NewNavMesh creates a navigation mesh from a set of convex polygons.
+func NewNavMesh(polys ...floatgeom.Polygon2) *NavMesh {
+	nm := &NavMesh{
+		Polygons: polys,
+		portals:  make([][]portal, len(polys)),
+	}
+	for i := 0; i < len(polys); i++ {
+		for j := i + 1; j < len(polys); j++ {
+			if !touching(polys[i].Bounding, polys[j].Bounding) {
+				continue
+			}
+			if a, b, ok := sharedEdge(polys[i], polys[j]); ok {
+				nm.portals[i] = append(nm.portals[i], portal{to: j, a: a, b: b})
+				nm.portals[j] = append(nm.portals[j], portal{to: i, a: a, b: b})
+			}
+		}
+	}
+	return nm
+}
+
+// NavMesh builds a navigation mesh from the open cells of this grid, greedily
+// merging them into as few rectangles as it can.
+func (g *Grid) NavMesh() *NavMesh {
+	used := make([]bool, len(g.blocked))
+	open := func(x, y int) bool {
+		return g.walkable(x, y) && !used[y*g.w+x]
+	}
+	polys := []floatgeom.Polygon2{}
+	for y := 0; y < g.h; y++ {
+		for x := 0; x < g.w; x++ {
+			if !open(x, y) {
+				continue
+			}
+			x2 := x + 1
+			for open(x2, y) {
+				x2++
+			}
+			y2 := y + 1
+		grow:
+			for ; y2 < g.h; y2++ {
+				for cx := x; cx < x2; cx++ {
+					if !open(cx, y2) {
+						break grow
+					}
+				}
+			}
+			for cy := y; cy < y2; cy++ {
+				for cx := x; cx < x2; cx++ {
+					used[cy*g.w+cx] = true
+				}
+			}
+			min := g.cellRect(intgeom.Point2{x, y}).Min
+			max := g.cellRect(intgeom.Point2{x2 - 1, y2 - 1}).Max
+			polys = append(polys, floatgeom.NewPolygon2(
+				min,
+				floatgeom.Point2{max.X(), min.Y()},
+				max,
+				floatgeom.Point2{min.X(), max.Y()},
+			))
+		}
+	}
+	return NewNavMesh(polys...)
+}
+
+func touching(r1, r2 floatgeom.Rect2) bool {
+	return r1.Min.X() <= r2.Max.X()+navEpsilon && r2.Min.X() <= r1.Max.X()+navEpsilon &&
+		r1.Min.Y() <= r2.Max.Y()+navEpsilon && r2.Min.Y() <= r1.Max.Y()+navEpsilon
+}
+
+func cross(a, b floatgeom.Point2) float64 {
+	return a.X()*b.Y() - a.Y()*b.X()
+}
+
+// sharedEdge returns the segment along which an edge of p1 and an edge of p2 overlap.
+func sharedEdge(p1, p2 floatgeom.Polygon2) (a, b floatgeom.Point2, ok bool) {
+	for i := range p1.Points {
+		a0, a1 := p1.Points[i], p1.Points[(i+1)%len(p1.Points)]
+		d := a1.Sub(a0)
+		lenSq := d.Dot(d)
+		if lenSq == 0 {
+			continue
+		}
+		eps := navEpsilon * math.Sqrt(lenSq)
+		for j := range p2.Points {
+			b0, b1 := p2.Points[j], p2.Points[(j+1)%len(p2.Points)]
+			if math.Abs(cross(d, b0.Sub(a0))) > eps || math.Abs(cross(d, b1.Sub(a0))) > eps {
+				continue
+			}
+			t0 := b0.Sub(a0).Dot(d) / lenSq
+			t1 := b1.Sub(a0).Dot(d) / lenSq
+			lo := math.Max(0, math.Min(t0, t1))
+			hi := math.Min(1, math.Max(t0, t1))
+			if (hi-lo)*math.Sqrt(lenSq) <= navEpsilon {
+				continue
+			}
+			return a0.Add(d.MulConst(lo)), a0.Add(d.MulConst(hi)), true
+		}
+	}
+	return a, b, false
+}
+
+// Locate returns the index of the polygon containing p. If no polygon contains p,
+// the nearest polygon is returned. It returns false if the mesh is empty.
+func (nm *NavMesh) Locate(p floatgeom.Point2) (int, bool) {
+	best, bestDist := -1, math.Inf(1)
+	for i, poly := range nm.Polygons {
+		d := polygonDistance(poly, p)
+		if d < bestDist {
+			best, bestDist = i, d
+		}
+		if d == 0 {
+			break
+		}
+	}
+	return best, best != -1
+}
+
+// polygonDistance returns the distance from p to a convex polygon, or 0 if the
+// polygon contains p.
+func polygonDistance(poly floatgeom.Polygon2, p floatgeom.Point2) float64 {
+	inside := true
+	side := 0.0
+	min := math.Inf(1)
+	for i := range poly.Points {
+		a, b := poly.Points[i], poly.Points[(i+1)%len(poly.Points)]
+		c := cross(b.Sub(a), p.Sub(a))
+		if c != 0 {
+			if side != 0 && (c > 0) != (side > 0) {
+				inside = false
+			}
+			side = c
+		}
+		min = math.Min(min, segmentDistance(a, b, p))
+	}
+	if inside {
+		return 0
+	}
+	return min
+}
+
+func segmentDistance(a, b, p floatgeom.Point2) float64 {
+	d := b.Sub(a)
+	lenSq := d.Dot(d)
+	if lenSq == 0 {
+		return p.Distance(a)
+	}
+	t := math.Max(0, math.Min(1, p.Sub(a).Dot(d)/lenSq))
+	return p.Distance(a.Add(d.MulConst(t)))
+}
+
+// FindPath finds a path between two points through the mesh. Polygons are searched
+// with A* through the midpoints of their shared edges, then the resulting corridor
+// is tightened with a funnel so the path only bends around corners.
+func (nm *NavMesh) FindPath(from, to floatgeom.Point2) ([]floatgeom.Point2, error) {
+	start, ok := nm.Locate(from)
+	if !ok {
+		return nil, ErrNoPath
+	}
+	goal, _ := nm.Locate(to)
+	s := newSearchState(len(nm.Polygons))
+	// entries are the points each polygon was entered through on its best path
+	entries := make([]floatgeom.Point2, len(nm.Polygons))
+	entries[start] = from
+	s.relax(start, start, 0, from.Distance(to))
+	for {
+		cur, ok := s.next()
+		if !ok {
+			return nil, ErrNoPath
+		}
+		if cur == goal {
+			break
+		}
+		for _, p := range nm.portals[cur] {
+			m := p.midpoint()
+			if s.relax(cur, p.to, s.g[cur]+entries[cur].Distance(m), m.Distance(to)) {
+				entries[p.to] = m
+			}
+		}
+	}
+	corridor := s.trace(goal)
+	// the funnel walks a list of portals, starting and ending with degenerate
+	// portals at the path's endpoints
+	lefts := []floatgeom.Point2{from}
+	rights := []floatgeom.Point2{from}
+	for i := len(corridor) - 1; i > 0; i-- {
+		a, b := corridor[i], corridor[i-1]
+		for _, p := range nm.portals[a] {
+			if p.to != b {
+				continue
+			}
+			dir := nm.Polygons[b].Bounding.Center().Sub(nm.Polygons[a].Bounding.Center())
+			l, r := p.a, p.b
+			if cross(dir, p.a.Sub(p.midpoint())) < 0 {
+				l, r = r, l
+			}
+			lefts = append(lefts, l)
+			rights = append(rights, r)
+			break
+		}
+	}
+	lefts = append(lefts, to)
+	rights = append(rights, to)
+	return stringPull(lefts, rights), nil
+}
+
+func triarea2(a, b, c floatgeom.Point2) float64 {
+	return cross(c.Sub(a), b.Sub(a))
+}
+
+func pointsEqual(a, b floatgeom.Point2) bool {
+	return a.Distance(b) < navEpsilon
+}
+
+// stringPull tightens a corridor of portals into the shortest path through them.
+// The first and last portals must be the start and end points of the path. The
+// returned path does not include its start point.
+//
+// Implements the "simple stupid funnel algorithm" by M. Mononen.
+func stringPull(lefts, rights []floatgeom.Point2) []floatgeom.Point2 {
+	path := []floatgeom.Point2{}
+	apex, left, right := lefts[0], lefts[0], rights[0]
+	apexIndex, leftIndex, rightIndex := 0, 0, 0
+	for i := 1; i < len(lefts); i++ {
+		l, r := lefts[i], rights[i]
+		// tighten the right side of the funnel
+		if triarea2(apex, right, r) <= 0 {
+			if pointsEqual(apex, right) || triarea2(apex, left, r) > 0 {
+				right, rightIndex = r, i
+			} else {
+				// the right side crossed over the left, so the left is a corner
+				path = append(path, left)
+				apex, apexIndex = left, leftIndex
+				left, right = apex, apex
+				leftIndex, rightIndex = apexIndex, apexIndex
+				i = apexIndex
+				continue
+			}
+		}
+		// tighten the left side of the funnel
+		if triarea2(apex, left, l) >= 0 {
+			if pointsEqual(apex, left) || triarea2(apex, right, l) < 0 {
+				left, leftIndex = l, i
+			} else {
+				path = append(path, right)
+				apex, apexIndex = right, rightIndex
+				left, right = apex, apex
+				leftIndex, rightIndex = apexIndex, apexIndex
+				i = apexIndex
+				continue
+			}
+		}
+	}
+	end := lefts[len(lefts)-1]
+	if len(path) == 0 || !pointsEqual(path[len(path)-1], end) {
+		path = append(path, end)
+	}
+	return path
+}
@@ -0,0 +1,78 @@
+package nav
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+)
+
+func TestNavMeshCorner(t *testing.T) {
+	// An L shaped corridor:
+	// +--+
+	// |  |
+	// |  +--+
+	// |     |
+	// +-----+
+	nm := NewNavMesh(
+		floatgeom.NewPolygon2(
+			floatgeom.Point2{0, 0}, floatgeom.Point2{10, 0},
+			floatgeom.Point2{10, 10}, floatgeom.Point2{0, 10},
+		),
+		floatgeom.NewPolygon2(
+			floatgeom.Point2{0, 10}, floatgeom.Point2{10, 10},
+			floatgeom.Point2{10, 20}, floatgeom.Point2{0, 20},
+		),
+		floatgeom.NewPolygon2(
+			floatgeom.Point2{10, 10}, floatgeom.Point2{20, 10},
+			floatgeom.Point2{20, 20}, floatgeom.Point2{10, 20},
+		),
+	)
+	from, to := floatgeom.Point2{5, 1}, floatgeom.Point2{19, 15}
+	path, err := nm.FindPath(from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(path) != 2 {
+		t.Fatalf("expected path to bend once, got %v", path)
+	}
+	if path[0] != (floatgeom.Point2{10, 10}) {
+		t.Fatalf("expected path to bend at corner, got %v", path)
+	}
+	if path[1] != to {
+		t.Fatalf("expected path to end at target, got %v", path)
+	}
+	// With no corner in the way, the path is straight
+	path, err = nm.FindPath(floatgeom.Point2{5, 5}, floatgeom.Point2{15, 15})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(path) != 1 {
+		t.Fatalf("expected straight path, got %v", path)
+	}
+}
+
+func TestGridNavMesh(t *testing.T) {
+	tree := collision.NewTree()
+	tree.Add(collision.NewUnassignedSpace(4, 0, 2, 8))
+	g, _ := NewGrid(floatgeom.NewRect2(0, 0, 10, 10), floatgeom.Point2{1, 1}, WithTree(tree))
+	nm := g.NavMesh()
+	area := 0.0
+	for _, p := range nm.Polygons {
+		area += p.Bounding.Area()
+	}
+	if area != 84 {
+		t.Fatalf("expected navmesh to cover open area of 84, got %v", area)
+	}
+	from, to := floatgeom.Point2{1, 1}, floatgeom.Point2{9, 1}
+	path, err := nm.FindPath(from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The shortest path wraps tightly around the bottom of the wall
+	expected := from.Distance(floatgeom.Point2{4, 8}) + 2 + floatgeom.Point2{6, 8}.Distance(to)
+	if l := pathLength(from, path); math.Abs(l-expected) > 1e-6 {
+		t.Fatalf("expected path of length %v, got %v: %v", expected, l, path)
+	}
+}
@@ -0,0 +1,123 @@
+package steering
+
+import (
+	"math"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/entities"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/scene"
+)
+
+// A Behavior produces a steering force for an agent each frame.
+type Behavior interface {
+	Steer(a *Agent) floatgeom.Point2
+}
+
+// A BehaviorFunc converts a function into a Behavior.
+type BehaviorFunc func(a *Agent) floatgeom.Point2
+
+// Steer calls bf(a).
+func (bf BehaviorFunc) Steer(a *Agent) floatgeom.Point2 {
+	return bf(a)
+}
+
+type weighted struct {
+	Behavior
+	weight float64
+}
+
+// An Agent is an entity moved by a weighted combination of steering behaviors.
+// The entity's Delta is its velocity, and its Speed is its maximum velocity on
+// each axis.
+type Agent struct {
+	*entities.Entity
+	// MaxForce limits how much the agent's velocity can change each frame. If
+	// zero, the combined steering force is not limited.
+	MaxForce float64
+
+	behaviors []weighted
+}
+
+// NewAgent creates an agent for an entity with the given maximum steering force.
+func NewAgent(e *entities.Entity, maxForce float64) *Agent {
+	return &Agent{
+		Entity:   e,
+		MaxForce: maxForce,
+	}
+}
+
+// Add adds a behavior to this agent. Each frame, the forces from all of an agent's
+// behaviors are multiplied by their weights and summed.
+func (a *Agent) Add(b Behavior, weight float64) {
+	a.behaviors = append(a.behaviors, weighted{b, weight})
+}
+
+// Clear removes all behaviors from this agent.
+func (a *Agent) Clear() {
+	a.behaviors = nil
+}
+
+// Position returns the center of the agent.
+func (a *Agent) Position() floatgeom.Point2 {
+	return a.Rect.Center()
+}
+
+// MaxSpeed returns the greatest speed the agent can reach, along its fastest axis.
+func (a *Agent) MaxSpeed() float64 {
+	return math.Max(a.Speed.X(), a.Speed.Y())
+}
+
+// Steering returns the combined force of this agent's behaviors, limited by MaxForce.
+func (a *Agent) Steering() floatgeom.Point2 {
+	force := floatgeom.Point2{}
+	for _, b := range a.behaviors {
+		force = force.Add(b.Steer(a).MulConst(b.weight))
+	}
+	return truncate(force, a.MaxForce)
+}
+
+// Apply adds a force to the agent's velocity, limits the velocity to the agent's
+// speed, and moves the agent by it.
+func (a *Agent) Apply(force floatgeom.Point2) {
+	a.Delta = clampSpeed(a.Delta.Add(force), a.Speed)
+	a.ShiftDelta()
+}
+
+// Update steers and moves this agent for one frame.
+func (a *Agent) Update() {
+	a.Apply(a.Steering())
+}
+
+// Bind causes this agent to Update every frame. Agents belonging to a Flock should
+// be bound through the Flock instead.
+func (a *Agent) Bind(ctx *scene.Context) event.Binding {
+	return event.Bind(ctx, event.Enter, a.Entity, func(_ *entities.Entity, _ event.EnterPayload) event.Response {
+		a.Update()
+		return 0
+	})
+}
+
+// truncate limits the magnitude of v to max, if max is positive.
+func truncate(v floatgeom.Point2, max float64) floatgeom.Point2 {
+	if max <= 0 {
+		return v
+	}
+	if mag := v.Magnitude(); mag > max {
+		return v.MulConst(max / mag)
+	}
+	return v
+}
+
+// clampSpeed limits v to the ellipse with radii of speed's components, so an agent
+// never moves faster on an axis than its speed on that axis.
+func clampSpeed(v, speed floatgeom.Point2) floatgeom.Point2 {
+	if speed.X() <= 0 || speed.Y() <= 0 {
+		return floatgeom.Point2{}
+	}
+	scale := math.Hypot(v.X()/speed.X(), v.Y()/speed.Y())
+	if scale > 1 {
+		return v.DivConst(scale)
+	}
+	return v
+}
@@ -0,0 +1,80 @@
+package steering
+
+import (
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/collision/ray"
+)
+
+var _ Behavior = &AvoidObstacles{}
+
+// AvoidObstacles steers an agent away from spaces in front of it. It casts a ray
+// from the agent's center and from each of its sides along its velocity, and
+// steers away from the closest space hit.
+type AvoidObstacles struct {
+	// Caster casts the feeler rays. Its filters choose which spaces are obstacles,
+	// and its tree is where they are searched for. The agent's own spaces are
+	// never obstacles.
+	Caster *ray.Caster
+	// LookAhead is how many frames of movement ahead of the agent are checked.
+	LookAhead float64
+	// MinDistance is the least distance checked ahead of the agent, so slow
+	// agents still notice obstacles.
+	MinDistance float64
+}
+
+// NewAvoidObstacles creates an AvoidObstacles behavior looking the given frames
+// ahead. The caster is built from ray.NewCaster with the given options.
+func NewAvoidObstacles(lookAhead float64, opts ...ray.CastOption) *AvoidObstacles {
+	return &AvoidObstacles{
+		Caster:    ray.NewCaster(opts...),
+		LookAhead: lookAhead,
+	}
+}
+
+// Steer satisfies Behavior.
+func (ao *AvoidObstacles) Steer(a *Agent) floatgeom.Point2 {
+	speed := a.Delta.Magnitude()
+	if speed == 0 {
+		return floatgeom.Point2{}
+	}
+	heading := a.Delta.DivConst(speed)
+	dist := speed * ao.LookAhead
+	if dist < ao.MinDistance {
+		dist = ao.MinDistance
+	}
+	if dist <= 0 {
+		return floatgeom.Point2{}
+	}
+	caster := ao.Caster.Copy()
+	caster.CastDistance = dist
+
+	side := floatgeom.Point2{-heading.Y(), heading.X()}.MulConst((a.W() + a.H()) / 4)
+	center := a.Position()
+	var closest collision.Point
+	closestDist := dist
+	for _, origin := range []floatgeom.Point2{center, center.Add(side), center.Sub(side)} {
+		for _, pt := range caster.Cast(origin, heading) {
+			if pt.Zone.CID == a.CID() {
+				continue
+			}
+			if d := origin.Distance(pt.ProjectZ()); d < closestDist {
+				closest, closestDist = pt, d
+			}
+			break
+		}
+	}
+	if closest.IsNil() {
+		return floatgeom.Point2{}
+	}
+	// Push sideways away from the obstacle's center, harder the closer it is
+	away := center.Sub(closest.Zone.Location.ProjectZ().Center())
+	lateral := away.Sub(heading.MulConst(away.Dot(heading)))
+	if lateral == (floatgeom.Point2{}) {
+		lateral = side
+	}
+	urgency := 1 - closestDist/dist
+	force := lateral.Normalize().MulConst(a.MaxSpeed() * urgency)
+	// and brake in proportion
+	return force.Sub(heading.MulConst(speed * urgency / 2))
+}
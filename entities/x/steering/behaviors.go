@@ -0,0 +1,173 @@
+package steering
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/entities"
+)
+
+var (
+	_ Behavior = &Seek{}
+	_ Behavior = &Flee{}
+	_ Behavior = &Arrive{}
+	_ Behavior = &Pursue{}
+	_ Behavior = &Evade{}
+	_ Behavior = &Wander{}
+)
+
+// seek returns the force steering a toward p at full speed.
+func seek(a *Agent, p floatgeom.Point2) floatgeom.Point2 {
+	desired := p.Sub(a.Position()).Normalize().MulConst(a.MaxSpeed())
+	return desired.Sub(a.Delta)
+}
+
+// flee returns the force steering a away from p at full speed.
+func flee(a *Agent, p floatgeom.Point2) floatgeom.Point2 {
+	desired := a.Position().Sub(p).Normalize().MulConst(a.MaxSpeed())
+	return desired.Sub(a.Delta)
+}
+
+// predict returns where target will be once a could reach its current position,
+// looking no further than maxFrames ahead if maxFrames is positive.
+func predict(a *Agent, target *entities.Entity, maxFrames float64) floatgeom.Point2 {
+	pos := target.Rect.Center()
+	frames := 0.0
+	if speed := a.MaxSpeed(); speed > 0 {
+		frames = pos.Distance(a.Position()) / speed
+	}
+	if maxFrames > 0 && frames > maxFrames {
+		frames = maxFrames
+	}
+	return pos.Add(target.Delta.MulConst(frames))
+}
+
+// Seek steers an agent directly toward a target point.
+type Seek struct {
+	Target floatgeom.Point2
+}
+
+// Steer satisfies Behavior.
+func (s *Seek) Steer(a *Agent) floatgeom.Point2 {
+	return seek(a, s.Target)
+}
+
+// Flee steers an agent directly away from a target point.
+type Flee struct {
+	Target floatgeom.Point2
+	// PanicDistance is how close the target must be before the agent flees. If
+	// zero, the agent always flees.
+	PanicDistance float64
+}
+
+// Steer satisfies Behavior.
+func (f *Flee) Steer(a *Agent) floatgeom.Point2 {
+	if f.PanicDistance > 0 && a.Position().Distance(f.Target) > f.PanicDistance {
+		return floatgeom.Point2{}
+	}
+	return flee(a, f.Target)
+}
+
+// Arrive steers an agent toward a target point, slowing down as it approaches so
+// it comes to a stop on the target.
+type Arrive struct {
+	Target floatgeom.Point2
+	// SlowDistance is how close to the target the agent begins slowing down.
+	SlowDistance float64
+}
+
+// Steer satisfies Behavior.
+func (ar *Arrive) Steer(a *Agent) floatgeom.Point2 {
+	toTarget := ar.Target.Sub(a.Position())
+	dist := toTarget.Magnitude()
+	if dist == 0 {
+		return a.Delta.MulConst(-1)
+	}
+	speed := a.MaxSpeed()
+	if dist < ar.SlowDistance {
+		speed *= dist / ar.SlowDistance
+	}
+	// Never plan to overshoot the target in a single frame
+	speed = math.Min(speed, dist)
+	return toTarget.MulConst(speed / dist).Sub(a.Delta)
+}
+
+// Pursue steers an agent toward where a moving target entity will be, based on the
+// target's Delta.
+type Pursue struct {
+	Target *entities.Entity
+	// MaxPrediction limits how many frames ahead the target's position is predicted.
+	// If zero, prediction is not limited.
+	MaxPrediction float64
+}
+
+// Steer satisfies Behavior.
+func (p *Pursue) Steer(a *Agent) floatgeom.Point2 {
+	return seek(a, predict(a, p.Target, p.MaxPrediction))
+}
+
+// Evade steers an agent away from where a moving target entity will be, based on
+// the target's Delta.
+type Evade struct {
+	Target *entities.Entity
+	// MaxPrediction limits how many frames ahead the target's position is predicted.
+	// If zero, prediction is not limited.
+	MaxPrediction float64
+	// PanicDistance is how close the target must be before the agent evades. If
+	// zero, the agent always evades.
+	PanicDistance float64
+}
+
+// Steer satisfies Behavior.
+func (e *Evade) Steer(a *Agent) floatgeom.Point2 {
+	if e.PanicDistance > 0 && a.Position().Distance(e.Target.Rect.Center()) > e.PanicDistance {
+		return floatgeom.Point2{}
+	}
+	return flee(a, predict(a, e.Target, e.MaxPrediction))
+}
+
+// Wander steers an agent randomly, but smoothly, by seeking a point which drifts
+// around a circle projected in front of the agent.
+type Wander struct {
+	// Distance is how far in front of the agent the wander circle is projected.
+	Distance float64
+	// Radius is the radius of the wander circle.
+	Radius float64
+	// Jitter is the most the wander point can move around the circle each frame,
+	// in radians.
+	Jitter float64
+	// Rand is the source of randomness for this behavior. If nil, the global
+	// source from math/rand is used.
+	Rand *rand.Rand
+
+	angle float64
+}
+
+// NewWander creates a Wander behavior.
+func NewWander(distance, radius, jitter float64) *Wander {
+	return &Wander{
+		Distance: distance,
+		Radius:   radius,
+		Jitter:   jitter,
+	}
+}
+
+func (w *Wander) float64() float64 {
+	if w.Rand != nil {
+		return w.Rand.Float64()
+	}
+	return rand.Float64()
+}
+
+// Steer satisfies Behavior.
+func (w *Wander) Steer(a *Agent) floatgeom.Point2 {
+	w.angle += (w.float64()*2 - 1) * w.Jitter
+	heading := a.Delta.Normalize()
+	if heading == (floatgeom.Point2{}) {
+		heading = floatgeom.Point2{1, 0}
+	}
+	center := a.Position().Add(heading.MulConst(w.Distance))
+	target := center.Add(floatgeom.RadianPoint(w.angle + heading.ToRadians()).MulConst(w.Radius))
+	return seek(a, target)
+}
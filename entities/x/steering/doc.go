@@ -0,0 +1,3 @@
+// Package steering provides steering behaviors which move entities by adjusting
+// their Delta, such as seeking, fleeing, wandering and flocking.
+package steering
@@ -0,0 +1,160 @@
+package steering
+
+import (
+	"sync"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/scene"
+)
+
+var (
+	_ Behavior = &Separation{}
+	_ Behavior = &Alignment{}
+	_ Behavior = &Cohesion{}
+)
+
+// A Flock is a group of agents which can react to one another. Flocking agents
+// should be updated through their flock, which steers every agent before moving
+// any of them, so each agent sees the same state of its neighbors.
+type Flock struct {
+	// Radius is how close another agent must be to be considered a neighbor.
+	Radius float64
+
+	lock   sync.RWMutex
+	agents []*Agent
+}
+
+// NewFlock creates an empty flock whose agents consider others within radius
+// their neighbors.
+func NewFlock(radius float64) *Flock {
+	return &Flock{
+		Radius: radius,
+	}
+}
+
+// Add adds agents to this flock.
+func (f *Flock) Add(as ...*Agent) {
+	f.lock.Lock()
+	f.agents = append(f.agents, as...)
+	f.lock.Unlock()
+}
+
+// Remove removes an agent from this flock.
+func (f *Flock) Remove(a *Agent) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for i, a2 := range f.agents {
+		if a2 == a {
+			f.agents = append(f.agents[:i], f.agents[i+1:]...)
+			return
+		}
+	}
+}
+
+// Agents returns the agents in this flock.
+func (f *Flock) Agents() []*Agent {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	out := make([]*Agent, len(f.agents))
+	copy(out, f.agents)
+	return out
+}
+
+// Neighbors returns the agents in this flock within Radius of a, excluding a.
+func (f *Flock) Neighbors(a *Agent) []*Agent {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	pos := a.Position()
+	out := []*Agent{}
+	for _, a2 := range f.agents {
+		if a2 != a && a2.Position().Distance(pos) <= f.Radius {
+			out = append(out, a2)
+		}
+	}
+	return out
+}
+
+// Update steers every agent in this flock, then moves them all.
+func (f *Flock) Update() {
+	agents := f.Agents()
+	forces := make([]floatgeom.Point2, len(agents))
+	for i, a := range agents {
+		forces[i] = a.Steering()
+	}
+	for i, a := range agents {
+		a.Apply(forces[i])
+	}
+}
+
+// Bind causes this flock to Update every frame.
+func (f *Flock) Bind(ctx *scene.Context) event.Binding {
+	return event.GlobalBind(ctx, event.Enter, func(event.EnterPayload) event.Response {
+		f.Update()
+		return 0
+	})
+}
+
+// Separation steers an agent away from its crowding neighbors, more strongly the
+// closer they are.
+type Separation struct {
+	Flock *Flock
+}
+
+// Steer satisfies Behavior.
+func (s *Separation) Steer(a *Agent) floatgeom.Point2 {
+	pos := a.Position()
+	push := floatgeom.Point2{}
+	for _, n := range s.Flock.Neighbors(a) {
+		away := pos.Sub(n.Position())
+		dist := away.Magnitude()
+		if dist == 0 {
+			continue
+		}
+		// Scale each push inversely by distance
+		push = push.Add(away.DivConst(dist * dist))
+	}
+	if push == (floatgeom.Point2{}) {
+		return push
+	}
+	return push.Normalize().MulConst(a.MaxSpeed()).Sub(a.Delta)
+}
+
+// Alignment steers an agent to move in the same direction as its neighbors.
+type Alignment struct {
+	Flock *Flock
+}
+
+// Steer satisfies Behavior.
+func (al *Alignment) Steer(a *Agent) floatgeom.Point2 {
+	neighbors := al.Flock.Neighbors(a)
+	if len(neighbors) == 0 {
+		return floatgeom.Point2{}
+	}
+	heading := floatgeom.Point2{}
+	for _, n := range neighbors {
+		heading = heading.Add(n.Delta)
+	}
+	if heading == (floatgeom.Point2{}) {
+		return heading
+	}
+	return heading.Normalize().MulConst(a.MaxSpeed()).Sub(a.Delta)
+}
+
+// Cohesion steers an agent toward the center of its neighbors.
+type Cohesion struct {
+	Flock *Flock
+}
+
+// Steer satisfies Behavior.
+func (c *Cohesion) Steer(a *Agent) floatgeom.Point2 {
+	neighbors := c.Flock.Neighbors(a)
+	if len(neighbors) == 0 {
+		return floatgeom.Point2{}
+	}
+	center := floatgeom.Point2{}
+	for _, n := range neighbors {
+		center = center.Add(n.Position())
+	}
+	return seek(a, center.DivConst(float64(len(neighbors))))
+}
@@ -0,0 +1,149 @@
+package steering
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/collision/ray"
+	"github.com/oakmound/oak/v4/entities"
+)
+
+func newTestAgent(x, y float64) *Agent {
+	return NewAgent(&entities.Entity{
+		Rect:  floatgeom.NewRect2WH(x-1, y-1, 2, 2),
+		Speed: floatgeom.Point2{2, 2},
+	}, 0)
+}
+
+func TestSeekFlee(t *testing.T) {
+	a := newTestAgent(0, 0)
+	f := (&Seek{Target: floatgeom.Point2{10, 0}}).Steer(a)
+	if f != (floatgeom.Point2{2, 0}) {
+		t.Fatalf("expected seek toward target at max speed, got %v", f)
+	}
+	f = (&Flee{Target: floatgeom.Point2{10, 0}}).Steer(a)
+	if f != (floatgeom.Point2{-2, 0}) {
+		t.Fatalf("expected flee from target at max speed, got %v", f)
+	}
+	f = (&Flee{Target: floatgeom.Point2{10, 0}, PanicDistance: 5}).Steer(a)
+	if f != (floatgeom.Point2{}) {
+		t.Fatalf("expected no flee outside of panic distance, got %v", f)
+	}
+	a.Delta = floatgeom.Point2{2, 0}
+	f = (&Seek{Target: floatgeom.Point2{10, 0}}).Steer(a)
+	if f != (floatgeom.Point2{}) {
+		t.Fatalf("expected no force when already seeking at max speed, got %v", f)
+	}
+}
+
+func TestArrive(t *testing.T) {
+	a := newTestAgent(0, 0)
+	ar := &Arrive{Target: floatgeom.Point2{5, 0}, SlowDistance: 10}
+	f := ar.Steer(a)
+	if f != (floatgeom.Point2{1, 0}) {
+		t.Fatalf("expected arrive to slow to half speed, got %v", f)
+	}
+	ar.Target = floatgeom.Point2{0.5, 0}
+	ar.SlowDistance = 0
+	f = ar.Steer(a)
+	if f != (floatgeom.Point2{0.5, 0}) {
+		t.Fatalf("expected arrive not to overshoot, got %v", f)
+	}
+}
+
+func TestPursueEvade(t *testing.T) {
+	a := newTestAgent(0, 0)
+	target := &entities.Entity{
+		Rect:  floatgeom.NewRect2WH(9, -1, 2, 2),
+		Delta: floatgeom.Point2{0, 1},
+	}
+	f := (&Pursue{Target: target}).Steer(a)
+	if f.Y() <= 0 || f.X() <= 0 {
+		t.Fatalf("expected pursue to lead target, got %v", f)
+	}
+	f = (&Pursue{Target: target, MaxPrediction: 0.0001}).Steer(a)
+	if math.Abs(f.Y()) > 0.001 {
+		t.Fatalf("expected limited prediction to seek target, got %v", f)
+	}
+	f = (&Evade{Target: target}).Steer(a)
+	if f.Y() >= 0 || f.X() >= 0 {
+		t.Fatalf("expected evade to avoid target's path, got %v", f)
+	}
+}
+
+func TestAgentSteering(t *testing.T) {
+	a := newTestAgent(0, 0)
+	a.Add(&Seek{Target: floatgeom.Point2{10, 0}}, 1)
+	a.Add(&Seek{Target: floatgeom.Point2{0, 10}}, 0.5)
+	if f := a.Steering(); f != (floatgeom.Point2{2, 1}) {
+		t.Fatalf("expected weighted steering, got %v", f)
+	}
+	a.MaxForce = 1
+	if f := a.Steering(); math.Abs(f.Magnitude()-1) > 1e-9 {
+		t.Fatalf("expected steering to be truncated, got %v", f)
+	}
+	a.Clear()
+	if f := a.Steering(); f != (floatgeom.Point2{}) {
+		t.Fatalf("expected no steering without behaviors, got %v", f)
+	}
+	if v := clampSpeed(floatgeom.Point2{10, 0}, floatgeom.Point2{2, 1}); v != (floatgeom.Point2{2, 0}) {
+		t.Fatalf("expected speed clamped to x speed, got %v", v)
+	}
+	if v := clampSpeed(floatgeom.Point2{0, -10}, floatgeom.Point2{2, 1}); v != (floatgeom.Point2{0, -1}) {
+		t.Fatalf("expected speed clamped to y speed, got %v", v)
+	}
+}
+
+func TestFlocking(t *testing.T) {
+	fl := NewFlock(5)
+	a1 := newTestAgent(0, 0)
+	a2 := newTestAgent(2, 0)
+	a3 := newTestAgent(100, 0)
+	a2.Delta = floatgeom.Point2{0, 1}
+	fl.Add(a1, a2, a3)
+	if n := fl.Neighbors(a1); len(n) != 1 || n[0] != a2 {
+		t.Fatalf("expected a2 to be a1's only neighbor, got %v", n)
+	}
+	if f := (&Separation{Flock: fl}).Steer(a1); f.X() >= 0 {
+		t.Fatalf("expected separation away from a2, got %v", f)
+	}
+	if f := (&Cohesion{Flock: fl}).Steer(a1); f.X() <= 0 {
+		t.Fatalf("expected cohesion toward a2, got %v", f)
+	}
+	if f := (&Alignment{Flock: fl}).Steer(a1); f != (floatgeom.Point2{0, 2}) {
+		t.Fatalf("expected alignment with a2, got %v", f)
+	}
+	if f := (&Alignment{Flock: fl}).Steer(a3); f != (floatgeom.Point2{}) {
+		t.Fatalf("expected no alignment without neighbors, got %v", f)
+	}
+	fl.Remove(a2)
+	if n := fl.Neighbors(a1); len(n) != 0 {
+		t.Fatalf("expected no neighbors after removal, got %v", n)
+	}
+}
+
+func TestAvoidObstacles(t *testing.T) {
+	tree := collision.NewTree()
+	tree.Add(collision.NewFullSpace(10, -6, 4, 10, 1, 5))
+	a := newTestAgent(0, 0)
+	a.Delta = floatgeom.Point2{2, 0}
+	ao := NewAvoidObstacles(10, ray.Tree(tree))
+	f := ao.Steer(a)
+	if f.Y() <= 0 {
+		t.Fatalf("expected to steer below obstacle, got %v", f)
+	}
+	if f.X() >= 0 {
+		t.Fatalf("expected to brake before obstacle, got %v", f)
+	}
+	a.Delta = floatgeom.Point2{-2, 0}
+	if f := ao.Steer(a); f != (floatgeom.Point2{}) {
+		t.Fatalf("expected no avoidance moving away from obstacle, got %v", f)
+	}
+	ao = NewAvoidObstacles(10, ray.Tree(tree), ray.IgnoreLabels(1))
+	a.Delta = floatgeom.Point2{2, 0}
+	if f := ao.Steer(a); f != (floatgeom.Point2{}) {
+		t.Fatalf("expected filtered obstacle to be ignored, got %v", f)
+	}
+}
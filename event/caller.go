@@ -2,6 +2,8 @@ package event
 
 import (
 	"sync"
+
+	"github.com/oakmound/oak/v4/oakerr"
 )
 
 // A CallerID is a caller ID that Callers use to bind themselves to receive callback
@@ -85,6 +87,22 @@ func (cm *CallerMap) RemoveEntity(id CallerID) {
 	cm.callersLock.Unlock()
 }
 
+// Restore adds an entity back to the caller map under an ID it was previously
+// registered with, so bindings made against that ID will apply to it again. The ID
+// must have been assigned by this map and must not be in use.
+func (cm *CallerMap) Restore(id CallerID, e Caller) error {
+	cm.callersLock.Lock()
+	defer cm.callersLock.Unlock()
+	if id == Global || id > cm.highestID {
+		return oakerr.InvalidInput{InputName: "id"}
+	}
+	if _, ok := cm.callers[id]; ok {
+		return oakerr.ExistingElement{InputName: "id", InputType: "CallerID"}
+	}
+	cm.callers[id] = e
+	return nil
+}
+
 // Clear clears the caller map to forget all registered callers.
 func (cm *CallerMap) Clear() {
 	cm.callersLock.Lock()
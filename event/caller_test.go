@@ -64,4 +64,25 @@ func TestCallerMap_Register(t *testing.T) {
 			t.Fatalf("caller map has registered caller after clear")
 		}
 	})
+	t.Run("Restore", func(t *testing.T) {
+		m := event.NewCallerMap()
+		c1 := event.CallerID(rand.Intn(10000))
+		id := m.Register(c1)
+		if err := m.Restore(id, c1); err == nil {
+			t.Fatalf("restore should fail for a registered id")
+		}
+		m.RemoveEntity(id)
+		if err := m.Restore(id, c1); err != nil {
+			t.Fatalf("restore failed: %v", err)
+		}
+		if m.GetEntity(id) != c1 {
+			t.Fatalf("unable to retrieve restored caller")
+		}
+		if err := m.Restore(id+1, c1); err == nil {
+			t.Fatalf("restore should fail for an unassigned id")
+		}
+		if err := m.Restore(event.Global, c1); err == nil {
+			t.Fatalf("restore should fail for the global id")
+		}
+	})
 }
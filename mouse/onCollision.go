@@ -3,6 +3,7 @@ package mouse
 import (
 	"errors"
 
+	"github.com/oakmound/oak/v4/alg/floatgeom"
 	"github.com/oakmound/oak/v4/collision"
 	"github.com/oakmound/oak/v4/event"
 )
@@ -15,6 +16,7 @@ type CollisionPhase struct {
 	LastEvent    *Event
 
 	wasTouching bool
+	lastContact Contact
 }
 
 func (cp *CollisionPhase) getCollisionPhase() *CollisionPhase {
@@ -31,7 +33,8 @@ type collisionPhase interface {
 
 // PhaseCollision binds to the entity behind the space's CID so that it will
 // receive MouseCollisionStart and MouseCollisionStop events, appropriately when
-// the mouse begins to hover or stops hovering over the input space.
+// the mouse begins to hover or stops hovering over the input space, along with
+// Stay and Contact events.
 func PhaseCollision(s *collision.Space, handler event.Handler) error {
 	en := handler.GetCallerMap().GetEntity(s.CID)
 	if cp, ok := en.(collisionPhase); ok {
@@ -44,12 +47,33 @@ func PhaseCollision(s *collision.Space, handler event.Handler) error {
 	return errors.New("This space's entity does not implement collisionPhase")
 }
 
-// MouseCollisionStart/Stop: see collision Start/Stop, for mouse collision
+// MouseCollisionStart/Stay/Stop: see collision Start/Stop, for mouse collision.
+// Stay is triggered each frame the mouse remains over the space.
 var (
 	Start = event.RegisterEvent[*Event]()
+	Stay  = event.RegisterEvent[*Event]()
 	Stop  = event.RegisterEvent[*Event]()
 )
 
+// A Contact is where the mouse touches a CollisionPhase's space.
+type Contact struct {
+	// Space is the space the mouse touched.
+	Space *collision.Space
+	// Point is where the mouse touched the space, and Offset is where that is
+	// from the space's top left corner.
+	Point, Offset floatgeom.Point2
+	// Event is the mouse event the contact was found from.
+	Event *Event
+}
+
+// ContactStart/Stay/Stop: as Start/Stay/Stop, but with where the mouse touched
+// the space. Stop carries the last contact before the mouse left.
+var (
+	ContactStart = event.RegisterEvent[Contact]()
+	ContactStay  = event.RegisterEvent[Contact]()
+	ContactStop  = event.RegisterEvent[Contact]()
+)
+
 func phaseCollisionEnter(id event.CallerID, handler event.Handler, _ interface{}) event.Response {
 	e, ok := handler.GetCallerMap().GetEntity(id).(collisionPhase)
 	if !ok {
@@ -70,14 +94,28 @@ func phaseCollisionEnter(id event.CallerID, handler event.Handler, _ interface{}
 	}
 
 	if oc.OnCollisionS.Contains(ev.ToSpace()) {
+		s := oc.OnCollisionS
+		c := Contact{
+			Space:  s,
+			Point:  ev.Point2,
+			Offset: ev.Point2.Sub(floatgeom.Point2{s.X(), s.Y()}),
+			Event:  ev,
+		}
 		if !oc.wasTouching {
 			event.TriggerForCallerOn(handler, id, Start, ev)
+			event.TriggerForCallerOn(handler, id, ContactStart, c)
 			oc.wasTouching = true
+		} else {
+			event.TriggerForCallerOn(handler, id, Stay, ev)
+			event.TriggerForCallerOn(handler, id, ContactStay, c)
 		}
+		oc.lastContact = c
 	} else {
 		if oc.wasTouching {
 			event.TriggerForCallerOn(handler, id, Stop, ev)
+			event.TriggerForCallerOn(handler, id, ContactStop, oc.lastContact)
 			oc.wasTouching = false
+			oc.lastContact = Contact{}
 		}
 	}
 	return 0
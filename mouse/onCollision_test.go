@@ -38,14 +38,29 @@ func TestCollisionPhase(t *testing.T) {
 		activeCh <- false
 		return 0
 	})
+	stayCh := make(chan struct{}, 100)
+	b3 := event.Bind(b, Stay, cp, func(_ *cphase, _ *Event) event.Response {
+		select {
+		case stayCh <- struct{}{}:
+		default:
+		}
+		return 0
+	})
 	<-b1.Bound
 	<-b2.Bound
+	<-b3.Bound
 	LastEvent = Event{
 		Point2: floatgeom.Point2{10, 10},
 	}
 	if active := <-activeCh; !active {
 		t.Fatalf("collision should be active")
 	}
+	<-event.TriggerOn(b, event.Enter, event.EnterPayload{})
+	select {
+	case <-stayCh:
+	case <-time.After(time.Second):
+		t.Fatalf("collision should stay active")
+	}
 
 	LastEvent = Event{
 		Point2: floatgeom.Point2{21, 21},
@@ -56,6 +71,46 @@ func TestCollisionPhase(t *testing.T) {
 	}
 }
 
+func TestCollisionPhaseContacts(t *testing.T) {
+	b := event.NewBus(event.NewCallerMap())
+	cp := &cphase{}
+	cid := b.GetCallerMap().Register(cp)
+	s := collision.NewSpace(10, 10, 10, 10, cid)
+	if err := PhaseCollision(s, b); err != nil {
+		t.Fatalf("phase collision failed: %v", err)
+	}
+	contacts := make(chan Contact, 3)
+	record := func(_ *cphase, c Contact) event.Response {
+		contacts <- c
+		return 0
+	}
+	for _, ev := range []event.EventID[Contact]{ContactStart, ContactStay, ContactStop} {
+		<-event.Bind(b, ev, cp, record).Bound
+	}
+	expect := func(pt, offset floatgeom.Point2) {
+		t.Helper()
+		select {
+		case c := <-contacts:
+			if c.Space != s || c.Point != pt || c.Offset != offset {
+				t.Fatalf("expected contact at %v, %v into the space, got %+v", pt, offset, c)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected a contact")
+		}
+	}
+	enter := func(x, y float64) {
+		cp.LastEvent = &Event{Point2: floatgeom.Point2{x, y}}
+		phaseCollisionEnter(cid, b, nil)
+	}
+	enter(12, 13)
+	expect(floatgeom.Point2{12, 13}, floatgeom.Point2{2, 3})
+	enter(15, 11)
+	expect(floatgeom.Point2{15, 11}, floatgeom.Point2{5, 1})
+	// Leaving reports where the mouse last touched the space.
+	enter(30, 30)
+	expect(floatgeom.Point2{15, 11}, floatgeom.Point2{5, 1})
+}
+
 func TestPhaseCollision_Unembedded(t *testing.T) {
 	t.Parallel()
 	s3 := collision.NewSpace(10, 10, 10, 10, 5)
@@ -0,0 +1,187 @@
+package constraint
+
+import (
+	"math"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/physics"
+)
+
+// A Constraint restricts how particles can move. Constraints are solved
+// several times each step, so they affect each other less.
+type Constraint interface {
+	// Solve moves particles toward satisfying the constraint.
+	Solve()
+}
+
+// A Force pushes particles once each step, before constraints are solved.
+type Force interface {
+	// Apply pushes particles over a step of dt seconds.
+	Apply(dt float64)
+}
+
+// A Distance constraint keeps two particles a fixed distance apart, like a rod.
+type Distance struct {
+	A, B   *Particle
+	Length float64
+	// Stiffness is how much of the error in distance is corrected each time
+	// the constraint is solved, from 0 to 1.
+	Stiffness float64
+	// Slack, if set, only limits how far apart the particles can be, like
+	// a rope.
+	Slack bool
+}
+
+// NewDistance returns a rigid Distance constraint holding two particles at
+// their current distance.
+func NewDistance(a, b *Particle) *Distance {
+	return &Distance{
+		A:         a,
+		B:         b,
+		Length:    a.Pos().Distance(b.Pos()),
+		Stiffness: 1,
+	}
+}
+
+// Solve satisfies Constraint.
+func (d *Distance) Solve() {
+	delta := d.B.Pos().Sub(d.A.Pos())
+	dist := delta.Magnitude()
+	if dist == 0 || (d.Slack && dist <= d.Length) {
+		return
+	}
+	separate(d.A, d.B, delta.DivConst(dist).MulConst((dist-d.Length)*d.Stiffness))
+}
+
+// separate moves two particles by a correction, split by their masses, with a
+// moving toward b by the correction and b moving away from a.
+func separate(a, b *Particle, correction floatgeom.Point2) {
+	wa, wb := a.invMass(), b.invMass()
+	total := wa + wb
+	if total == 0 {
+		return
+	}
+	a.setPos(a.Pos().Add(correction.MulConst(wa / total)))
+	b.setPos(b.Pos().Sub(correction.MulConst(wb / total)))
+}
+
+// A Spring is a Force pulling two particles toward a rest length apart, with
+// damping resisting how fast they move toward or away from each other.
+type Spring struct {
+	A, B       *Particle
+	RestLength float64
+	// Stiffness is the spring constant, in force per unit of stretch.
+	Stiffness float64
+	// Damping is the force resisting each unit per second of stretching.
+	Damping float64
+}
+
+// NewSpring returns a Spring resting at the particles' current distance.
+func NewSpring(a, b *Particle, stiffness, damping float64) *Spring {
+	return &Spring{
+		A:          a,
+		B:          b,
+		RestLength: a.Pos().Distance(b.Pos()),
+		Stiffness:  stiffness,
+		Damping:    damping,
+	}
+}
+
+// Apply satisfies Force.
+func (s *Spring) Apply(dt float64) {
+	if dt <= 0 {
+		return
+	}
+	delta := s.B.Pos().Sub(s.A.Pos())
+	dist := delta.Magnitude()
+	if dist == 0 {
+		return
+	}
+	dir := delta.DivConst(dist)
+	stretchSpeed := s.B.Velocity().Sub(s.A.Velocity()).Dot(dir) / dt
+	force := s.Stiffness*(dist-s.RestLength) + s.Damping*stretchSpeed
+	// Springs act as forces, moving each particle by its acceleration
+	impulse := dir.MulConst(force * dt * dt)
+	s.A.setPos(s.A.Pos().Add(impulse.MulConst(s.A.invMass())))
+	s.B.setPos(s.B.Pos().Sub(impulse.MulConst(s.B.invMass())))
+}
+
+// A Pin holds a particle at an offset from an anchor vector, which may itself
+// be moving, such as an entity's position.
+type Pin struct {
+	Particle *Particle
+	Anchor   physics.Vector
+	Offset   floatgeom.Point2
+}
+
+// NewPin returns a Pin holding a particle at its current offset from an anchor.
+func NewPin(p *Particle, anchor physics.Vector) *Pin {
+	return &Pin{
+		Particle: p,
+		Anchor:   anchor,
+		Offset:   p.Pos().Sub(floatgeom.Point2{anchor.X(), anchor.Y()}),
+	}
+}
+
+// Solve satisfies Constraint.
+func (p *Pin) Solve() {
+	p.Particle.SetPos(floatgeom.Point2{p.Anchor.X(), p.Anchor.Y()}.Add(p.Offset))
+}
+
+// A Hinge holds a particle at a fixed distance from a pivot vector, free to
+// swing between a minimum and maximum angle, like the end of a hinged platform.
+type Hinge struct {
+	Particle *Particle
+	Pivot    physics.Vector
+	Length   float64
+	// MinAngle and MaxAngle limit the angle from the pivot to the particle,
+	// in radians, from -π to π. If they are equal, the hinge swings freely.
+	MinAngle, MaxAngle float64
+}
+
+// NewHinge returns a freely swinging Hinge holding a particle at its current
+// distance from a pivot.
+func NewHinge(p *Particle, pivot physics.Vector) *Hinge {
+	return &Hinge{
+		Particle: p,
+		Pivot:    pivot,
+		Length:   p.Pos().Distance(floatgeom.Point2{pivot.X(), pivot.Y()}),
+	}
+}
+
+// Solve satisfies Constraint.
+func (h *Hinge) Solve() {
+	if h.Particle.invMass() == 0 {
+		return
+	}
+	pivot := floatgeom.Point2{h.Pivot.X(), h.Pivot.Y()}
+	delta := h.Particle.Pos().Sub(pivot)
+	angle := math.Atan2(delta.Y(), delta.X())
+	if h.MinAngle != h.MaxAngle {
+		angle = clampAngle(angle, h.MinAngle, h.MaxAngle)
+	}
+	h.Particle.setPos(pivot.Add(floatgeom.Point2{math.Cos(angle), math.Sin(angle)}.MulConst(h.Length)))
+}
+
+// clampAngle limits an angle to the range from min to max, which may wrap
+// past π, moving angles outside of it to the nearer end.
+func clampAngle(angle, min, max float64) float64 {
+	span := normalizeAngle(max - min)
+	rel := normalizeAngle(angle - min)
+	if rel <= span {
+		return angle
+	}
+	if rel-span < 2*math.Pi-rel {
+		return max
+	}
+	return min
+}
+
+// normalizeAngle returns an angle from 0 to 2π.
+func normalizeAngle(a float64) float64 {
+	a = math.Mod(a, 2*math.Pi)
+	if a < 0 {
+		a += 2 * math.Pi
+	}
+	return a
+}
@@ -0,0 +1,170 @@
+package constraint
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/physics"
+)
+
+func steps(s *System, n int) {
+	for i := 0; i < n; i++ {
+		s.StepOnce()
+	}
+}
+
+func TestRopeHangs(t *testing.T) {
+	r, err := NewRope(floatgeom.Point2{0, 0}, floatgeom.Point2{100, 0}, 10)
+	if err != nil {
+		t.Fatalf("failed to create rope: %v", err)
+	}
+	r.Start().Pinned = true
+	// Something attached to the end of the rope should follow it
+	follower := physics.NewVector(0, 0)
+	follower.Attach(r.End(), 0, 5)
+
+	s := NewSystem()
+	s.Gravity = floatgeom.Point2{0, 500}
+	s.Iterations = 20
+	s.AddRope(r)
+	steps(s, 600)
+
+	if r.Start().Pos() != (floatgeom.Point2{0, 0}) {
+		t.Fatalf("expected pinned start not to move, got %v", r.Start().Pos())
+	}
+	end := r.End().Pos()
+	if math.Abs(end.X()) > 5 || end.Y() < 95 {
+		t.Fatalf("expected rope to hang down, end at %v", end)
+	}
+	for _, l := range r.Links {
+		if d := l.A.Pos().Distance(l.B.Pos()); math.Abs(d-10) > .5 {
+			t.Fatalf("expected rope links to keep their length, got %v", d)
+		}
+	}
+	if follower.X() != end.X() || follower.Y() != end.Y()+5 {
+		t.Fatalf("expected attached vector to follow rope end, got %v,%v", follower.X(), follower.Y())
+	}
+}
+
+func TestDistanceSlack(t *testing.T) {
+	a := NewParticle(physics.NewVector(0, 0))
+	b := NewParticle(physics.NewVector(10, 0))
+	d := NewDistance(a, b)
+	d.Slack = true
+	b.SetPos(floatgeom.Point2{5, 0})
+	d.Solve()
+	if b.Pos() != (floatgeom.Point2{5, 0}) {
+		t.Fatalf("expected slack constraint to allow shortening, got %v", b.Pos())
+	}
+	b.SetPos(floatgeom.Point2{20, 0})
+	d.Solve()
+	if a.Pos() != (floatgeom.Point2{5, 0}) || b.Pos() != (floatgeom.Point2{15, 0}) {
+		t.Fatalf("expected equal particles to share correction, got %v %v", a.Pos(), b.Pos())
+	}
+	a.Mass = 0
+	b.SetPos(floatgeom.Point2{25, 0})
+	d.Solve()
+	if a.Pos() != (floatgeom.Point2{5, 0}) || b.Pos() != (floatgeom.Point2{15, 0}) {
+		t.Fatalf("expected massless particle not to move, got %v %v", a.Pos(), b.Pos())
+	}
+}
+
+func TestSpringSettles(t *testing.T) {
+	anchor := NewParticle(physics.NewVector(0, 0))
+	anchor.Pinned = true
+	weight := NewParticle(physics.NewVector(20, 0))
+	spring := NewSpring(anchor, weight, 100, 2)
+	weight.SetPos(floatgeom.Point2{40, 0})
+
+	s := NewSystem()
+	s.Damping = 0
+	s.AddParticles(anchor, weight)
+	s.AddForces(spring)
+	overshot := false
+	for i := 0; i < 1200; i++ {
+		s.StepOnce()
+		if weight.X() < 20 {
+			overshot = true
+		}
+	}
+	if !overshot {
+		t.Fatalf("expected spring to oscillate past its rest length")
+	}
+	if math.Abs(weight.X()-20) > .5 {
+		t.Fatalf("expected damped spring to settle at rest length, got %v", weight.X())
+	}
+}
+
+func TestPinFollowsAnchor(t *testing.T) {
+	anchor := physics.NewVector(0, 0)
+	p := NewParticle(physics.NewVector(5, 5))
+	pin := NewPin(p, anchor)
+	anchor.SetPos(10, 10)
+	pin.Solve()
+	if p.Pos() != (floatgeom.Point2{15, 15}) {
+		t.Fatalf("expected pinned particle to follow anchor, got %v", p.Pos())
+	}
+	if p.Velocity() != (floatgeom.Point2{}) {
+		t.Fatalf("expected pin not to change particle velocity, got %v", p.Velocity())
+	}
+}
+
+func TestHingeLimits(t *testing.T) {
+	pivot := physics.NewVector(0, 0)
+	p := NewParticle(physics.NewVector(10, 0))
+	h := NewHinge(p, pivot)
+	h.MinAngle, h.MaxAngle = -math.Pi/4, math.Pi/4
+
+	s := NewSystem()
+	s.Gravity = floatgeom.Point2{0, 500}
+	s.AddParticles(p)
+	s.AddConstraints(h)
+	steps(s, 120)
+	expected := floatgeom.Point2{math.Cos(math.Pi / 4), math.Sin(math.Pi / 4)}.MulConst(10)
+	if p.Pos().Distance(expected) > 1e-9 {
+		t.Fatalf("expected hinge to stop at its limit, got %v", p.Pos())
+	}
+
+	if clampAngle(math.Pi-.1, 3*math.Pi/4, -3*math.Pi/4) != math.Pi-.1 {
+		t.Fatalf("expected angle within wrapping range to be unchanged")
+	}
+	if clampAngle(.1, 3*math.Pi/4, -3*math.Pi/4) != 3*math.Pi/4 {
+		t.Fatalf("expected angle to clamp to the nearer end")
+	}
+}
+
+func TestRopeErrors(t *testing.T) {
+	if _, err := NewRope(floatgeom.Point2{}, floatgeom.Point2{1, 1}, 0); err == nil {
+		t.Fatalf("expected error for rope without segments")
+	}
+	if _, err := NewChain(physics.NewVector(0, 0)); err == nil {
+		t.Fatalf("expected error for chain of one vector")
+	}
+}
+
+func TestSystemRun(t *testing.T) {
+	bus := event.NewBus(event.NewCallerMap())
+	p := NewParticle(physics.NewVector(0, 0))
+	p.SetVelocity(floatgeom.Point2{1, 0})
+	s := NewSystem()
+	s.Damping = 0
+	s.AddParticles(p)
+	binding := s.Run(bus)
+	<-binding.Bound
+	<-event.TriggerOn(bus, event.Enter, event.EnterPayload{SinceLastFrame: 2 * s.Step})
+	if p.X() != 2 {
+		t.Fatalf("expected two steps, got %v", p.X())
+	}
+	<-event.TriggerOn(bus, event.Enter, event.EnterPayload{SinceLastFrame: time.Second})
+	if p.X() != 7 {
+		t.Fatalf("expected steps to be limited, got %v", p.X())
+	}
+	s.Clear()
+	<-event.TriggerOn(bus, event.Enter, event.EnterPayload{SinceLastFrame: s.Step})
+	if p.X() != 7 {
+		t.Fatalf("expected cleared system not to move particle")
+	}
+}
@@ -0,0 +1,4 @@
+// Package constraint provides Verlet particles linked by distance, spring, pin
+// and hinge constraints, for ropes, chains, springs and hinged platforms.
+// Particles move physics.Vectors, so anything attached to them follows along.
+package constraint
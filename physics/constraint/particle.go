@@ -0,0 +1,78 @@
+package constraint
+
+import (
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/physics"
+)
+
+// A Particle is a point moved by Verlet integration, which keeps its velocity
+// as the difference between its current and previous positions.
+type Particle struct {
+	// Vector is the particle's position. Anything attached to it follows
+	// the particle.
+	physics.Vector
+	// Mass affects how much the particle moves when constraints pull on it.
+	// Particles with no mass cannot be moved by constraints.
+	Mass float64
+	// Pinned particles do not move on their own, but can be moved directly.
+	Pinned bool
+
+	prev floatgeom.Point2
+}
+
+// NewParticle returns a particle of mass 1 moving the given vector.
+func NewParticle(v physics.Vector) *Particle {
+	return &Particle{
+		Vector: v,
+		Mass:   1,
+		prev:   floatgeom.Point2{v.X(), v.Y()},
+	}
+}
+
+// Pos returns the position of the particle.
+func (p *Particle) Pos() floatgeom.Point2 {
+	return floatgeom.Point2{p.X(), p.Y()}
+}
+
+// SetPos moves the particle without changing its velocity.
+func (p *Particle) SetPos(pos floatgeom.Point2) {
+	p.prev = p.prev.Add(pos.Sub(p.Pos()))
+	p.setPos(pos)
+}
+
+// setPos moves the particle, changing its velocity to match.
+func (p *Particle) setPos(pos floatgeom.Point2) {
+	// Keep the offsets of vectors attached to others
+	x, y := p.GetPos()
+	p.Vector.SetPos(x+pos.X()-p.X(), y+pos.Y()-p.Y())
+}
+
+// Velocity returns how far the particle moved over its last step.
+func (p *Particle) Velocity() floatgeom.Point2 {
+	return p.Pos().Sub(p.prev)
+}
+
+// SetVelocity sets how far the particle will move over its next step.
+func (p *Particle) SetVelocity(v floatgeom.Point2) {
+	p.prev = p.Pos().Sub(v)
+}
+
+func (p *Particle) invMass() float64 {
+	if p.Pinned || p.Mass <= 0 {
+		return 0
+	}
+	return 1 / p.Mass
+}
+
+// integrate moves the particle by its velocity, slowed by damping, and by an
+// acceleration over a step of dt seconds.
+func (p *Particle) integrate(accel floatgeom.Point2, damping, dt float64) {
+	if p.Pinned {
+		p.prev = p.Pos()
+		return
+	}
+	pos := p.Pos()
+	next := pos.Add(p.Velocity().MulConst(1 - damping)).Add(accel.MulConst(dt * dt))
+	p.prev = pos
+	p.setPos(next)
+}
@@ -0,0 +1,57 @@
+package constraint
+
+import (
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/oakerr"
+	"github.com/oakmound/oak/v4/physics"
+)
+
+// A Rope is a chain of particles, each linked to the next by a Distance
+// constraint.
+type Rope struct {
+	Particles []*Particle
+	Links     []*Distance
+}
+
+// NewRope returns a rope of evenly spaced particles from one point to another,
+// split into the given number of segments.
+func NewRope(from, to floatgeom.Point2, segments int) (*Rope, error) {
+	if segments < 1 {
+		return nil, oakerr.InvalidInput{InputName: "segments"}
+	}
+	vs := make([]physics.Vector, segments+1)
+	for i := range vs {
+		p := from.Add(to.Sub(from).MulConst(float64(i) / float64(segments)))
+		vs[i] = physics.NewVector(p.X(), p.Y())
+	}
+	return NewChain(vs...)
+}
+
+// NewChain returns a rope linking existing vectors, like the positions of
+// renderables, at their current distances from each other.
+func NewChain(vs ...physics.Vector) (*Rope, error) {
+	if len(vs) < 2 {
+		return nil, oakerr.InsufficientInputs{AtLeast: 2, InputName: "vs"}
+	}
+	r := &Rope{
+		Particles: make([]*Particle, len(vs)),
+		Links:     make([]*Distance, len(vs)-1),
+	}
+	for i, v := range vs {
+		r.Particles[i] = NewParticle(v)
+	}
+	for i := range r.Links {
+		r.Links[i] = NewDistance(r.Particles[i], r.Particles[i+1])
+	}
+	return r, nil
+}
+
+// Start returns the first particle of the rope.
+func (r *Rope) Start() *Particle {
+	return r.Particles[0]
+}
+
+// End returns the last particle of the rope.
+func (r *Rope) End() *Particle {
+	return r.Particles[len(r.Particles)-1]
+}
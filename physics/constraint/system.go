@@ -0,0 +1,130 @@
+package constraint
+
+import (
+	"sync"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/event"
+)
+
+// A System moves particles and solves the constraints between them in fixed
+// time steps.
+type System struct {
+	// Gravity accelerates every particle, in units per second per second.
+	Gravity floatgeom.Point2
+	// Damping is how much of each particle's velocity is lost each step,
+	// from 0 to 1.
+	Damping float64
+	// Step is how much time each step of the system covers.
+	Step time.Duration
+	// MaxSteps limits how many steps Update will run at once.
+	MaxSteps int
+	// Iterations is how many times constraints are solved each step. More
+	// iterations make ropes and chains stretch less.
+	Iterations int
+
+	lock        sync.Mutex
+	particles   []*Particle
+	forces      []Force
+	constraints []Constraint
+	accumulated time.Duration
+}
+
+// NewSystem returns a system stepped 60 times a second, with no gravity.
+func NewSystem() *System {
+	return &System{
+		Damping:    .01,
+		Step:       time.Second / 60,
+		MaxSteps:   5,
+		Iterations: 8,
+	}
+}
+
+// AddParticles adds particles to be moved by the system.
+func (s *System) AddParticles(ps ...*Particle) {
+	s.lock.Lock()
+	s.particles = append(s.particles, ps...)
+	s.lock.Unlock()
+}
+
+// AddConstraints adds constraints to be solved by the system.
+func (s *System) AddConstraints(cs ...Constraint) {
+	s.lock.Lock()
+	s.constraints = append(s.constraints, cs...)
+	s.lock.Unlock()
+}
+
+// AddForces adds forces to be applied by the system.
+func (s *System) AddForces(fs ...Force) {
+	s.lock.Lock()
+	s.forces = append(s.forces, fs...)
+	s.lock.Unlock()
+}
+
+// AddRope adds the particles and links of a rope to the system.
+func (s *System) AddRope(r *Rope) {
+	s.AddParticles(r.Particles...)
+	for _, l := range r.Links {
+		s.AddConstraints(l)
+	}
+}
+
+// Clear removes everything from the system.
+func (s *System) Clear() {
+	s.lock.Lock()
+	s.particles = nil
+	s.forces = nil
+	s.constraints = nil
+	s.lock.Unlock()
+}
+
+// Run steps the system each frame of the handler's event.Enter events. Unbind
+// the returned binding to stop it.
+func (s *System) Run(h event.Handler) event.Binding {
+	return event.GlobalBind(h, event.Enter, func(ev event.EnterPayload) event.Response {
+		s.Update(ev.SinceLastFrame)
+		return 0
+	})
+}
+
+// Update advances the system by elapsed time, running as many fixed steps as
+// have elapsed, up to MaxSteps. Time short of a full step is carried over to
+// the next Update.
+func (s *System) Update(elapsed time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.Step <= 0 {
+		return
+	}
+	s.accumulated += elapsed
+	for steps := 0; s.accumulated >= s.Step; steps++ {
+		if s.MaxSteps > 0 && steps >= s.MaxSteps {
+			s.accumulated = 0
+			return
+		}
+		s.step(s.Step.Seconds())
+		s.accumulated -= s.Step
+	}
+}
+
+// StepOnce advances the system by exactly one step.
+func (s *System) StepOnce() {
+	s.lock.Lock()
+	s.step(s.Step.Seconds())
+	s.lock.Unlock()
+}
+
+func (s *System) step(dt float64) {
+	for _, f := range s.forces {
+		f.Apply(dt)
+	}
+	for _, p := range s.particles {
+		p.integrate(s.Gravity, s.Damping, dt)
+	}
+	for i := 0; i < s.Iterations; i++ {
+		for _, c := range s.constraints {
+			c.Solve()
+		}
+	}
+}
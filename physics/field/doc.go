@@ -0,0 +1,5 @@
+// Package field applies forces, like wind, conveyor belts, water and gravity
+// wells, to pushable things overlapping labeled regions of a collision tree,
+// and triggers events as they enter and exit those regions. Pushable things
+// are found through the caller IDs of their spaces.
+package field
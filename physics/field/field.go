@@ -0,0 +1,119 @@
+package field
+
+import (
+	"math"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/physics"
+)
+
+// A Field pushes targets overlapping a region.
+type Field interface {
+	// Force returns the force a region applies to a target over dt seconds.
+	Force(region, target *collision.Space, p physics.Pushable, dt float64) physics.ForceVector
+}
+
+// FieldFunc is a function satisfying Field.
+type FieldFunc func(region, target *collision.Space, p physics.Pushable, dt float64) physics.ForceVector
+
+// Force satisfies Field.
+func (ff FieldFunc) Force(region, target *collision.Space, p physics.Pushable, dt float64) physics.ForceVector {
+	return ff(region, target, p, dt)
+}
+
+func force(f floatgeom.Point2) physics.ForceVector {
+	return physics.NewForceVector(physics.NewVector(f.X(), f.Y()), f.Magnitude())
+}
+
+// Wind pushes targets in a direction with a constant force, so lighter targets
+// are pushed faster.
+type Wind struct {
+	// Strength is the direction and force of the wind, per second.
+	Strength floatgeom.Point2
+}
+
+var _ Field = Wind{}
+
+// Force satisfies Field.
+func (w Wind) Force(_, _ *collision.Space, _ physics.Pushable, dt float64) physics.ForceVector {
+	return force(w.Strength.MulConst(dt))
+}
+
+// A Conveyor carries targets along at its velocity, like a conveyor belt or a
+// river current.
+type Conveyor struct {
+	// Velocity is how fast targets are carried.
+	Velocity floatgeom.Point2
+	// Grip is how quickly targets reach the conveyor's velocity, as the
+	// portion of the difference removed per second.
+	Grip float64
+}
+
+var _ Field = Conveyor{}
+
+// Force satisfies Field.
+func (c Conveyor) Force(_, _ *collision.Space, p physics.Pushable, dt float64) physics.ForceVector {
+	d := p.GetDelta()
+	diff := c.Velocity.Sub(floatgeom.Point2{d.X(), d.Y()})
+	return force(diff.MulConst(p.GetMass() * math.Min(1, c.Grip*dt)))
+}
+
+// Water lifts targets against gravity and slows them, in proportion to how
+// much of each target is submerged.
+type Water struct {
+	// Gravity is the gravity buoyancy opposes.
+	Gravity floatgeom.Point2
+	// Density is the mass of water per unit of area, which lifts a target
+	// by the mass of the water it displaces.
+	Density float64
+	// Drag is the portion of a fully submerged target's velocity removed
+	// per second.
+	Drag float64
+}
+
+var _ Field = Water{}
+
+// Force satisfies Field.
+func (w Water) Force(region, target *collision.Space, p physics.Pushable, dt float64) physics.ForceVector {
+	r, t := region.Location.ProjectZ(), target.Location.ProjectZ()
+	w2 := math.Min(r.Max.X(), t.Max.X()) - math.Max(r.Min.X(), t.Min.X())
+	h2 := math.Min(r.Max.Y(), t.Max.Y()) - math.Max(r.Min.Y(), t.Min.Y())
+	if w2 <= 0 || h2 <= 0 || t.Area() <= 0 {
+		return force(floatgeom.Point2{})
+	}
+	submerged := w2 * h2
+	buoyancy := w.Gravity.MulConst(-w.Density * submerged * dt)
+	d := p.GetDelta()
+	drag := floatgeom.Point2{d.X(), d.Y()}.MulConst(-p.GetMass() * math.Min(1, w.Drag*dt) * submerged / t.Area())
+	return force(buoyancy.Add(drag))
+}
+
+// A GravityWell pulls targets toward the center of its region, more strongly
+// the closer they are.
+type GravityWell struct {
+	// Strength is the acceleration toward the center a target receives
+	// at a distance of one unit, falling off with the square of distance.
+	Strength float64
+	// MinDistance limits how strong the pull can be as a target nears the
+	// center. It defaults to 1.
+	MinDistance float64
+}
+
+var _ Field = GravityWell{}
+
+// Force satisfies Field.
+func (g GravityWell) Force(region, target *collision.Space, p physics.Pushable, dt float64) physics.ForceVector {
+	delta := region.Location.ProjectZ().Center().Sub(target.Location.ProjectZ().Center())
+	dist := delta.Magnitude()
+	if dist == 0 {
+		return force(floatgeom.Point2{})
+	}
+	min := g.MinDistance
+	if min <= 0 {
+		min = 1
+	}
+	d := math.Max(dist, min)
+	accel := g.Strength / (d * d)
+	return force(delta.DivConst(dist).MulConst(accel * p.GetMass() * dt))
+}
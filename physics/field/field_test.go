@@ -0,0 +1,197 @@
+package field
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/physics"
+)
+
+type crate struct {
+	physics.Mass
+	delta physics.Vector
+}
+
+func (c *crate) GetDelta() physics.Vector {
+	return c.delta
+}
+
+func newCrate(mass float64) *crate {
+	c := &crate{delta: physics.NewVector(0, 0)}
+	c.SetMass(mass)
+	return c
+}
+
+const (
+	windLabel collision.Label = iota + 1
+	waterLabel
+	triggerLabel
+)
+
+func TestSystem(t *testing.T) {
+	tree := collision.NewTree()
+	wind := collision.NewLabeledSpace(0, 0, 100, 100, windLabel)
+	water := collision.NewLabeledSpace(0, 50, 100, 50, waterLabel)
+	trigger := collision.NewLabeledSpace(200, 0, 10, 10, triggerLabel)
+	tree.Add(wind, water, trigger)
+
+	bus := event.NewBus(event.NewCallerMap())
+	overlaps := make(chan string, 10)
+	event.GlobalBind(bus, Entered, func(o Overlap) event.Response {
+		if o.Region == trigger {
+			overlaps <- "entered"
+		}
+		return 0
+	})
+	event.GlobalBind(bus, Exited, func(o Overlap) event.Response {
+		if o.Region == trigger {
+			overlaps <- "exited"
+		}
+		return 0
+	})
+	time.Sleep(50 * time.Millisecond)
+
+	s := NewSystem(tree, bus)
+	s.SetField(windLabel, Wind{Strength: floatgeom.Point2{10, 0}})
+	s.SetField(waterLabel, Water{Gravity: floatgeom.Point2{0, 10}, Density: 1})
+	s.SetField(triggerLabel, nil)
+
+	light, heavy := newCrate(1), newCrate(2)
+	lightSp := collision.NewSpace(10, 10, 2, 2, 0)
+	heavySp := collision.NewSpace(20, 10, 2, 2, 0)
+	tree.Add(lightSp, heavySp)
+	s.Track(lightSp, light)
+	s.Track(heavySp, heavy)
+
+	s.Update(time.Second)
+	if light.delta.X() != 10 || heavy.delta.X() != 5 {
+		t.Fatalf("expected wind to push lighter crate faster, got %v %v", light.delta.X(), heavy.delta.X())
+	}
+	if light.delta.Y() != 0 {
+		t.Fatalf("expected crate out of water not to float, got %v", light.delta.Y())
+	}
+
+	// A crate fully in the water displaces 4 units of water, lifting it
+	tree.UpdateSpace(10, 80, 2, 2, lightSp)
+	light.delta.SetPos(0, 0)
+	s.Update(time.Second / 10)
+	if math.Abs(light.delta.Y()+4) > 1e-9 || math.Abs(light.delta.X()-1) > 1e-9 {
+		t.Fatalf("expected water to lift crate, got %v,%v", light.delta.X(), light.delta.Y())
+	}
+
+	tree.UpdateSpace(202, 2, 2, 2, lightSp)
+	s.Update(time.Second)
+	tree.UpdateSpace(10, 10, 2, 2, lightSp)
+	s.Update(time.Second)
+	// Events are triggered asynchronously, so may arrive in any order
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case got := <-overlaps:
+			seen[got] = true
+		case <-time.After(time.Second):
+			t.Fatalf("expected enter and exit events, got %v", seen)
+		}
+	}
+	if !seen["entered"] || !seen["exited"] {
+		t.Fatalf("expected enter and exit events, got %v", seen)
+	}
+
+	if err := s.Untrack(lightSp); err != nil {
+		t.Fatalf("failed to untrack: %v", err)
+	}
+	if err := s.Untrack(lightSp); err == nil {
+		t.Fatalf("expected error untracking twice")
+	}
+}
+
+// callerCrate is a crate registered as a caller, so a System can find it.
+type callerCrate struct {
+	*crate
+	event.CallerID
+}
+
+func (c callerCrate) CID() event.CallerID {
+	return c.CallerID
+}
+
+func TestSystemFindsCallers(t *testing.T) {
+	tree := collision.NewTree()
+	wind := collision.NewLabeledSpace(0, 0, 100, 100, windLabel)
+	tree.Add(wind)
+	cm := event.NewCallerMap()
+	bus := event.NewBus(cm)
+	exited := make(chan *collision.Space, 1)
+	b := event.GlobalBind(bus, Exited, func(o Overlap) event.Response {
+		exited <- o.Target
+		return 0
+	})
+	<-b.Bound
+
+	s := NewSystem(tree, bus)
+	s.SetField(windLabel, Wind{Strength: floatgeom.Point2{10, 0}})
+
+	c := callerCrate{crate: newCrate(2)}
+	c.CallerID = cm.Register(c)
+	sp := collision.NewSpace(10, 10, 2, 2, c.CallerID)
+	// Spaces whose callers cannot be pushed are left alone
+	other := collision.NewSpace(10, 10, 2, 2, cm.Register(ghost{}))
+	tree.Add(sp, other)
+
+	s.Update(time.Second)
+	if c.delta.X() != 5 {
+		t.Fatalf("expected untracked crate to be pushed, got %v", c.delta.X())
+	}
+	tree.UpdateSpace(200, 10, 2, 2, sp)
+	s.Update(time.Second)
+	if c.delta.X() != 5 {
+		t.Fatalf("expected crate out of the wind not to be pushed, got %v", c.delta.X())
+	}
+	select {
+	case got := <-exited:
+		if got != sp {
+			t.Fatalf("expected the crate to exit")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected the crate to exit the wind")
+	}
+}
+
+// A ghost is a caller which cannot be pushed.
+type ghost struct{}
+
+func (ghost) CID() event.CallerID {
+	return 0
+}
+
+func TestFields(t *testing.T) {
+	region := collision.NewSpace(0, 0, 100, 100, 0)
+	target := collision.NewSpace(89, 49, 2, 2, 0)
+
+	c := newCrate(2)
+	c.delta.SetPos(0, 4)
+	conveyor := Conveyor{Velocity: floatgeom.Point2{10, 0}, Grip: 2}
+	physics.Push(conveyor.Force(region, target, c, 1), c)
+	if math.Abs(c.delta.X()-10) > 1e-9 || math.Abs(c.delta.Y()) > 1e-9 {
+		t.Fatalf("expected full grip conveyor to match its velocity, got %v,%v", c.delta.X(), c.delta.Y())
+	}
+
+	c = newCrate(2)
+	well := GravityWell{Strength: 1600}
+	physics.Push(well.Force(region, target, c, 1), c)
+	if math.Abs(c.delta.X()+1) > 1e-9 || math.Abs(c.delta.Y()) > 1e-9 {
+		t.Fatalf("expected gravity well to pull toward center, got %v,%v", c.delta.X(), c.delta.Y())
+	}
+
+	c = newCrate(2)
+	c.delta.SetPos(10, 0)
+	water := Water{Drag: .5}
+	physics.Push(water.Force(region, target, c, 1), c)
+	if math.Abs(c.delta.X()-5) > 1e-9 {
+		t.Fatalf("expected water drag to slow crate, got %v", c.delta.X())
+	}
+}
@@ -0,0 +1,190 @@
+package field
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/oakerr"
+	"github.com/oakmound/oak/v4/physics"
+)
+
+// An Overlap is a target overlapping a region.
+type Overlap struct {
+	Region, Target *collision.Space
+}
+
+// Entered and Exited are triggered as targets enter and exit regions.
+var (
+	Entered = event.RegisterEvent[Overlap]()
+	Exited  = event.RegisterEvent[Overlap]()
+)
+
+// A System applies the fields of labeled regions in a collision tree to the
+// pushable things overlapping them. Spaces in the tree whose caller ID
+// belongs to a physics.Pushable in Callers are found automatically; other
+// spaces can be tracked by hand.
+type System struct {
+	Tree *collision.Tree
+	// Handler, if set, is triggered on as targets enter and exit regions.
+	Handler event.Handler
+	// Callers, if set, is where the callers of spaces overlapping regions are
+	// looked up, so those which are physics.Pushable are pushed.
+	Callers *event.CallerMap
+
+	lock    sync.Mutex
+	fields  map[collision.Label]Field
+	labels  []collision.Label
+	targets map[*collision.Space]physics.Pushable
+	inside  map[*collision.Space]map[*collision.Space]bool
+}
+
+// NewSystem returns a System finding regions in the given tree and triggering
+// events on the given handler, which may be nil. Targets are found through the
+// handler's caller map.
+func NewSystem(tree *collision.Tree, h event.Handler) *System {
+	var callers *event.CallerMap
+	if h != nil {
+		callers = h.GetCallerMap()
+	}
+	return &System{
+		Tree:    tree,
+		Handler: h,
+		Callers: callers,
+		fields:  make(map[collision.Label]Field),
+		targets: make(map[*collision.Space]physics.Pushable),
+		inside:  make(map[*collision.Space]map[*collision.Space]bool),
+	}
+}
+
+// SetField makes every space with the given label a region applying a field.
+// A nil field makes those spaces trigger areas, which only trigger events.
+func (s *System) SetField(l collision.Label, f Field) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.fields[l]; !ok {
+		s.labels = append(s.labels, l)
+	}
+	s.fields[l] = f
+}
+
+// Track adds a target, whose space is checked against regions, and which is
+// pushed by their fields. Only targets which cannot be found through Callers,
+// like spaces without caller IDs, need to be tracked.
+func (s *System) Track(sp *collision.Space, p physics.Pushable) {
+	s.lock.Lock()
+	s.targets[sp] = p
+	s.lock.Unlock()
+}
+
+// Untrack removes a target, or returns an error if it was not tracked. The
+// target is not considered to have exited the regions it was in.
+func (s *System) Untrack(sp *collision.Space) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.targets[sp]; !ok {
+		return oakerr.NotFound{InputName: "sp"}
+	}
+	delete(s.targets, sp)
+	delete(s.inside, sp)
+	return nil
+}
+
+// Run updates the system each frame of the handler's event.Enter events.
+// Unbind the returned binding to stop it.
+func (s *System) Run(h event.Handler) event.Binding {
+	return event.GlobalBind(h, event.Enter, func(ev event.EnterPayload) event.Response {
+		s.Update(ev.SinceLastFrame)
+		return 0
+	})
+}
+
+// Update pushes each target by the fields of the regions it overlaps, over the
+// elapsed time, and triggers events for the regions it entered or exited.
+func (s *System) Update(elapsed time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	dt := elapsed.Seconds()
+	targets := s.findTargets()
+	for target, p := range targets {
+		regions := s.Tree.Hit(target, collision.WithLabels(s.labels...))
+		now := make(map[*collision.Space]bool, len(regions))
+		for _, region := range regions {
+			if region == target {
+				continue
+			}
+			now[region] = true
+			if !s.inside[target][region] {
+				s.trigger(Entered, region, target)
+			}
+			if f := s.fields[region.Label]; f != nil {
+				physics.Push(f.Force(region, target, p, dt), p)
+			}
+		}
+		for region := range s.inside[target] {
+			if !now[region] {
+				s.trigger(Exited, region, target)
+			}
+		}
+		s.inside[target] = now
+	}
+	// Found targets which are no longer overlapping any region have exited
+	for target, in := range s.inside {
+		if _, ok := targets[target]; ok {
+			continue
+		}
+		for region := range in {
+			s.trigger(Exited, region, target)
+		}
+		delete(s.inside, target)
+	}
+}
+
+// everywhere is a rectangle containing every space.
+var everywhere = floatgeom.Rect3{
+	Min: floatgeom.Point3{math.Inf(-1), math.Inf(-1), math.Inf(-1)},
+	Max: floatgeom.Point3{math.Inf(1), math.Inf(1), math.Inf(1)},
+}
+
+// findTargets returns the tracked targets, along with each space overlapping a
+// region whose caller is Pushable.
+func (s *System) findTargets() map[*collision.Space]physics.Pushable {
+	if s.Callers == nil {
+		return s.targets
+	}
+	targets := make(map[*collision.Space]physics.Pushable, len(s.targets))
+	for sp, p := range s.targets {
+		targets[sp] = p
+	}
+	for _, region := range s.Tree.SearchIntersect(everywhere) {
+		if !s.isRegion(region) {
+			continue
+		}
+		for _, sp := range s.Tree.Hit(region) {
+			if sp.CID == 0 || s.isRegion(sp) {
+				continue
+			}
+			if _, ok := targets[sp]; ok {
+				continue
+			}
+			if p, ok := s.Callers.GetEntity(sp.CID).(physics.Pushable); ok {
+				targets[sp] = p
+			}
+		}
+	}
+	return targets
+}
+
+func (s *System) isRegion(sp *collision.Space) bool {
+	_, ok := s.fields[sp.Label]
+	return ok
+}
+
+func (s *System) trigger(ev event.EventID[Overlap], region, target *collision.Space) {
+	if s.Handler != nil {
+		event.TriggerOn(s.Handler, ev, Overlap{Region: region, Target: target})
+	}
+}
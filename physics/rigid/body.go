@@ -0,0 +1,217 @@
+package rigid
+
+import (
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/physics"
+)
+
+// A Shifter moves something, like an entities.Entity, which owns a body's space.
+type Shifter interface {
+	Shift(delta floatgeom.Point2)
+}
+
+// A Body is a rigid body simulated by a World, whose shape and position are
+// those of its collision space. Circles are simulated as circles, and all
+// other spaces as their rectangles.
+type Body struct {
+	physics.Mass
+	// Space is the body's shape and position.
+	Space *collision.Space
+	// Shifter, if set, moves the body instead of its space being moved
+	// directly, so whatever owns the space moves with it.
+	Shifter Shifter
+	// Velocity is how far the body moves per second.
+	Velocity physics.Vector
+	// Angle is how far the body has rotated, in radians. Rotation does not
+	// change a body's space, but can be used to draw it.
+	Angle float64
+	// AngularVelocity is how far the body rotates per second, in radians.
+	AngularVelocity float64
+	// Restitution is how much the body bounces, from 0 to 1.
+	Restitution float64
+	// Friction is how much the body resists sliding along other bodies.
+	Friction float64
+	// GravityScale multiplies the gravity applied to the body.
+	GravityScale float64
+
+	force  floatgeom.Point2
+	torque float64
+
+	asleep    bool
+	stillTime float64
+}
+
+// An Option modifies a body.
+type Option func(*Body)
+
+// NewBody returns a body with a mass of 1 for the given space.
+func NewBody(s *collision.Space, opts ...Option) *Body {
+	b := &Body{
+		Space:        s,
+		Velocity:     physics.NewVector(0, 0),
+		Friction:     .3,
+		GravityScale: 1,
+	}
+	b.SetMass(1)
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// WithMass sets the mass of a body. Non-positive masses are ignored.
+func WithMass(m float64) Option {
+	return func(b *Body) {
+		b.SetMass(m)
+	}
+}
+
+// Static makes a body immovable, as with Mass.Freeze.
+func Static() Option {
+	return func(b *Body) {
+		b.Freeze()
+	}
+}
+
+// WithRestitution sets how much a body bounces.
+func WithRestitution(r float64) Option {
+	return func(b *Body) {
+		b.Restitution = r
+	}
+}
+
+// WithFriction sets how much a body resists sliding.
+func WithFriction(f float64) Option {
+	return func(b *Body) {
+		b.Friction = f
+	}
+}
+
+// WithGravityScale sets how strongly gravity affects a body.
+func WithGravityScale(s float64) Option {
+	return func(b *Body) {
+		b.GravityScale = s
+	}
+}
+
+// WithVelocity sets the starting velocity of a body.
+func WithVelocity(v floatgeom.Point2) Option {
+	return func(b *Body) {
+		b.Velocity.SetPos(v.X(), v.Y())
+	}
+}
+
+// WithShifter sets what moves a body's space, see Body.Shifter.
+func WithShifter(s Shifter) Option {
+	return func(b *Body) {
+		b.Shifter = s
+	}
+}
+
+// GetDelta returns the body's velocity, so physics.Push can push bodies.
+func (b *Body) GetDelta() physics.Vector {
+	return b.Velocity
+}
+
+// Static returns whether the body cannot move.
+func (b *Body) Static() bool {
+	return b.GetMass() <= 0
+}
+
+// Asleep returns whether the body has come to rest and is no longer simulated
+// until something wakes it.
+func (b *Body) Asleep() bool {
+	return b.asleep
+}
+
+// Wake resumes simulating a sleeping body.
+func (b *Body) Wake() {
+	b.asleep = false
+	b.stillTime = 0
+}
+
+// ApplyForce pushes the body with a force over the next step, waking it.
+func (b *Body) ApplyForce(f physics.ForceVector) {
+	dir := f.Vector.Copy().Normalize()
+	b.force = b.force.Add(floatgeom.Point2{dir.X(), dir.Y()}.MulConst(*f.Force))
+	b.Wake()
+}
+
+// ApplyTorque spins the body over the next step, waking it.
+func (b *Body) ApplyTorque(torque float64) {
+	b.torque += torque
+	b.Wake()
+}
+
+// ApplyImpulse immediately changes the body's velocity by an impulse applied at
+// a point relative to its center, waking it.
+func (b *Body) ApplyImpulse(impulse, at floatgeom.Point2) {
+	if b.Static() {
+		return
+	}
+	b.Wake()
+	b.applyImpulse(impulse, at)
+}
+
+func (b *Body) applyImpulse(impulse, at floatgeom.Point2) {
+	v := b.velocity().Add(impulse.MulConst(b.invMass()))
+	b.Velocity.SetPos(v.X(), v.Y())
+	b.AngularVelocity += cross(at, impulse) * b.invInertia()
+}
+
+// Center returns the center of the body.
+func (b *Body) Center() floatgeom.Point2 {
+	if c, ok := b.Space.Shape.(collision.Circle); ok {
+		return b.min().Add(c.Center)
+	}
+	return b.Space.Location.ProjectZ().Center()
+}
+
+func (b *Body) min() floatgeom.Point2 {
+	return floatgeom.Point2{b.Space.X(), b.Space.Y()}
+}
+
+func (b *Body) velocity() floatgeom.Point2 {
+	return floatgeom.Point2{b.Velocity.X(), b.Velocity.Y()}
+}
+
+// simulated returns whether the body responds to forces and impulses.
+func (b *Body) simulated() bool {
+	return !b.Static() && !b.asleep
+}
+
+func (b *Body) invMass() float64 {
+	if !b.simulated() {
+		return 0
+	}
+	return 1 / b.GetMass()
+}
+
+func (b *Body) invInertia() float64 {
+	if !b.simulated() {
+		return 0
+	}
+	m := b.GetMass()
+	var inertia float64
+	if c, ok := b.Space.Shape.(collision.Circle); ok {
+		inertia = m * c.Radius * c.Radius / 2
+	} else {
+		w, h := b.Space.GetW(), b.Space.GetH()
+		inertia = m * (w*w + h*h) / 12
+	}
+	if inertia == 0 {
+		return 0
+	}
+	return 1 / inertia
+}
+
+func cross(a, b floatgeom.Point2) float64 {
+	return a.X()*b.Y() - a.Y()*b.X()
+}
+
+// crossScalar returns the cross product of a scalar, as a vector out of the
+// plane, with a vector.
+func crossScalar(s float64, v floatgeom.Point2) floatgeom.Point2 {
+	return floatgeom.Point2{-s * v.Y(), s * v.X()}
+}
@@ -0,0 +1,172 @@
+package rigid
+
+import (
+	"math"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+)
+
+// A contact is where two bodies overlap.
+type contact struct {
+	a, b *Body
+	// normal points from a toward b.
+	normal floatgeom.Point2
+	depth  float64
+	point  floatgeom.Point2
+}
+
+// collide returns the contact between two bodies, if they overlap.
+func collide(a, b *Body) (contact, bool) {
+	ca, aCircle := a.Space.Shape.(collision.Circle)
+	cb, bCircle := b.Space.Shape.(collision.Circle)
+	c := contact{a: a, b: b}
+	var ok bool
+	switch {
+	case aCircle && bCircle:
+		c.normal, c.depth, c.point, ok = circleCircle(a.Center(), ca.Radius, b.Center(), cb.Radius)
+	case aCircle && b.Space.Shape == nil:
+		c.normal, c.depth, c.point, ok = circleRect(a.Center(), ca.Radius, b.Space.Location.ProjectZ())
+	case bCircle && a.Space.Shape == nil:
+		c.normal, c.depth, c.point, ok = circleRect(b.Center(), cb.Radius, a.Space.Location.ProjectZ())
+		c.normal = c.normal.MulConst(-1)
+	default:
+		if !a.Space.Collides(b.Space) {
+			return c, false
+		}
+		m := collision.NewManifold(a.Space, b.Space)
+		if m.Side == collision.SideNone {
+			return c, false
+		}
+		c.depth = m.Penetration.Magnitude()
+		c.normal = m.Penetration.DivConst(-c.depth)
+		c.point = m.Overlap.Center()
+		ok = true
+	}
+	return c, ok
+}
+
+func circleCircle(ca floatgeom.Point2, ra float64, cb floatgeom.Point2, rb float64) (normal floatgeom.Point2, depth float64, point floatgeom.Point2, ok bool) {
+	d := cb.Sub(ca)
+	dist := d.Magnitude()
+	if dist >= ra+rb {
+		return normal, 0, point, false
+	}
+	normal = floatgeom.Point2{1, 0}
+	if dist != 0 {
+		normal = d.DivConst(dist)
+	}
+	return normal, ra + rb - dist, ca.Add(normal.MulConst(ra)), true
+}
+
+// circleRect returns the contact of a circle with a rectangle, with the normal
+// pointing from the circle toward the rectangle.
+func circleRect(c floatgeom.Point2, r float64, rect floatgeom.Rect2) (normal floatgeom.Point2, depth float64, point floatgeom.Point2, ok bool) {
+	closest := floatgeom.Point2{
+		math.Max(rect.Min.X(), math.Min(c.X(), rect.Max.X())),
+		math.Max(rect.Min.Y(), math.Min(c.Y(), rect.Max.Y())),
+	}
+	if closest != c {
+		d := closest.Sub(c)
+		dist := d.Magnitude()
+		if dist >= r {
+			return normal, 0, point, false
+		}
+		return d.DivConst(dist), r - dist, closest, true
+	}
+	// The circle's center is inside the rectangle, so push it out of the
+	// nearest edge
+	edges := []struct {
+		dist   float64
+		normal floatgeom.Point2
+	}{
+		{c.X() - rect.Min.X(), floatgeom.Point2{1, 0}},
+		{rect.Max.X() - c.X(), floatgeom.Point2{-1, 0}},
+		{c.Y() - rect.Min.Y(), floatgeom.Point2{0, 1}},
+		{rect.Max.Y() - c.Y(), floatgeom.Point2{0, -1}},
+	}
+	nearest := edges[0]
+	for _, e := range edges[1:] {
+		if e.dist < nearest.dist {
+			nearest = e
+		}
+	}
+	return nearest.normal, r + nearest.dist, c, true
+}
+
+// restitutionThreshold is the speed below which bodies stop bouncing, so
+// resting bodies do not jitter.
+const restitutionThreshold = 1
+
+// solveVelocity applies the impulses which stop two bodies moving into each
+// other at a contact, and the friction between them.
+func (c *contact) solveVelocity() {
+	a, b := c.a, c.b
+	ra := c.point.Sub(a.Center())
+	rb := c.point.Sub(b.Center())
+	relative := func() floatgeom.Point2 {
+		va := a.velocity().Add(crossScalar(a.AngularVelocity, ra))
+		vb := b.velocity().Add(crossScalar(b.AngularVelocity, rb))
+		return vb.Sub(va)
+	}
+	rv := relative()
+	vn := rv.Dot(c.normal)
+	if vn > 0 {
+		return
+	}
+	e := math.Max(a.Restitution, b.Restitution)
+	if -vn < restitutionThreshold {
+		e = 0
+	}
+	k := c.effectiveMass(ra, rb, c.normal)
+	if k == 0 {
+		return
+	}
+	j := -(1 + e) * vn / k
+	impulse := c.normal.MulConst(j)
+	a.applyImpulse(impulse.MulConst(-1), ra)
+	b.applyImpulse(impulse, rb)
+
+	rv = relative()
+	tangent := rv.Sub(c.normal.MulConst(rv.Dot(c.normal)))
+	if tangent.Magnitude() == 0 {
+		return
+	}
+	tangent = tangent.Normalize()
+	kt := c.effectiveMass(ra, rb, tangent)
+	if kt == 0 {
+		return
+	}
+	jt := -rv.Dot(tangent) / kt
+	mu := math.Sqrt(a.Friction * b.Friction)
+	jt = math.Max(-j*mu, math.Min(jt, j*mu))
+	friction := tangent.MulConst(jt)
+	a.applyImpulse(friction.MulConst(-1), ra)
+	b.applyImpulse(friction, rb)
+}
+
+func (c *contact) effectiveMass(ra, rb, dir floatgeom.Point2) float64 {
+	rna, rnb := cross(ra, dir), cross(rb, dir)
+	return c.a.invMass() + c.b.invMass() +
+		rna*rna*c.a.invInertia() + rnb*rnb*c.b.invInertia()
+}
+
+const (
+	// correctionPercent is how much of the overlap between bodies is removed
+	// each step.
+	correctionPercent = .8
+	// correctionSlop is how much overlap is allowed, so resting bodies do
+	// not jitter.
+	correctionSlop = .01
+)
+
+// correction returns how far each body should move to stop overlapping.
+func (c *contact) correction() (floatgeom.Point2, floatgeom.Point2) {
+	total := c.a.invMass() + c.b.invMass()
+	if total == 0 {
+		return floatgeom.Point2{}, floatgeom.Point2{}
+	}
+	amount := math.Max(c.depth-correctionSlop, 0) / total * correctionPercent
+	push := c.normal.MulConst(amount)
+	return push.MulConst(-c.a.invMass()), push.MulConst(c.b.invMass())
+}
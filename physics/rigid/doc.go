@@ -0,0 +1,3 @@
+// Package rigid provides a small 2D rigid body simulation over collision spaces,
+// with gravity, friction, restitution and impulse based collision resolution.
+package rigid
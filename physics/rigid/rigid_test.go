@@ -0,0 +1,131 @@
+package rigid
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/physics"
+)
+
+func steps(w *World, n int) {
+	for i := 0; i < n; i++ {
+		w.StepOnce()
+	}
+}
+
+func TestBodyRestsOnFloor(t *testing.T) {
+	tree := collision.NewTree()
+	floor := NewBody(collision.NewSpace(-100, 100, 300, 20, 0), Static())
+	box := NewBody(collision.NewSpace(0, 0, 10, 10, 0))
+	tree.Add(floor.Space, box.Space)
+	w := NewWorld(WithGravity(floatgeom.Point2{0, 500}), WithTree(tree))
+	w.Add(floor, box)
+	steps(w, 240)
+	if math.Abs(box.Space.Y()-90) > .5 {
+		t.Fatalf("expected box to rest on floor, got y %v", box.Space.Y())
+	}
+	if !box.Asleep() {
+		t.Fatalf("expected resting box to fall asleep")
+	}
+	if floor.Space.Y() != 100 {
+		t.Fatalf("expected static floor not to move")
+	}
+	if hits := tree.Hits(floor.Space); len(hits) != 1 {
+		t.Fatalf("expected box to have been moved within its tree, got %v", hits)
+	}
+
+	box.ApplyImpulse(floatgeom.Point2{0, -100}, floatgeom.Point2{})
+	if box.Asleep() || box.Velocity.Y() != -100 {
+		t.Fatalf("expected impulse to wake box, got velocity %v", box.Velocity.Y())
+	}
+}
+
+func TestBodyBounces(t *testing.T) {
+	floor := NewBody(collision.NewSpace(-100, 100, 300, 20, 0), Static())
+	ball := NewBody(collision.NewCircleSpace(5, 50, 5, 0), WithRestitution(1), WithVelocity(floatgeom.Point2{0, 120}))
+	w := NewWorld()
+	w.Add(floor, ball)
+	steps(w, 30)
+	if ball.Velocity.Y() >= 0 {
+		t.Fatalf("expected ball to bounce, got velocity %v", ball.Velocity.Y())
+	}
+	if math.Abs(ball.Velocity.Y()+120) > 1 {
+		t.Fatalf("expected ball to keep its speed, got %v", ball.Velocity.Y())
+	}
+}
+
+func TestCirclesExchangeMomentum(t *testing.T) {
+	a := NewBody(collision.NewCircleSpace(0, 0, 5, 0), WithRestitution(1), WithVelocity(floatgeom.Point2{60, 0}))
+	b := NewBody(collision.NewCircleSpace(30, 0, 5, 0), WithRestitution(1))
+	w := NewWorld(WithSleep(0, 0))
+	w.Add(a, b)
+	steps(w, 30)
+	if math.Abs(a.Velocity.X()) > .01 || math.Abs(b.Velocity.X()-60) > .01 {
+		t.Fatalf("expected equal circles to exchange velocity, got %v %v", a.Velocity.X(), b.Velocity.X())
+	}
+}
+
+func TestFrictionSlows(t *testing.T) {
+	slide := func(friction float64) float64 {
+		floor := NewBody(collision.NewSpace(-1000, 10, 3000, 20, 0), Static(), WithFriction(friction))
+		box := NewBody(collision.NewSpace(0, 0, 10, 10, 0), WithFriction(friction), WithVelocity(floatgeom.Point2{100, 0}))
+		w := NewWorld(WithGravity(floatgeom.Point2{0, 500}))
+		w.Add(floor, box)
+		steps(w, 30)
+		return box.Space.X()
+	}
+	if rough, smooth := slide(1), slide(0); rough >= smooth || smooth < 49 {
+		t.Fatalf("expected friction to slow sliding, got %v vs %v", rough, smooth)
+	}
+}
+
+type shifter struct {
+	moved floatgeom.Point2
+	space *collision.Space
+}
+
+func (s *shifter) Shift(delta floatgeom.Point2) {
+	s.moved = s.moved.Add(delta)
+	s.space.Location = s.space.Location.Shift(floatgeom.Point3{delta.X(), delta.Y(), 0})
+}
+
+func TestWorldRun(t *testing.T) {
+	bus := event.NewBus(event.NewCallerMap())
+	sp := collision.NewSpace(0, 0, 10, 10, 0)
+	s := &shifter{space: sp}
+	b := NewBody(sp, WithShifter(s), WithMass(2))
+	w := NewWorld()
+	w.Add(b)
+	b.ApplyForce(physics.NewForceVector(physics.NewVector(1, 0), 120))
+
+	binding := w.Run(bus)
+	<-binding.Bound
+	// Half a step should not advance the world
+	<-event.TriggerOn(bus, event.Enter, event.EnterPayload{SinceLastFrame: w.Step / 2})
+	if s.moved != (floatgeom.Point2{}) {
+		t.Fatalf("expected no movement before a full step, got %v", s.moved)
+	}
+	<-event.TriggerOn(bus, event.Enter, event.EnterPayload{SinceLastFrame: w.Step / 2})
+	if math.Abs(b.Velocity.X()-1) > 1e-6 || math.Abs(s.moved.X()-1.0/60) > 1e-6 {
+		t.Fatalf("expected force to accelerate body over one step, got %v moved %v", b.Velocity.X(), s.moved)
+	}
+	// Long frames are limited to MaxSteps
+	<-event.TriggerOn(bus, event.Enter, event.EnterPayload{SinceLastFrame: time.Second})
+	if math.Abs(s.moved.X()-6.0/60) > 1e-6 {
+		t.Fatalf("expected five more steps, moved %v", s.moved)
+	}
+
+	if err := w.Remove(b); err != nil {
+		t.Fatalf("failed to remove body: %v", err)
+	}
+	if err := w.Remove(b); err == nil {
+		t.Fatalf("expected error removing missing body")
+	}
+	if len(w.Bodies()) != 0 {
+		t.Fatalf("expected world to be empty")
+	}
+}
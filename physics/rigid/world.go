@@ -0,0 +1,265 @@
+package rigid
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/oakerr"
+)
+
+// A World simulates a set of bodies in fixed time steps.
+type World struct {
+	// Gravity accelerates every body, in units per second per second.
+	Gravity floatgeom.Point2
+	// Step is how much time each step of the simulation covers.
+	Step time.Duration
+	// MaxSteps limits how many steps Update will run at once, so a slow
+	// frame does not cause slower frames after it.
+	MaxSteps int
+	// Iterations is how many times each step's contacts are resolved. More
+	// iterations make stacks of bodies more stable.
+	Iterations int
+	// Bodies moving slower than SleepVelocity for SleepTime fall asleep.
+	SleepVelocity float64
+	SleepTime     time.Duration
+	// Tree, if set, is the tree bodies' spaces are moved in. Bodies with a
+	// Shifter are moved by it instead.
+	Tree *collision.Tree
+
+	lock        sync.Mutex
+	bodies      []*Body
+	accumulated time.Duration
+}
+
+// A WorldOption modifies a world.
+type WorldOption func(*World)
+
+// NewWorld returns a world stepped 60 times a second, with no gravity.
+func NewWorld(opts ...WorldOption) *World {
+	w := &World{
+		Step:          time.Second / 60,
+		MaxSteps:      5,
+		Iterations:    8,
+		SleepVelocity: 2,
+		SleepTime:     time.Second / 2,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// WithGravity sets the gravity of a world.
+func WithGravity(g floatgeom.Point2) WorldOption {
+	return func(w *World) {
+		w.Gravity = g
+	}
+}
+
+// WithStep sets how much time each step of a world covers.
+func WithStep(step time.Duration) WorldOption {
+	return func(w *World) {
+		w.Step = step
+	}
+}
+
+// WithIterations sets how many times each step's contacts are resolved.
+func WithIterations(n int) WorldOption {
+	return func(w *World) {
+		w.Iterations = n
+	}
+}
+
+// WithSleep sets how slow, and for how long, bodies must move to fall asleep.
+// A velocity of zero disables sleeping.
+func WithSleep(velocity float64, after time.Duration) WorldOption {
+	return func(w *World) {
+		w.SleepVelocity = velocity
+		w.SleepTime = after
+	}
+}
+
+// WithTree sets the tree a world moves its bodies' spaces in.
+func WithTree(t *collision.Tree) WorldOption {
+	return func(w *World) {
+		w.Tree = t
+	}
+}
+
+// Add adds bodies to the world.
+func (w *World) Add(bodies ...*Body) {
+	w.lock.Lock()
+	w.bodies = append(w.bodies, bodies...)
+	w.lock.Unlock()
+}
+
+// Remove removes a body from the world, or returns an error if it was not
+// in the world.
+func (w *World) Remove(b *Body) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for i, b2 := range w.bodies {
+		if b2 == b {
+			w.bodies = append(w.bodies[:i], w.bodies[i+1:]...)
+			return nil
+		}
+	}
+	return oakerr.NotFound{InputName: "b"}
+}
+
+// Bodies returns the bodies in the world.
+func (w *World) Bodies() []*Body {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	bodies := make([]*Body, len(w.bodies))
+	copy(bodies, w.bodies)
+	return bodies
+}
+
+// Run steps the world each frame of the handler's event.Enter events. Unbind
+// the returned binding to stop it.
+func (w *World) Run(h event.Handler) event.Binding {
+	return event.GlobalBind(h, event.Enter, func(ev event.EnterPayload) event.Response {
+		w.Update(ev.SinceLastFrame)
+		return 0
+	})
+}
+
+// Update advances the world by elapsed time, running as many fixed steps as
+// have elapsed, up to MaxSteps. Time short of a full step is carried over to
+// the next Update.
+func (w *World) Update(elapsed time.Duration) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.Step <= 0 {
+		return
+	}
+	w.accumulated += elapsed
+	for steps := 0; w.accumulated >= w.Step; steps++ {
+		if w.MaxSteps > 0 && steps >= w.MaxSteps {
+			w.accumulated = 0
+			return
+		}
+		w.step(w.Step.Seconds())
+		w.accumulated -= w.Step
+	}
+}
+
+// StepOnce advances the world by exactly one step.
+func (w *World) StepOnce() {
+	w.lock.Lock()
+	w.step(w.Step.Seconds())
+	w.lock.Unlock()
+}
+
+func (w *World) step(dt float64) {
+	for _, b := range w.bodies {
+		if !b.simulated() {
+			b.force, b.torque = floatgeom.Point2{}, 0
+			continue
+		}
+		accel := w.Gravity.MulConst(b.GravityScale).Add(b.force.DivConst(b.GetMass()))
+		v := b.velocity().Add(accel.MulConst(dt))
+		b.Velocity.SetPos(v.X(), v.Y())
+		b.AngularVelocity += b.torque * b.invInertia() * dt
+		b.force, b.torque = floatgeom.Point2{}, 0
+	}
+
+	contacts := w.contacts()
+	for i := 0; i < w.Iterations; i++ {
+		for j := range contacts {
+			contacts[j].solveVelocity()
+		}
+	}
+
+	moves := make(map[*Body]floatgeom.Point2, len(w.bodies))
+	for _, c := range contacts {
+		ma, mb := c.correction()
+		moves[c.a] = moves[c.a].Add(ma)
+		moves[c.b] = moves[c.b].Add(mb)
+	}
+	for _, b := range w.bodies {
+		if !b.simulated() {
+			continue
+		}
+		w.move(b, moves[b].Add(b.velocity().MulConst(dt)))
+		b.Angle += b.AngularVelocity * dt
+		w.updateSleep(b, dt)
+	}
+}
+
+// contacts returns the contacts between bodies, sweeping across the bodies
+// sorted by their left edges to find which could overlap.
+func (w *World) contacts() []contact {
+	sorted := make([]*Body, len(w.bodies))
+	copy(sorted, w.bodies)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Space.Location.Min.X() < sorted[j].Space.Location.Min.X()
+	})
+	contacts := []contact{}
+	for i, a := range sorted {
+		la := a.Space.Location
+		for _, b := range sorted[i+1:] {
+			lb := b.Space.Location
+			if lb.Min.X() > la.Max.X() {
+				break
+			}
+			if lb.Min.Y() > la.Max.Y() || la.Min.Y() > lb.Max.Y() {
+				continue
+			}
+			w.wakes(a, b)
+			if !a.simulated() && !b.simulated() {
+				continue
+			}
+			if c, ok := collide(a, b); ok {
+				contacts = append(contacts, c)
+			}
+		}
+	}
+	return contacts
+}
+
+// wakes wakes either body if it is asleep and the other is moving near it.
+func (w *World) wakes(a, b *Body) {
+	for _, pair := range [2][2]*Body{{a, b}, {b, a}} {
+		sleeper, other := pair[0], pair[1]
+		if sleeper.asleep && other.simulated() && other.velocity().Magnitude() > w.SleepVelocity {
+			sleeper.Wake()
+		}
+	}
+}
+
+func (w *World) updateSleep(b *Body, dt float64) {
+	if w.SleepVelocity <= 0 {
+		return
+	}
+	if b.velocity().Magnitude() > w.SleepVelocity || math.Abs(b.AngularVelocity) > w.SleepVelocity/10 {
+		b.stillTime = 0
+		return
+	}
+	b.stillTime += dt
+	if b.stillTime >= w.SleepTime.Seconds() {
+		b.asleep = true
+		b.Velocity.SetPos(0, 0)
+		b.AngularVelocity = 0
+	}
+}
+
+func (w *World) move(b *Body, delta floatgeom.Point2) {
+	if delta == (floatgeom.Point2{}) {
+		return
+	}
+	if b.Shifter != nil {
+		b.Shifter.Shift(delta)
+		return
+	}
+	if w.Tree != nil && w.Tree.ShiftSpace(delta.X(), delta.Y(), b.Space) == nil {
+		return
+	}
+	b.Space.Location = b.Space.Location.Shift(floatgeom.Point3{delta.X(), delta.Y(), 0})
+}
@@ -0,0 +1,339 @@
+package aseprite
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/fileutil"
+	"github.com/oakmound/oak/v4/oakerr"
+	"github.com/oakmound/oak/v4/render"
+)
+
+// DefaultTag is the key the sequence of every frame is given in switches built
+// from sheets with no tags.
+const DefaultTag = "default"
+
+// A Sheet is an exported Aseprite sprite sheet.
+type Sheet struct {
+	Frames []Frame
+	Tags   []Tag
+	Slices []Slice
+}
+
+// A Frame is one frame of a sheet.
+type Frame struct {
+	// Image is the frame at its untrimmed size.
+	Image    *image.RGBA
+	Duration time.Duration
+}
+
+// A Tag names a range of frames as an animation.
+type Tag struct {
+	Name string
+	// From and To are the indices of the tag's first and last frames.
+	From, To  int
+	Direction render.Direction
+}
+
+// A Slice is a named region of a sheet's frames, such as a hitbox.
+type Slice struct {
+	Name string
+	// Data is the user data set on the slice in Aseprite.
+	Data string
+	// Keys hold the slice's bounds from their frame onward, in frame order.
+	Keys []SliceKey
+}
+
+// A SliceKey sets a slice's bounds from a frame onward.
+type SliceKey struct {
+	Frame  int
+	Bounds floatgeom.Rect2
+	// Pivot is the slice's pivot relative to its bounds, if it has one.
+	Pivot *floatgeom.Point2
+}
+
+// At returns the slice's key for a frame, if the slice is set by that frame.
+func (s Slice) At(frame int) (SliceKey, bool) {
+	var (
+		key   SliceKey
+		found bool
+	)
+	for _, k := range s.Keys {
+		if k.Frame > frame {
+			break
+		}
+		key, found = k, true
+	}
+	return key, found
+}
+
+type jsonRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type jsonFrame struct {
+	Frame            jsonRect `json:"frame"`
+	Rotated          bool     `json:"rotated"`
+	SpriteSourceSize jsonRect `json:"spriteSourceSize"`
+	SourceSize       jsonRect `json:"sourceSize"`
+	Duration         int      `json:"duration"`
+}
+
+type jsonSheet struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image     string `json:"image"`
+		FrameTags []struct {
+			Name      string `json:"name"`
+			From      int    `json:"from"`
+			To        int    `json:"to"`
+			Direction string `json:"direction"`
+		} `json:"frameTags"`
+		Slices []struct {
+			Name string `json:"name"`
+			Data string `json:"data"`
+			Keys []struct {
+				Frame  int       `json:"frame"`
+				Bounds jsonRect  `json:"bounds"`
+				Pivot  *jsonRect `json:"pivot"`
+			} `json:"keys"`
+		} `json:"slices"`
+	} `json:"meta"`
+}
+
+type loader struct {
+	cache *render.Cache
+}
+
+// Option configures how sheets are loaded.
+type Option func(*loader)
+
+// WithCache sets the cache sheet images are loaded through. By default,
+// render.DefaultCache is used.
+func WithCache(c *render.Cache) Option {
+	return func(l *loader) {
+		l.cache = c
+	}
+}
+
+// Load reads a sheet from the JSON Aseprite exports alongside it. The sheet's
+// image is loaded relative to the JSON file.
+func Load(file string, opts ...Option) (*Sheet, error) {
+	f, err := fileutil.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f, filepath.Dir(file), opts...)
+}
+
+// Parse reads a sheet from Aseprite's exported JSON, with frames as either a
+// hash or an array. The sheet's image is loaded relative to dir.
+func Parse(r io.Reader, dir string, opts ...Option) (*Sheet, error) {
+	l := &loader{
+		cache: render.DefaultCache,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	var js jsonSheet
+	if err := json.NewDecoder(r).Decode(&js); err != nil {
+		return nil, err
+	}
+	frames, err := decodeFrames(js.Frames)
+	if err != nil {
+		return nil, err
+	}
+	if js.Meta.Image == "" {
+		return nil, oakerr.InsufficientInputs{AtLeast: 1, InputName: "meta.image"}
+	}
+	sp, err := l.cache.LoadSprite(filepath.Join(dir, js.Meta.Image))
+	if err != nil {
+		return nil, err
+	}
+	rgba := sp.GetRGBA()
+	sh := &Sheet{
+		Frames: make([]Frame, len(frames)),
+	}
+	for i, jf := range frames {
+		if jf.Rotated {
+			return nil, oakerr.UnsupportedFormat{Format: "rotated frames"}
+		}
+		sh.Frames[i] = Frame{
+			Image:    frameImage(rgba, jf),
+			Duration: time.Duration(jf.Duration) * time.Millisecond,
+		}
+	}
+	for _, jt := range js.Meta.FrameTags {
+		if jt.From < 0 || jt.To >= len(frames) || jt.From > jt.To {
+			return nil, oakerr.InvalidInput{InputName: "frameTags"}
+		}
+		tag := Tag{
+			Name: jt.Name,
+			From: jt.From,
+			To:   jt.To,
+		}
+		switch jt.Direction {
+		case "", "forward":
+			tag.Direction = render.Forward
+		case "reverse":
+			tag.Direction = render.Reverse
+		case "pingpong":
+			tag.Direction = render.PingPong
+		case "pingpong_reverse":
+			tag.Direction = render.PingPongReverse
+		default:
+			return nil, oakerr.UnsupportedFormat{Format: jt.Direction}
+		}
+		sh.Tags = append(sh.Tags, tag)
+	}
+	for _, js := range js.Meta.Slices {
+		s := Slice{
+			Name: js.Name,
+			Data: js.Data,
+		}
+		for _, jk := range js.Keys {
+			b := jk.Bounds
+			k := SliceKey{
+				Frame:  jk.Frame,
+				Bounds: floatgeom.NewRect2WH(float64(b.X), float64(b.Y), float64(b.W), float64(b.H)),
+			}
+			if jk.Pivot != nil {
+				k.Pivot = &floatgeom.Point2{float64(jk.Pivot.X), float64(jk.Pivot.Y)}
+			}
+			s.Keys = append(s.Keys, k)
+		}
+		sh.Slices = append(sh.Slices, s)
+	}
+	return sh, nil
+}
+
+// decodeFrames reads frames from an array, or from a hash in the order they
+// are written.
+func decodeFrames(raw json.RawMessage) ([]jsonFrame, error) {
+	var frames []jsonFrame
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, oakerr.InsufficientInputs{AtLeast: 1, InputName: "frames"}
+	}
+	if raw[0] == '[' {
+		if err := json.Unmarshal(raw, &frames); err != nil {
+			return nil, err
+		}
+		return frames, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		// Skip the frame's name.
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		var f jsonFrame
+		if err := dec.Decode(&f); err != nil {
+			return nil, err
+		}
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+// frameImage cuts a frame out of a sheet, restoring any trimmed space around it.
+func frameImage(sheet *image.RGBA, jf jsonFrame) *image.RGBA {
+	w, h := jf.SourceSize.W, jf.SourceSize.H
+	if w == 0 || h == 0 {
+		w, h = jf.Frame.W, jf.Frame.H
+	}
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	off := image.Pt(jf.SpriteSourceSize.X, jf.SpriteSourceSize.Y)
+	for y := 0; y < jf.Frame.H; y++ {
+		for x := 0; x < jf.Frame.W; x++ {
+			out.SetRGBA(off.X+x, off.Y+y, sheet.RGBAAt(jf.Frame.X+x, jf.Frame.Y+y))
+		}
+	}
+	return out
+}
+
+// Tag returns the tag with the given name.
+func (sh *Sheet) Tag(name string) (Tag, bool) {
+	for _, t := range sh.Tags {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Tag{}, false
+}
+
+// Slice returns the slice with the given name.
+func (sh *Sheet) Slice(name string) (Slice, bool) {
+	for _, s := range sh.Slices {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Slice{}, false
+}
+
+// Sequence returns a sequence of the frames from..to, inclusive, each shown
+// for its own duration.
+func (sh *Sheet) Sequence(from, to int, dir render.Direction) (*render.Sequence, error) {
+	if from < 0 || to >= len(sh.Frames) || from > to {
+		return nil, oakerr.InvalidInput{InputName: "from"}
+	}
+	mods := make([]render.Modifiable, 0, to-from+1)
+	durations := make([]time.Duration, 0, to-from+1)
+	for _, f := range sh.Frames[from : to+1] {
+		mods = append(mods, render.NewSprite(0, 0, f.Image))
+		durations = append(durations, f.Duration)
+	}
+	sq := render.NewSequence(0, mods...)
+	if err := sq.SetFrameDurations(durations...); err != nil {
+		return nil, err
+	}
+	sq.SetDirection(dir)
+	return sq, nil
+}
+
+// TagSequence returns a sequence of a tag's frames, played in its direction.
+func (sh *Sheet) TagSequence(name string) (*render.Sequence, error) {
+	t, ok := sh.Tag(name)
+	if !ok {
+		return nil, oakerr.NotFound{InputName: name}
+	}
+	return sh.Sequence(t.From, t.To, t.Direction)
+}
+
+// Switch returns a switch holding a sequence for each tag, keyed by tag name,
+// set to start. Sheets without tags give a switch holding every frame under
+// DefaultTag.
+func (sh *Sheet) Switch(start string) (*render.Switch, error) {
+	m := map[string]render.Modifiable{}
+	if len(sh.Tags) == 0 {
+		sq, err := sh.Sequence(0, len(sh.Frames)-1, render.Forward)
+		if err != nil {
+			return nil, err
+		}
+		m[DefaultTag] = sq
+	}
+	for _, t := range sh.Tags {
+		sq, err := sh.Sequence(t.From, t.To, t.Direction)
+		if err != nil {
+			return nil, err
+		}
+		m[t.Name] = sq
+	}
+	if _, ok := m[start]; !ok {
+		return nil, oakerr.NotFound{InputName: start}
+	}
+	return render.NewSwitch(start, m), nil
+}
@@ -0,0 +1,117 @@
+package aseprite
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/render"
+)
+
+var (
+	red   = color.RGBA{255, 0, 0, 255}
+	green = color.RGBA{0, 255, 0, 255}
+	blue  = color.RGBA{0, 0, 255, 255}
+	white = color.RGBA{255, 255, 255, 255}
+)
+
+func TestLoad(t *testing.T) {
+	sh, err := Load("testdata/hero.json", WithCache(render.NewCache()))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(sh.Frames) != 4 {
+		t.Fatalf("expected 4 frames, got %v", len(sh.Frames))
+	}
+	for i, c := range []color.RGBA{red, green, blue} {
+		if sh.Frames[i].Image.RGBAAt(0, 0) != c {
+			t.Fatalf("frame %v has the wrong image", i)
+		}
+	}
+	if sh.Frames[1].Duration != 200*time.Millisecond {
+		t.Fatalf("expected 200ms frame, got %v", sh.Frames[1].Duration)
+	}
+	trimmed := sh.Frames[3].Image
+	if trimmed.Bounds().Dx() != 8 || trimmed.RGBAAt(0, 0) != (color.RGBA{}) ||
+		trimmed.RGBAAt(2, 3) != white || trimmed.RGBAAt(5, 6) != white {
+		t.Fatalf("trimmed frame should be restored to its source size")
+	}
+
+	walk, ok := sh.Tag("walk")
+	if !ok || walk.From != 1 || walk.To != 3 || walk.Direction != render.PingPong {
+		t.Fatalf("bad walk tag: %+v", walk)
+	}
+	hitbox, ok := sh.Slice("hitbox")
+	if !ok || hitbox.Data != "solid" {
+		t.Fatalf("bad hitbox slice: %+v", hitbox)
+	}
+	if k, ok := hitbox.At(1); !ok || k.Bounds != floatgeom.NewRect2WH(1, 1, 6, 7) || k.Pivot != nil {
+		t.Fatalf("frame 1 should use the first hitbox key, got %+v", k)
+	}
+	if k, ok := hitbox.At(3); !ok || k.Bounds != floatgeom.NewRect2WH(2, 1, 4, 7) || *k.Pivot != (floatgeom.Point2{2, 7}) {
+		t.Fatalf("frame 3 should use the second hitbox key, got %+v", k)
+	}
+	if _, ok := (Slice{Keys: []SliceKey{{Frame: 2}}}).At(1); ok {
+		t.Fatalf("slices should not be set before their first key")
+	}
+
+	sw, err := sh.Switch("idle")
+	if err != nil {
+		t.Fatalf("switch failed: %v", err)
+	}
+	if sw.Get() != "idle" {
+		t.Fatalf("switch should start on idle")
+	}
+	sq, ok := sw.GetSub("walk").(*render.Sequence)
+	if !ok {
+		t.Fatalf("walk should be a sequence")
+	}
+	if sq.Get(0).GetRGBA().RGBAAt(0, 0) != green || sq.Get(2).GetRGBA().RGBAAt(2, 3) != white {
+		t.Fatalf("walk should hold frames 1 through 3")
+	}
+	if _, err := sh.Switch("run"); err == nil {
+		t.Fatalf("expected missing start tag to fail")
+	}
+	if _, err := sh.TagSequence("run"); err == nil {
+		t.Fatalf("expected missing tag to fail")
+	}
+	if _, err := sh.Sequence(2, 4, render.Forward); err == nil {
+		t.Fatalf("expected out of range frames to fail")
+	}
+}
+
+func TestLoadArray(t *testing.T) {
+	sh, err := Load("testdata/hero_array.json", WithCache(render.NewCache()))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(sh.Frames) != 2 || sh.Frames[1].Image.RGBAAt(0, 0) != green {
+		t.Fatalf("array frames loaded incorrectly")
+	}
+	sw, err := sh.Switch(DefaultTag)
+	if err != nil {
+		t.Fatalf("untagged sheets should switch to every frame: %v", err)
+	}
+	if sq := sw.GetSub(DefaultTag).(*render.Sequence); sq.Get(1) == nil {
+		t.Fatalf("default sequence should hold every frame")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tcs := map[string]string{
+		"bad json":     `{`,
+		"no frames":    `{"meta": {"image": "hero.png"}}`,
+		"no image":     `{"frames": []}`,
+		"bad tag":      `{"frames": [], "meta": {"image": "hero.png", "frameTags": [{"name": "a", "from": 0, "to": 1}]}}`,
+		"missing file": `{"frames": [], "meta": {"image": "missing.png"}}`,
+		"direction": `{"frames": [{"frame": {"w": 1, "h": 1}}], "meta": {"image": "hero.png",
+			"frameTags": [{"name": "a", "from": 0, "to": 0, "direction": "sideways"}]}}`,
+	}
+	for name, js := range tcs {
+		if _, err := Parse(strings.NewReader(js), "testdata"); err == nil {
+			t.Fatalf("%v: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+// Package aseprite imports sprite sheets exported from Aseprite as a PNG and
+// JSON data, building sequences and switches from their frames and tags.
+package aseprite
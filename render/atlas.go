@@ -0,0 +1,177 @@
+package render
+
+import (
+	"image"
+	"image/draw"
+	"sync"
+
+	"github.com/oakmound/oak/v4/oakerr"
+)
+
+// An Atlas packs many images into a few large pages, so that small sprites
+// share memory rather than each holding their own. Images are placed with a
+// skyline bottom-left packer, and new pages are added as earlier pages fill.
+type Atlas struct {
+	lock         sync.Mutex
+	pageW, pageH int
+	pages        []*atlasPage
+}
+
+type atlasPage struct {
+	rgba *image.RGBA
+	// skyline holds the top edge of the images packed so far, left to right.
+	skyline []skylineSegment
+}
+
+type skylineSegment struct {
+	x, y, w int
+}
+
+// NewAtlas returns an empty atlas whose pages are w by h pixels.
+func NewAtlas(w, h int) (*Atlas, error) {
+	if w <= 0 {
+		return nil, oakerr.InvalidInput{InputName: "w"}
+	}
+	if h <= 0 {
+		return nil, oakerr.InvalidInput{InputName: "h"}
+	}
+	return &Atlas{
+		pageW: w,
+		pageH: h,
+	}, nil
+}
+
+// Pack copies an image into the atlas, returning a view of it within its page.
+// The view shares its pixels with the page, so drawing to it changes only its
+// own part of the page. Images larger than a page cannot be packed.
+func (a *Atlas) Pack(img image.Image) (*image.RGBA, error) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > a.pageW || h > a.pageH {
+		return nil, oakerr.InvalidInput{InputName: "img"}
+	}
+	if w == 0 || h == 0 {
+		return image.NewRGBA(image.Rect(0, 0, w, h)), nil
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	var (
+		pg *atlasPage
+		at image.Point
+		ok bool
+	)
+	for _, pg = range a.pages {
+		if at, ok = pg.insert(w, h); ok {
+			break
+		}
+	}
+	if !ok {
+		pg = &atlasPage{
+			rgba:    image.NewRGBA(image.Rect(0, 0, a.pageW, a.pageH)),
+			skyline: []skylineSegment{{w: a.pageW}},
+		}
+		a.pages = append(a.pages, pg)
+		at, _ = pg.insert(w, h)
+	}
+	r := image.Rectangle{Min: at, Max: at.Add(image.Pt(w, h))}
+	draw.Draw(pg.rgba, r, img, b.Min, draw.Src)
+	return view(pg.rgba, r), nil
+}
+
+// Pages returns the atlas' pages.
+func (a *Atlas) Pages() []*image.RGBA {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	pages := make([]*image.RGBA, len(a.pages))
+	for i, pg := range a.pages {
+		pages[i] = pg.rgba
+	}
+	return pages
+}
+
+// Reset empties the atlas. Views already packed keep their pixels.
+func (a *Atlas) Reset() {
+	a.lock.Lock()
+	a.pages = nil
+	a.lock.Unlock()
+}
+
+// view returns an image of part of rgba which shares its pixels but, as
+// sprites expect, has its bounds at the origin. Between its rows, its Pix holds
+// the rest of rgba's rows, so it must be read through its Stride.
+func view(rgba *image.RGBA, r image.Rectangle) *image.RGBA {
+	if r.Empty() {
+		return image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	}
+	start := rgba.PixOffset(r.Min.X, r.Min.Y)
+	end := rgba.PixOffset(r.Max.X-1, r.Max.Y-1) + 4
+	return &image.RGBA{
+		Pix:    rgba.Pix[start:end:end],
+		Stride: rgba.Stride,
+		Rect:   image.Rect(0, 0, r.Dx(), r.Dy()),
+	}
+}
+
+// insert finds room for a w by h image, preferring the position nearest the
+// top of the page, and raises the skyline over it.
+func (pg *atlasPage) insert(w, h int) (image.Point, bool) {
+	pageW, pageH := pg.rgba.Rect.Dx(), pg.rgba.Rect.Dy()
+	best, bestY, bestX := -1, pageH+1, pageW+1
+	for i, seg := range pg.skyline {
+		if seg.x+w > pageW {
+			break
+		}
+		y, ok := pg.fit(i, w, h, pageH)
+		if ok && (y < bestY || (y == bestY && seg.x < bestX)) {
+			best, bestY, bestX = i, y, seg.x
+		}
+	}
+	if best == -1 {
+		return image.Point{}, false
+	}
+	pg.raise(best, bestX, bestY+h, w)
+	return image.Pt(bestX, bestY), true
+}
+
+// fit returns how low an image w wide can rest on the skyline from segment i.
+func (pg *atlasPage) fit(i, w, h, pageH int) (int, bool) {
+	y := 0
+	for left := w; left > 0; i++ {
+		if i >= len(pg.skyline) {
+			return 0, false
+		}
+		if pg.skyline[i].y > y {
+			y = pg.skyline[i].y
+		}
+		left -= pg.skyline[i].w
+	}
+	return y, y+h <= pageH
+}
+
+// raise sets the skyline to y from x for w pixels, starting at segment i.
+func (pg *atlasPage) raise(i, x, y, w int) {
+	sky := append([]skylineSegment{}, pg.skyline[:i]...)
+	sky = append(sky, skylineSegment{x: x, y: y, w: w})
+	for _, seg := range pg.skyline[i:] {
+		end := seg.x + seg.w
+		if end <= x+w {
+			continue
+		}
+		if seg.x < x+w {
+			seg.w = end - (x + w)
+			seg.x = x + w
+		}
+		sky = append(sky, seg)
+	}
+	// Merge neighbors at the same height.
+	merged := sky[:1]
+	for _, seg := range sky[1:] {
+		last := &merged[len(merged)-1]
+		if last.y == seg.y {
+			last.w += seg.w
+			continue
+		}
+		merged = append(merged, seg)
+	}
+	pg.skyline = merged
+}
@@ -0,0 +1,172 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/oakmound/oak/v4/alg/intgeom"
+)
+
+func TestNewAtlas(t *testing.T) {
+	if _, err := NewAtlas(0, 10); err == nil {
+		t.Fatalf("expected zero width to fail")
+	}
+	if _, err := NewAtlas(10, -1); err == nil {
+		t.Fatalf("expected negative height to fail")
+	}
+}
+
+func TestAtlasPack(t *testing.T) {
+	a, err := NewAtlas(64, 64)
+	if err != nil {
+		t.Fatalf("new atlas failed: %v", err)
+	}
+	if _, err := a.Pack(image.NewRGBA(image.Rect(0, 0, 65, 1))); err == nil {
+		t.Fatalf("expected image wider than a page to fail")
+	}
+	rng := rand.New(rand.NewSource(1))
+	type packed struct {
+		view *image.RGBA
+		c    color.RGBA
+	}
+	var all []packed
+	for i := 0; i < 100; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 1+rng.Intn(16), 1+rng.Intn(16)))
+		c := color.RGBA{uint8(i), uint8(i * 7), uint8(i * 13), 255}
+		for j := 0; j < len(img.Pix); j += 4 {
+			copy(img.Pix[j:], []uint8{c.R, c.G, c.B, c.A})
+		}
+		v, err := a.Pack(img)
+		if err != nil {
+			t.Fatalf("pack %v failed: %v", i, err)
+		}
+		if v.Bounds() != img.Bounds() {
+			t.Fatalf("view bounds %v should match image bounds %v", v.Bounds(), img.Bounds())
+		}
+		all = append(all, packed{v, c})
+	}
+	if len(a.Pages()) < 2 {
+		t.Fatalf("expected images to fill more than one page")
+	}
+	// Every view should still hold only its own color, so none overlap.
+	for i, p := range all {
+		b := p.view.Bounds()
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				if p.view.RGBAAt(x, y) != p.c {
+					t.Fatalf("image %v was overwritten at %v,%v", i, x, y)
+				}
+			}
+		}
+	}
+	// Copies hold only their own pixels, without their neighbors on the page.
+	for i, p := range all {
+		cp := NewSprite(0, 0, p.view).Copy().GetRGBA()
+		b := cp.Bounds()
+		if len(cp.Pix) != b.Dx()*b.Dy()*4 || cp.Stride != b.Dx()*4 {
+			t.Fatalf("copy of image %v should be tightly packed", i)
+		}
+		for j := 0; j < len(cp.Pix); j += 4 {
+			if c := (color.RGBA{cp.Pix[j], cp.Pix[j+1], cp.Pix[j+2], cp.Pix[j+3]}); c != p.c {
+				t.Fatalf("copy of image %v holds another image's pixels", i)
+			}
+		}
+	}
+	// The first image packed sits at the top left of the first page.
+	all[0].view.SetRGBA(0, 0, color.RGBA{})
+	if a.Pages()[0].RGBAAt(0, 0) != (color.RGBA{}) {
+		t.Fatalf("views should share pixels with their page")
+	}
+	a.Reset()
+	if len(a.Pages()) != 0 {
+		t.Fatalf("reset should empty the atlas")
+	}
+}
+
+func TestCacheSetAtlas(t *testing.T) {
+	c := NewCache()
+	a, _ := NewAtlas(256, 256)
+	c.SetAtlas(a)
+	sp, err := c.LoadSprite("testdata/assets/images/16x16/jeremy.png")
+	if err != nil {
+		t.Fatalf("load sprite failed: %v", err)
+	}
+	if len(a.Pages()) != 1 {
+		t.Fatalf("loaded sprite should be packed")
+	}
+	if w, h := sp.GetDims(); w != 128 || h != 128 {
+		t.Fatalf("packed sprite has wrong dims %v,%v", w, h)
+	}
+	sh, err := c.LoadSheet("testdata/assets/images/16x16/jeremy.png", intgeom.Point2{16, 16})
+	if err != nil {
+		t.Fatalf("load sheet failed: %v", err)
+	}
+	cell := (*sh)[1][1]
+	if cell.Bounds() != image.Rect(0, 0, 16, 16) {
+		t.Fatalf("sheet cell has wrong bounds %v", cell.Bounds())
+	}
+	cell.SetRGBA(0, 0, color.RGBA{1, 2, 3, 4})
+	found := false
+	for _, pg := range a.Pages() {
+		for y := 0; y < 256; y++ {
+			for x := 0; x < 256; x++ {
+				if pg.RGBAAt(x, y) == (color.RGBA{1, 2, 3, 4}) {
+					found = true
+				}
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("sheet cells should be views into the atlas")
+	}
+	c.ClearAll()
+	if len(a.Pages()) != 0 {
+		t.Fatalf("clearing the cache should reset its atlas")
+	}
+}
+
+func TestCacheLoadAtlas(t *testing.T) {
+	c := NewCache()
+	names, err := c.LoadAtlas("testdata/atlas/atlas.json")
+	if err != nil {
+		t.Fatalf("load atlas failed: %v", err)
+	}
+	if len(names) != 3 || names[0] != "ui/a.png" || names[2] != "ui/c.png" {
+		t.Fatalf("unexpected frame names %v", names)
+	}
+	a, err := c.GetSprite("a.png")
+	if err != nil {
+		t.Fatalf("frames should be cached by base name: %v", err)
+	}
+	if w, h := a.GetDims(); w != 4 || h != 4 || a.GetRGBA().RGBAAt(3, 3) != (color.RGBA{255, 0, 0, 255}) {
+		t.Fatalf("frame a loaded incorrectly")
+	}
+	b, err := c.GetSprite("ui/b.png")
+	if err != nil {
+		t.Fatalf("frames should be cached by name: %v", err)
+	}
+	if w, h := b.GetDims(); w != 4 || h != 2 {
+		t.Fatalf("rotated frame should be turned back, got %v,%v", w, h)
+	}
+	if b.GetRGBA().RGBAAt(0, 0) != (color.RGBA{0, 0, 255, 255}) || b.GetRGBA().RGBAAt(3, 1) != (color.RGBA{0, 255, 0, 255}) {
+		t.Fatalf("rotated frame has the wrong orientation")
+	}
+	cs, _ := c.GetSprite("c.png")
+	if w, h := cs.GetDims(); w != 4 || h != 4 || cs.GetRGBA().RGBAAt(0, 0) != (color.RGBA{}) ||
+		cs.GetRGBA().RGBAAt(1, 1) != (color.RGBA{255, 255, 255, 255}) {
+		t.Fatalf("trimmed frame should be restored to its source size")
+	}
+
+	names, err = c.LoadAtlas("testdata/atlas/array.json")
+	if err != nil || len(names) != 1 || names[0] != "d.png" {
+		t.Fatalf("load array atlas failed: %v %v", names, err)
+	}
+	if _, err := c.LoadAtlas("testdata/atlas/missing.json"); err == nil {
+		t.Fatalf("expected missing atlas to fail")
+	}
+	if _, err := c.LoadAtlas("testdata/atlas/outside.json"); err == nil {
+		t.Fatalf("expected frames outside the page to fail")
+	}
+}
@@ -0,0 +1,126 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// A BlendMode is how a renderable's pixels are combined with the pixels
+// already drawn beneath them.
+type BlendMode uint8
+
+// BlendModes. Each works on premultiplied colors, as image.RGBA holds them.
+const (
+	// BlendOver draws pixels over those beneath by their alpha, as draw.Over
+	// does. It is the default.
+	BlendOver BlendMode = iota
+	// BlendAdd adds pixels to those beneath, brightening them, for glows and
+	// lights.
+	BlendAdd
+	// BlendMultiply multiplies pixels with those beneath, darkening them, for
+	// shadows and tints.
+	BlendMultiply
+	// BlendScreen inverts, multiplies and inverts again, brightening pixels
+	// beneath without saturating as quickly as BlendAdd.
+	BlendScreen
+	// BlendSubtract subtracts pixels from those beneath, keeping the alpha
+	// beneath.
+	BlendSubtract
+	// BlendReplace replaces pixels beneath, alpha included, as draw.Src does.
+	BlendReplace
+)
+
+// Blendable types can be drawn with a BlendMode.
+type Blendable interface {
+	SetBlendMode(BlendMode)
+	GetBlendMode() BlendMode
+}
+
+// DrawImageBlend draws img to buff at x, y in the given blend mode. Drawing
+// to *image.RGBA buffers does not allocate.
+func DrawImageBlend(buff draw.Image, img *image.RGBA, x, y int, mode BlendMode) {
+	switch mode {
+	case BlendOver:
+		DrawImage(buff, img, x, y)
+		return
+	case BlendReplace:
+		OverwriteImage(buff, img, x, y)
+		return
+	}
+	src := img.Bounds()
+	dst := src.Sub(src.Min).Add(image.Pt(x, y)).Intersect(buff.Bounds())
+	for j := dst.Min.Y; j < dst.Max.Y; j++ {
+		for i := dst.Min.X; i < dst.Max.X; i++ {
+			c := img.RGBAAt(src.Min.X+i-x, src.Min.Y+j-y)
+			setBlended(buff, i, j, c, mode)
+		}
+	}
+}
+
+// BlendAt blends c into the pixel of buff at x, y in the given blend mode.
+func BlendAt(buff draw.Image, x, y int, c color.Color, mode BlendMode) {
+	if !(image.Point{x, y}).In(buff.Bounds()) {
+		return
+	}
+	r, g, b, a := c.RGBA()
+	setBlended(buff, x, y, color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}, mode)
+}
+
+// setBlended blends c into the pixel of buff at x, y, which must be within
+// buff.
+func setBlended(buff draw.Image, x, y int, c color.RGBA, mode BlendMode) {
+	if c.A == 0 && mode != BlendReplace {
+		return
+	}
+	if rgba, ok := buff.(*image.RGBA); ok {
+		blendPixel(rgba.Pix[rgba.PixOffset(x, y):], c, mode)
+		return
+	}
+	r, g, b, a := buff.At(x, y).RGBA()
+	out := [4]uint8{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+	blendPixel(out[:], c, mode)
+	buff.Set(x, y, color.RGBA{out[0], out[1], out[2], out[3]})
+}
+
+// blendPixel blends premultiplied c into the pixel at the start of dst.
+func blendPixel(dst []uint8, c color.RGBA, mode BlendMode) {
+	src := [4]uint32{uint32(c.R), uint32(c.G), uint32(c.B), uint32(c.A)}
+	sa, da := src[3], uint32(dst[3])
+	for k := 0; k < 4; k++ {
+		s, d := src[k], uint32(dst[k])
+		var out uint32
+		switch mode {
+		case BlendOver:
+			out = s + mul255(d, 255-sa)
+		case BlendAdd:
+			out = s + d
+		case BlendMultiply:
+			out = mul255(s, d) + mul255(s, 255-da) + mul255(d, 255-sa)
+			if k == 3 {
+				out = sa + da - mul255(sa, da)
+			}
+		case BlendScreen:
+			out = s + d - mul255(s, d)
+		case BlendSubtract:
+			out = d - s
+			if s > d {
+				out = 0
+			}
+			if k == 3 {
+				out = d
+			}
+		case BlendReplace:
+			out = s
+		}
+		if out > 255 {
+			out = 255
+		}
+		dst[k] = uint8(out)
+	}
+}
+
+// mul255 multiplies two 0-255 values as fractions of 255.
+func mul255(a, b uint32) uint32 {
+	return (a*b + 127) / 255
+}
@@ -0,0 +1,114 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestBlendPixel(t *testing.T) {
+	dst := color.RGBA{100, 200, 0, 255}
+	half := color.RGBA{64, 0, 64, 128}
+	tcs := []struct {
+		mode     BlendMode
+		src      color.RGBA
+		expected color.RGBA
+	}{
+		{BlendOver, half, color.RGBA{114, 100, 64, 255}},
+		{BlendAdd, half, color.RGBA{164, 200, 64, 255}},
+		{BlendMultiply, color.RGBA{255, 128, 0, 255}, color.RGBA{100, 100, 0, 255}},
+		{BlendScreen, color.RGBA{255, 128, 0, 255}, color.RGBA{255, 228, 0, 255}},
+		{BlendSubtract, color.RGBA{50, 255, 0, 255}, color.RGBA{50, 0, 0, 255}},
+		{BlendReplace, half, half},
+	}
+	for _, tc := range tcs {
+		px := []uint8{dst.R, dst.G, dst.B, dst.A}
+		blendPixel(px, tc.src, tc.mode)
+		if got := (color.RGBA{px[0], px[1], px[2], px[3]}); got != tc.expected {
+			t.Fatalf("mode %v: expected %v, got %v", tc.mode, tc.expected, got)
+		}
+	}
+}
+
+func TestBlendModes(t *testing.T) {
+	gray := color.RGBA{100, 100, 100, 255}
+	fill := func(img *image.RGBA, c color.RGBA) *image.RGBA {
+		for i := 0; i < len(img.Pix); i += 4 {
+			copy(img.Pix[i:], []uint8{c.R, c.G, c.B, c.A})
+		}
+		return img
+	}
+	background := func() *image.RGBA {
+		return fill(image.NewRGBA(image.Rect(0, 0, 4, 4)), gray)
+	}
+	light := func() *image.RGBA {
+		return fill(image.NewRGBA(image.Rect(0, 0, 2, 2)), color.RGBA{50, 0, 0, 255})
+	}
+	brighter := color.RGBA{150, 100, 100, 255}
+
+	sp := NewSprite(1, 1, light())
+	sp.SetBlendMode(BlendAdd)
+	if sp.GetBlendMode() != BlendAdd || sp.Copy().(*Sprite).GetBlendMode() != BlendAdd {
+		t.Fatalf("sprite should keep its blend mode")
+	}
+	buff := background()
+	sp.Draw(buff, 0, 0)
+	if buff.RGBAAt(1, 1) != brighter || buff.RGBAAt(0, 0) != gray || buff.RGBAAt(3, 3) != gray {
+		t.Fatalf("sprite should draw added")
+	}
+	buff = background()
+	sp.SetTransform(&Transform{})
+	sp.Draw(buff, 0, 0)
+	if buff.RGBAAt(2, 2) != brighter {
+		t.Fatalf("transformed sprite should draw added")
+	}
+
+	sq := NewSequence(0, NewSprite(1, 0, light()))
+	sq.SetBlendMode(BlendAdd)
+	buff = background()
+	sq.Draw(buff, 0, 1)
+	if buff.RGBAAt(1, 1) != brighter || buff.RGBAAt(0, 1) != gray {
+		t.Fatalf("sequence should draw added")
+	}
+
+	plain := NewSprite(0, 0, light())
+	cs := NewCompositeR(plain)
+	cs.SetBlendMode(BlendAdd)
+	buff = background()
+	cs.Draw(buff, 0, 0)
+	if buff.RGBAAt(0, 0) != brighter || plain.GetBlendMode() != BlendOver {
+		t.Fatalf("composite should draw its children added")
+	}
+	plain.SetBlendMode(BlendReplace)
+	buff = background()
+	cs.Draw(buff, 0, 0)
+	if buff.RGBAAt(0, 0) != (color.RGBA{50, 0, 0, 255}) {
+		t.Fatalf("children's own blend modes should be kept")
+	}
+
+	// Children are blended without their own blend modes being changed.
+	plain.SetBlendMode(BlendOver)
+	seq := NewSequence(0, NewSprite(2, 0, light()))
+	nested := NewCompositeR(NewSwitch("a", map[string]Modifiable{"a": NewReverting(NewSprite(0, 2, light()))}))
+	cs = NewCompositeR(plain, seq, nested)
+	cs.SetBlendMode(BlendAdd)
+	buff = background()
+	cs.Draw(buff, 0, 0)
+	if buff.RGBAAt(1, 1) != brighter || buff.RGBAAt(3, 1) != brighter || buff.RGBAAt(1, 3) != brighter || buff.RGBAAt(3, 3) != gray {
+		t.Fatalf("composite should draw nested children added")
+	}
+	if plain.GetBlendMode() != BlendOver || seq.GetBlendMode() != BlendOver || nested.GetBlendMode() != BlendOver {
+		t.Fatalf("drawing a composite should not change its children's blend modes")
+	}
+
+	nrgba := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < len(nrgba.Pix); i += 4 {
+		copy(nrgba.Pix[i:], []uint8{100, 100, 100, 255})
+	}
+	DrawImageBlend(nrgba, light(), 0, 0, BlendAdd)
+	BlendAt(nrgba, 3, 3, color.RGBA{50, 0, 0, 255}, BlendAdd)
+	BlendAt(nrgba, 9, 9, color.RGBA{50, 0, 0, 255}, BlendAdd)
+	if nrgba.NRGBAAt(0, 0) != (color.NRGBA{150, 100, 100, 255}) || nrgba.NRGBAAt(3, 3) != (color.NRGBA{150, 100, 100, 255}) {
+		t.Fatalf("blending into non-RGBA images failed")
+	}
+}
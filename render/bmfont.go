@@ -0,0 +1,398 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"image"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/oakmound/oak/v4/fileutil"
+	"github.com/oakmound/oak/v4/oakerr"
+)
+
+// A BMFont is a bitmap font in the AngelCode BMFont format, whose glyphs are
+// cut from page images rather than rasterized. BMFonts satisfy font.Face, and
+// fonts are generated from them by FontGenerators whose File ends in .fnt.
+type BMFont struct {
+	// Face is the name of the font the BMFont was made from.
+	Face string
+	// Size is the size the BMFont was made at.
+	Size int
+	// LineHeight is the distance from one line of text to the next.
+	LineHeight int
+	// Base is the distance from the top of a line to its baseline.
+	Base     int
+	Pages    []*image.RGBA
+	Chars    map[rune]BMChar
+	Kernings map[[2]rune]int
+}
+
+// A BMChar is where a glyph is in a BMFont's pages and how it is placed.
+type BMChar struct {
+	X, Y, Width, Height int
+	XOffset, YOffset    int
+	XAdvance            int
+	Page                int
+}
+
+// LoadBMFont loads a BMFont in the text, XML or binary formats, and the page
+// images it refers to, relative to it. It is cached under its full path and
+// its final path element.
+func (c *Cache) LoadBMFont(file string) (*BMFont, error) {
+	data, err := fileutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	bm, err := loadBMFont(data, filepath.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+	c.fontLock.Lock()
+	c.loadedBMFonts[file] = bm
+	c.loadedBMFonts[filepath.Base(file)] = bm
+	c.fontLock.Unlock()
+	return bm, nil
+}
+
+// loadBMFont parses a BMFont and loads its pages from dir.
+func loadBMFont(data []byte, dir string) (*BMFont, error) {
+	bm, pages, err := parseBMFont(data)
+	if err != nil {
+		return nil, err
+	}
+	bm.Pages = make([]*image.RGBA, len(pages))
+	for i, page := range pages {
+		if bm.Pages[i], err = loadSpriteNoCache(filepath.Join(dir, page), 0); err != nil {
+			return nil, err
+		}
+	}
+	for r, ch := range bm.Chars {
+		if ch.Page < 0 || ch.Page >= len(bm.Pages) {
+			return nil, oakerr.InvalidInput{InputName: "char " + string(r)}
+		}
+	}
+	return bm, nil
+}
+
+// GetBMFont returns a cached BMFont, or an error if it is not cached.
+func (c *Cache) GetBMFont(file string) (*BMFont, error) {
+	c.fontLock.RLock()
+	bm, ok := c.loadedBMFonts[file]
+	c.fontLock.RUnlock()
+	if !ok {
+		return nil, oakerr.NotFound{InputName: "file"}
+	}
+	return bm, nil
+}
+
+// isBMFont reports whether data looks like a BMFont in any of its formats,
+// rather than a TrueType font.
+func isBMFont(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return bytes.HasPrefix(data, []byte("BMF")) ||
+		bytes.HasPrefix(trimmed, []byte("<?xml")) ||
+		bytes.HasPrefix(trimmed, []byte("<font")) ||
+		bytes.HasPrefix(trimmed, []byte("info "))
+}
+
+// parseBMFont parses a BMFont, returning it and the files of its pages.
+func parseBMFont(data []byte) (*BMFont, []string, error) {
+	bm := &BMFont{
+		Chars:    make(map[rune]BMChar),
+		Kernings: make(map[[2]rune]int),
+	}
+	var (
+		pages []string
+		err   error
+	)
+	switch trimmed := bytes.TrimSpace(data); {
+	case bytes.HasPrefix(data, []byte("BMF")):
+		pages, err = bm.parseBinary(data)
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		pages, err = bm.parseXML(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("info")):
+		pages, err = bm.parseText(trimmed)
+	default:
+		err = oakerr.UnsupportedFormat{Format: "bmfont"}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return bm, pages, nil
+}
+
+func (bm *BMFont) parseText(data []byte) ([]string, error) {
+	pages := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		tag, attrs := bmTextLine(scanner.Text())
+		var err error
+		// atoi reads an int attribute, keeping the first error. Missing
+		// attributes are 0.
+		atoi := func(name string) int {
+			str, ok := attrs[name]
+			if !ok || err != nil {
+				return 0
+			}
+			v, convErr := strconv.Atoi(str)
+			if convErr != nil {
+				err = oakerr.InvalidInput{InputName: tag + "." + name}
+			}
+			return v
+		}
+		switch tag {
+		case "info":
+			bm.Face = attrs["face"]
+			bm.Size = atoi("size")
+		case "common":
+			bm.LineHeight = atoi("lineHeight")
+			bm.Base = atoi("base")
+		case "page":
+			id := atoi("id")
+			for len(pages) <= id && err == nil {
+				pages = append(pages, "")
+			}
+			if err == nil {
+				pages[id] = attrs["file"]
+			}
+		case "char":
+			bm.Chars[rune(atoi("id"))] = BMChar{
+				X:        atoi("x"),
+				Y:        atoi("y"),
+				Width:    atoi("width"),
+				Height:   atoi("height"),
+				XOffset:  atoi("xoffset"),
+				YOffset:  atoi("yoffset"),
+				XAdvance: atoi("xadvance"),
+				Page:     atoi("page"),
+			}
+		case "kerning":
+			bm.Kernings[[2]rune{rune(atoi("first")), rune(atoi("second"))}] = atoi("amount")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pages, scanner.Err()
+}
+
+// bmTextLine splits a line of a text BMFont into its tag and attributes.
+func bmTextLine(line string) (string, map[string]string) {
+	line = strings.TrimSpace(line)
+	tag := line
+	if i := strings.IndexByte(line, ' '); i != -1 {
+		tag, line = line[:i], line[i+1:]
+	} else {
+		line = ""
+	}
+	attrs := map[string]string{}
+	for line = strings.TrimSpace(line); line != ""; line = strings.TrimSpace(line) {
+		eq := strings.IndexByte(line, '=')
+		if eq == -1 {
+			break
+		}
+		key := line[:eq]
+		line = line[eq+1:]
+		var value string
+		if strings.HasPrefix(line, `"`) {
+			end := strings.IndexByte(line[1:], '"')
+			if end == -1 {
+				end = len(line) - 1
+			}
+			value, line = line[1:end+1], line[end+2:]
+		} else if sp := strings.IndexByte(line, ' '); sp != -1 {
+			value, line = line[:sp], line[sp+1:]
+		} else {
+			value, line = line, ""
+		}
+		attrs[key] = value
+	}
+	return tag, attrs
+}
+
+func (bm *BMFont) parseXML(data []byte) ([]string, error) {
+	var xf struct {
+		Info struct {
+			Face string `xml:"face,attr"`
+			Size int    `xml:"size,attr"`
+		} `xml:"info"`
+		Common struct {
+			LineHeight int `xml:"lineHeight,attr"`
+			Base       int `xml:"base,attr"`
+		} `xml:"common"`
+		Pages []struct {
+			ID   int    `xml:"id,attr"`
+			File string `xml:"file,attr"`
+		} `xml:"pages>page"`
+		Chars []struct {
+			ID       rune `xml:"id,attr"`
+			X        int  `xml:"x,attr"`
+			Y        int  `xml:"y,attr"`
+			Width    int  `xml:"width,attr"`
+			Height   int  `xml:"height,attr"`
+			XOffset  int  `xml:"xoffset,attr"`
+			YOffset  int  `xml:"yoffset,attr"`
+			XAdvance int  `xml:"xadvance,attr"`
+			Page     int  `xml:"page,attr"`
+		} `xml:"chars>char"`
+		Kernings []struct {
+			First  rune `xml:"first,attr"`
+			Second rune `xml:"second,attr"`
+			Amount int  `xml:"amount,attr"`
+		} `xml:"kernings>kerning"`
+	}
+	if err := xml.Unmarshal(data, &xf); err != nil {
+		return nil, err
+	}
+	bm.Face, bm.Size = xf.Info.Face, xf.Info.Size
+	bm.LineHeight, bm.Base = xf.Common.LineHeight, xf.Common.Base
+	pages := make([]string, len(xf.Pages))
+	for _, p := range xf.Pages {
+		if p.ID < 0 || p.ID >= len(pages) {
+			return nil, oakerr.InvalidInput{InputName: "page id"}
+		}
+		pages[p.ID] = p.File
+	}
+	for _, ch := range xf.Chars {
+		bm.Chars[ch.ID] = BMChar{
+			X:        ch.X,
+			Y:        ch.Y,
+			Width:    ch.Width,
+			Height:   ch.Height,
+			XOffset:  ch.XOffset,
+			YOffset:  ch.YOffset,
+			XAdvance: ch.XAdvance,
+			Page:     ch.Page,
+		}
+	}
+	for _, k := range xf.Kernings {
+		bm.Kernings[[2]rune{k.First, k.Second}] = k.Amount
+	}
+	return pages, nil
+}
+
+// Block types of binary BMFonts.
+const (
+	bmBlockInfo    = 1
+	bmBlockCommon  = 2
+	bmBlockPages   = 3
+	bmBlockChars   = 4
+	bmBlockKerning = 5
+)
+
+func (bm *BMFont) parseBinary(data []byte) ([]string, error) {
+	if len(data) < 4 || data[3] != 3 {
+		return nil, oakerr.UnsupportedFormat{Format: "bmfont binary version"}
+	}
+	le := binary.LittleEndian
+	var pages []string
+	for data = data[4:]; len(data) > 0; {
+		if len(data) < 5 {
+			return nil, oakerr.InvalidInput{InputName: "data"}
+		}
+		typ, size := data[0], int(le.Uint32(data[1:5]))
+		if len(data) < 5+size {
+			return nil, oakerr.InvalidInput{InputName: "data"}
+		}
+		block := data[5 : 5+size]
+		data = data[5+size:]
+		switch typ {
+		case bmBlockInfo:
+			if len(block) < 14 {
+				return nil, oakerr.InvalidInput{InputName: "info"}
+			}
+			bm.Size = int(int16(le.Uint16(block)))
+			if bm.Size < 0 {
+				// Negative sizes mark fonts sized to match character height.
+				bm.Size = -bm.Size
+			}
+			bm.Face = string(bytes.TrimRight(block[14:], "\x00"))
+		case bmBlockCommon:
+			if len(block) < 4 {
+				return nil, oakerr.InvalidInput{InputName: "common"}
+			}
+			bm.LineHeight = int(le.Uint16(block))
+			bm.Base = int(le.Uint16(block[2:]))
+		case bmBlockPages:
+			for _, p := range bytes.Split(bytes.TrimRight(block, "\x00"), []byte{0}) {
+				pages = append(pages, string(p))
+			}
+		case bmBlockChars:
+			for ; len(block) >= 20; block = block[20:] {
+				bm.Chars[rune(le.Uint32(block))] = BMChar{
+					X:        int(le.Uint16(block[4:])),
+					Y:        int(le.Uint16(block[6:])),
+					Width:    int(le.Uint16(block[8:])),
+					Height:   int(le.Uint16(block[10:])),
+					XOffset:  int(int16(le.Uint16(block[12:]))),
+					YOffset:  int(int16(le.Uint16(block[14:]))),
+					XAdvance: int(int16(le.Uint16(block[16:]))),
+					Page:     int(block[18]),
+				}
+			}
+		case bmBlockKerning:
+			for ; len(block) >= 10; block = block[10:] {
+				pair := [2]rune{rune(le.Uint32(block)), rune(le.Uint32(block[4:]))}
+				bm.Kernings[pair] = int(int16(le.Uint16(block[8:])))
+			}
+		}
+	}
+	return pages, nil
+}
+
+// Close does nothing; BMFonts hold no resources to release.
+func (bm *BMFont) Close() error {
+	return nil
+}
+
+// Glyph returns where a rune is drawn with its baseline at dot, the page it is
+// drawn from and where on that page.
+func (bm *BMFont) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	ch, ok := bm.Chars[r]
+	if !ok {
+		return image.Rectangle{}, nil, image.Point{}, 0, false
+	}
+	min := image.Pt(dot.X.Round()+ch.XOffset, dot.Y.Round()-bm.Base+ch.YOffset)
+	dr = image.Rectangle{Min: min, Max: min.Add(image.Pt(ch.Width, ch.Height))}
+	return dr, bm.Pages[ch.Page], image.Pt(ch.X, ch.Y), fixed.I(ch.XAdvance), true
+}
+
+// GlyphBounds returns the bounds of a rune relative to its dot.
+func (bm *BMFont) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	ch, ok := bm.Chars[r]
+	if !ok {
+		return fixed.Rectangle26_6{}, 0, false
+	}
+	y := ch.YOffset - bm.Base
+	bounds = fixed.R(ch.XOffset, y, ch.XOffset+ch.Width, y+ch.Height)
+	return bounds, fixed.I(ch.XAdvance), true
+}
+
+// GlyphAdvance returns how far the dot moves after drawing a rune.
+func (bm *BMFont) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	ch, ok := bm.Chars[r]
+	return fixed.I(ch.XAdvance), ok
+}
+
+// Kern returns the kerning between two runes.
+func (bm *BMFont) Kern(r0, r1 rune) fixed.Int26_6 {
+	return fixed.I(bm.Kernings[[2]rune{r0, r1}])
+}
+
+// Metrics returns the metrics of the BMFont.
+func (bm *BMFont) Metrics() font.Metrics {
+	return font.Metrics{
+		Height:    fixed.I(bm.LineHeight),
+		Ascent:    fixed.I(bm.Base),
+		Descent:   fixed.I(bm.LineHeight - bm.Base),
+		CapHeight: fixed.I(bm.Base),
+		XHeight:   fixed.I(bm.Base / 2),
+	}
+}
@@ -0,0 +1,111 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/oakmound/oak/v4/fileutil"
+)
+
+func TestCacheLoadBMFont(t *testing.T) {
+	c := NewCache()
+	text, err := c.LoadBMFont("testdata/assets/fonts/bmfont/pixel.fnt")
+	if err != nil {
+		t.Fatalf("load text bmfont failed: %v", err)
+	}
+	if text.Face != "Pixel" || text.Size != 8 || text.LineHeight != 8 || text.Base != 7 || len(text.Pages) != 1 {
+		t.Fatalf("text bmfont header loaded incorrectly: %+v", text)
+	}
+	if text.Chars['B'] != (BMChar{X: 4, Width: 4, Height: 6, YOffset: 1, XAdvance: 5}) {
+		t.Fatalf("text bmfont char loaded incorrectly: %+v", text.Chars['B'])
+	}
+	for _, file := range []string{"pixel_xml.fnt", "pixel_bin.fnt"} {
+		bm, err := c.LoadBMFont("testdata/assets/fonts/bmfont/" + file)
+		if err != nil {
+			t.Fatalf("load %v failed: %v", file, err)
+		}
+		if bm.Face != text.Face || bm.Size != text.Size || bm.LineHeight != text.LineHeight || bm.Base != text.Base {
+			t.Fatalf("%v header should match the text format: %+v", file, bm)
+		}
+		if !reflect.DeepEqual(bm.Chars, text.Chars) || !reflect.DeepEqual(bm.Kernings, text.Kernings) {
+			t.Fatalf("%v chars should match the text format", file)
+		}
+	}
+	if _, err := c.GetBMFont("pixel.fnt"); err != nil {
+		t.Fatalf("bmfonts should be cached by base name: %v", err)
+	}
+	c.Clear("pixel.fnt")
+	if _, err := c.GetBMFont("pixel.fnt"); err == nil {
+		t.Fatalf("cleared bmfont should not be cached")
+	}
+	if _, err := c.LoadBMFont("testdata/assets/fonts/luxisr.ttf"); err == nil {
+		t.Fatalf("expected truetype font to fail")
+	}
+}
+
+func TestBMFontText(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	fg := FontGenerator{
+		Cache: NewCache(),
+		File:  "testdata/assets/fonts/bmfont/pixel.fnt",
+		Color: image.NewUniform(red),
+	}
+	fnt, err := fg.Generate()
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	if fnt.Height() != 8 {
+		t.Fatalf("bmfont height should be its line height, got %v", fnt.Height())
+	}
+	txt := fnt.NewText("AB", 0, 0)
+	// B is kerned one pixel closer to A.
+	if w, h := txt.GetDims(); w != 9 || h != 8 {
+		t.Fatalf("expected 9x8 text, got %vx%v", w, h)
+	}
+	buff := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	txt.Draw(buff, 0, 0)
+	if buff.RGBAAt(0, 0) != (color.RGBA{}) || buff.RGBAAt(0, 1) != red || buff.RGBAAt(3, 6) != red {
+		t.Fatalf("A drawn to the wrong place")
+	}
+	if buff.RGBAAt(7, 1) != red || buff.RGBAAt(8, 1) != (color.RGBA{}) || buff.RGBAAt(4, 7) != (color.RGBA{}) {
+		t.Fatalf("B drawn to the wrong place")
+	}
+
+	txt.SetString("A A")
+	if w, _ := txt.GetDims(); w != 13 {
+		t.Fatalf("expected width 13, got %v", w)
+	}
+	txt.Center()
+	if txt.X() != -6 {
+		t.Fatalf("centered text at wrong x %v", txt.X())
+	}
+	lines := txt.Wrap(1, 8)
+	if len(lines) != 3 || lines[2].Y() != 16 {
+		t.Fatalf("wrap produced wrong lines")
+	}
+
+	// Without a color, glyphs are drawn in the colors of their pages.
+	raw, err := fileutil.ReadFile("testdata/assets/fonts/bmfont/pixel_bin.fnt")
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	fg = FontGenerator{
+		File:    "testdata/assets/fonts/bmfont/pixel_bin.fnt",
+		RawFile: raw,
+	}
+	fnt, err = fg.Generate()
+	if err != nil {
+		t.Fatalf("generate from raw file failed: %v", err)
+	}
+	buff = image.NewRGBA(image.Rect(0, 0, 16, 16))
+	fnt.NewText("B", 0, 0).Draw(buff, 0, 0)
+	if buff.RGBAAt(0, 1) != (color.RGBA{0, 0, 255, 255}) {
+		t.Fatalf("uncolored glyph should match its page, got %v", buff.RGBAAt(0, 1))
+	}
+	sp := fnt.NewText("B", 0, 0).ToSprite()
+	if sp.GetRGBA().RGBAAt(0, 1) != (color.RGBA{0, 0, 255, 255}) {
+		t.Fatalf("text sprite drawn incorrectly")
+	}
+}
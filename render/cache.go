@@ -21,14 +21,19 @@ type Cache struct {
 
 	fontLock    sync.RWMutex
 	loadedFonts map[string]*truetype.Font
+	// loadedBMFonts shares fontLock with loadedFonts.
+	loadedBMFonts map[string]*BMFont
+
+	atlas *Atlas
 }
 
 // NewCache returns an empty Cache
 func NewCache() *Cache {
 	return &Cache{
-		loadedImages: make(map[string]*image.RGBA),
-		loadedSheets: make(map[string]*Sheet),
-		loadedFonts:  make(map[string]*truetype.Font),
+		loadedImages:  make(map[string]*image.RGBA),
+		loadedSheets:  make(map[string]*Sheet),
+		loadedFonts:   make(map[string]*truetype.Font),
+		loadedBMFonts: make(map[string]*BMFont),
 	}
 }
 
@@ -40,12 +45,27 @@ func (c *Cache) ClearAll() {
 	c.loadedImages = make(map[string]*image.RGBA)
 	c.loadedSheets = make(map[string]*Sheet)
 	c.loadedFonts = make(map[string]*truetype.Font)
+	c.loadedBMFonts = make(map[string]*BMFont)
+	if c.atlas != nil {
+		c.atlas.Reset()
+	}
 	c.fontLock.Unlock()
 	c.sheetLock.Unlock()
 	c.imageLock.Unlock()
 }
 
-// Clear will remove elements matching the given key from the Cache.
+// SetAtlas sets an atlas that images loaded by this cache are packed into, and
+// sheets loaded by it are cut from as views rather than copies. Images too
+// large for the atlas' pages are kept apart. A nil atlas stops packing.
+// Images already loaded are not repacked.
+func (c *Cache) SetAtlas(a *Atlas) {
+	c.imageLock.Lock()
+	c.atlas = a
+	c.imageLock.Unlock()
+}
+
+// Clear will remove elements matching the given key from the Cache. Space an
+// image took in an atlas is not reclaimed until ClearAll is called.
 func (c *Cache) Clear(key string) {
 	c.imageLock.Lock()
 	c.sheetLock.Lock()
@@ -53,6 +73,7 @@ func (c *Cache) Clear(key string) {
 	delete(c.loadedImages, key)
 	delete(c.loadedSheets, key)
 	delete(c.loadedFonts, key)
+	delete(c.loadedBMFonts, key)
 	c.fontLock.Unlock()
 	c.sheetLock.Unlock()
 	c.imageLock.Unlock()
@@ -87,3 +108,18 @@ func GetFont(file string) (*truetype.Font, error) {
 func LoadFont(file string) (*truetype.Font, error) {
 	return DefaultCache.LoadFont(file)
 }
+
+// LoadAtlas calls LoadAtlas on the Default Cache.
+func LoadAtlas(file string) ([]string, error) {
+	return DefaultCache.LoadAtlas(file)
+}
+
+// LoadBMFont calls LoadBMFont on the Default Cache.
+func LoadBMFont(file string) (*BMFont, error) {
+	return DefaultCache.LoadBMFont(file)
+}
+
+// GetBMFont calls GetBMFont on the Default Cache.
+func GetBMFont(file string) (*BMFont, error) {
+	return DefaultCache.GetBMFont(file)
+}
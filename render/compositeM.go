@@ -95,6 +95,12 @@ func (cs *CompositeM) Draw(buff draw.Image, xOff, yOff float64) {
 	}
 }
 
+func (cs *CompositeM) drawBlend(buff draw.Image, xOff, yOff float64, mode BlendMode) {
+	for _, c := range cs.rs {
+		drawBlended(c, buff, cs.X()+xOff, cs.Y()+yOff, mode)
+	}
+}
+
 // Undraw stops the CompositeM from being drawn
 func (cs *CompositeM) Undraw() {
 	cs.layer = Undraw
@@ -17,6 +17,7 @@ type CompositeR struct {
 	toUndraw    []Renderable
 	rs          []Renderable
 	predrawLock sync.Mutex
+	blend       BlendMode
 }
 
 // NewCompositeR creates a new CompositeR from a slice of renderables
@@ -73,9 +74,54 @@ func (cs *CompositeR) SetOffsets(ps ...floatgeom.Point2) {
 
 // Draw Draws the CompositeR with an offset from its logical location.
 func (cs *CompositeR) Draw(buff draw.Image, xOff, yOff float64) {
+	cs.drawBlend(buff, xOff, yOff, cs.blend)
+}
+
+func (cs *CompositeR) drawBlend(buff draw.Image, xOff, yOff float64, mode BlendMode) {
 	for _, c := range cs.rs {
-		c.Draw(buff, cs.X()+xOff, cs.Y()+yOff)
+		drawBlended(c, buff, cs.X()+xOff, cs.Y()+yOff, mode)
+	}
+}
+
+// SetBlendMode sets how the renderables in this composite are blended with
+// what is drawn beneath them. Renderables which are Blendable and have a
+// blend mode of their own keep it, and renderables which are neither Blendable
+// nor Modifiable are drawn as usual.
+func (cs *CompositeR) SetBlendMode(mode BlendMode) {
+	cs.blend = mode
+}
+
+// GetBlendMode returns how the renderables in this composite are blended with
+// what is drawn beneath them.
+func (cs *CompositeR) GetBlendMode() BlendMode {
+	return cs.blend
+}
+
+// A blendDrawer can be drawn in a blend mode other than its own.
+type blendDrawer interface {
+	drawBlend(buff draw.Image, xOff, yOff float64, mode BlendMode)
+}
+
+// drawBlended draws r in the given blend mode, unless r has a blend mode of its
+// own.
+func drawBlended(r Renderable, buff draw.Image, xOff, yOff float64, mode BlendMode) {
+	if mode == BlendOver {
+		r.Draw(buff, xOff, yOff)
+		return
+	}
+	if b, ok := r.(Blendable); ok && b.GetBlendMode() != BlendOver {
+		r.Draw(buff, xOff, yOff)
+		return
+	}
+	if bd, ok := r.(blendDrawer); ok {
+		bd.drawBlend(buff, xOff, yOff, mode)
+		return
+	}
+	if m, ok := r.(Modifiable); ok && m.GetRGBA() != nil {
+		DrawImageBlend(buff, m.GetRGBA(), int(r.X()+xOff), int(r.Y()+yOff), mode)
+		return
 	}
+	r.Draw(buff, xOff, yOff)
 }
 
 // Undraw undraws the CompositeR and its consituent renderables
@@ -132,6 +178,7 @@ func (cs *CompositeR) PreDraw() {
 func (cs *CompositeR) Copy() Stackable {
 	cs2 := new(CompositeR)
 	cs2.LayeredPoint = cs.LayeredPoint
+	cs2.blend = cs.blend
 	cs2.rs = make([]Renderable, len(cs.rs))
 	return cs2
 }
@@ -158,7 +205,7 @@ func (cs *CompositeR) DrawToScreen(world draw.Image, viewPos *intgeom.Point2, sc
 		y += h
 		if x > viewPos[0] && y > viewPos[1] &&
 			x2 < viewPos[0]+screenW && y2 < viewPos[1]+screenH {
-			r.Draw(world, float64(-viewPos[0]), float64(-viewPos[1]))
+			drawBlended(r, world, float64(-viewPos[0]), float64(-viewPos[1]), cs.blend)
 		}
 	}
 	cs.rs = cs.rs[0:realLength]
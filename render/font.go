@@ -39,6 +39,7 @@ type Font struct {
 	gen FontGenerator
 	font.Drawer
 	ttfnt  *truetype.Font
+	bm     *BMFont
 	bounds intgeom.Rect2
 	Unsafe bool
 	mutex  sync.Mutex
@@ -46,7 +47,10 @@ type Font struct {
 	Fallbacks []*Font
 }
 
-// A FontGenerator stores information that can be used to create a font
+// A FontGenerator stores information that can be used to create a font.
+// File or RawFile may hold a TrueType font or a BMFont; BMFonts are loaded
+// from files ending in .fnt, or raw files in any BMFont format, whose pages
+// are found relative to File.
 type FontGenerator struct {
 	Cache   *Cache
 	File    string
@@ -70,13 +74,21 @@ func (fg FontGenerator) validate() error {
 	if len(fg.File) == 0 && len(fg.RawFile) == 0 {
 		return oakerr.InvalidInput{InputName: "File"}
 	}
-	if fg.Color == nil {
+	if fg.Color == nil && !fg.isBMFont() {
 		return oakerr.InvalidInput{InputName: "Color"}
 	}
 	return nil
 }
 
-// Generate generates a font. File or RawFile and Color must be provided.
+func (fg FontGenerator) isBMFont() bool {
+	if len(fg.RawFile) != 0 {
+		return isBMFont(fg.RawFile)
+	}
+	return strings.EqualFold(filepath.Ext(fg.File), ".fnt")
+}
+
+// Generate generates a font. File or RawFile and Color must be provided,
+// except that BMFonts may omit Color to draw in the colors of their pages.
 // If Cache and File are provided, the generated font will be stored in the provided cache.
 // If Cache is not provided, it will default to DefaultCache.
 func (fg *FontGenerator) Generate() (*Font, error) {
@@ -86,6 +98,9 @@ func (fg *FontGenerator) Generate() (*Font, error) {
 	if fg.Cache == nil {
 		fg.Cache = DefaultCache
 	}
+	if fg.isBMFont() {
+		return fg.generateBM()
+	}
 
 	var fnt *truetype.Font
 	var err error
@@ -130,6 +145,32 @@ func (fg *FontGenerator) Generate() (*Font, error) {
 	}, nil
 }
 
+// generateBM generates a font from a BMFont. BMFonts are drawn at the size
+// they were made, so FontOptions are ignored.
+func (fg *FontGenerator) generateBM() (*Font, error) {
+	var bm *BMFont
+	var err error
+	if len(fg.RawFile) != 0 {
+		bm, err = loadBMFont(fg.RawFile, filepath.Dir(fg.File))
+	} else {
+		bm, err = fg.Cache.LoadBMFont(fg.File)
+	}
+	if err != nil {
+		return nil, err
+	}
+	gen := *fg
+	gen.Size = float64(bm.LineHeight)
+	return &Font{
+		gen: gen,
+		Drawer: font.Drawer{
+			Src:  fg.Color,
+			Face: bm,
+		},
+		bm:     bm,
+		bounds: intgeom.NewRect2(0, 0, 0, bm.LineHeight),
+	}, nil
+}
+
 // RegenerateWith creates a new font off of this generator after changing its generation settings.
 func (fg FontGenerator) RegenerateWith(fgFunc func(FontGenerator) FontGenerator) (*Font, error) {
 	g := fgFunc(fg)
@@ -150,11 +191,15 @@ func (f *Font) Copy() *Font {
 		gen:       f.gen,
 		Drawer:    f.Drawer,
 		ttfnt:     f.ttfnt,
+		bm:        f.bm,
 		bounds:    f.bounds,
 		Unsafe:    f.Unsafe,
 		Fallbacks: f.Fallbacks,
 	}
-	f2.Drawer.Face = truetype.NewFace(f.ttfnt, &f.gen.FontOptions)
+	// BMFonts hold no per-face state, so their face is shared.
+	if f.bm == nil {
+		f2.Drawer.Face = truetype.NewFace(f.ttfnt, &f.gen.FontOptions)
+	}
 	return f2
 }
 
@@ -167,14 +212,14 @@ func (f *Font) MeasureString(s string) fixed.Int26_6 {
 	var width fixed.Int26_6
 	for _, c := range s {
 		if prevC >= 0 {
-			f.Drawer.Dot.X += f.Drawer.Face.Kern(prevC, c)
+			width += f.Drawer.Face.Kern(prevC, c)
 		}
 		_, _, _, advance, ok := f.Drawer.Face.Glyph(f.Drawer.Dot, c)
-		if idx := f.ttfnt.Index(c); !ok || idx == 0 {
+		if !f.hasGlyph(c, ok) {
 			found := false
 			for _, fallback := range f.Fallbacks {
 				_, _, _, advance, ok = fallback.Drawer.Face.Glyph(f.Drawer.Dot, c)
-				if idx := fallback.ttfnt.Index(c); ok && idx != 0 {
+				if fallback.hasGlyph(c, ok) {
 					found = true
 					break
 				}
@@ -198,11 +243,11 @@ func (f *Font) drawString(s string) {
 			f.Drawer.Dot.X += f.Drawer.Face.Kern(prevC, c)
 		}
 		dr, mask, maskp, advance, ok := f.Drawer.Face.Glyph(f.Drawer.Dot, c)
-		if idx := f.ttfnt.Index(c); !ok || idx == 0 {
+		if !f.hasGlyph(c, ok) {
 			found := false
 			for _, fallback := range f.Fallbacks {
 				dr, mask, maskp, advance, ok = fallback.Drawer.Face.Glyph(f.Drawer.Dot, c)
-				if idx := fallback.ttfnt.Index(c); ok && idx != 0 {
+				if fallback.hasGlyph(c, ok) {
 					found = true
 					break
 				}
@@ -211,12 +256,34 @@ func (f *Font) drawString(s string) {
 				continue
 			}
 		}
-		draw.DrawMask(f.Drawer.Dst, dr, f.Drawer.Src, image.Point{}, mask, maskp, draw.Over)
+		if f.Drawer.Src == nil {
+			// Uncolored BMFonts draw their glyphs as they are in their pages.
+			draw.Draw(f.Drawer.Dst, dr, mask, maskp, draw.Over)
+		} else {
+			draw.DrawMask(f.Drawer.Dst, dr, f.Drawer.Src, image.Point{}, mask, maskp, draw.Over)
+		}
 		f.Drawer.Dot.X += advance
 		prevC = c
 	}
 }
 
+// hasGlyph reports whether this font can draw c, given whether its face
+// reported a glyph for c.
+func (f *Font) hasGlyph(c rune, ok bool) bool {
+	if f.ttfnt == nil {
+		return ok
+	}
+	return ok && f.ttfnt.Index(c) != 0
+}
+
+// ascent returns the distance from the top of a line of text to its baseline.
+func (f *Font) ascent() float64 {
+	if f.bm != nil {
+		return float64(f.bm.Base)
+	}
+	return f.Height()
+}
+
 // Height returns the height or size of the font
 func (f *Font) Height() float64 {
 	if f.gen.Size == 0 {
@@ -135,6 +135,27 @@ func TestFont_Height(t *testing.T) {
 
 }
 
+func TestFont_MeasureStringKerning(t *testing.T) {
+	f := DefaultFont()
+	face := f.Drawer.Face
+	kern := face.Kern('A', 'V')
+	if kern >= 0 {
+		t.Fatalf("expected the default font to kern AV closer, got %v", kern)
+	}
+	a, _ := face.GlyphAdvance('A')
+	v, _ := face.GlyphAdvance('V')
+	dot := f.Drawer.Dot
+	if got := f.MeasureString("AV"); got != a+v+kern {
+		t.Fatalf("expected AV to measure %v with kerning, got %v", a+v+kern, got)
+	}
+	if f.Drawer.Dot != dot {
+		t.Fatalf("measuring should not move the drawer's dot")
+	}
+	if got := f.MeasureString("A"); got != a {
+		t.Fatalf("expected A to measure %v, got %v", a, got)
+	}
+}
+
 func TestFont_RegenerateWith(t *testing.T) {
 	fg := FontGenerator{
 		File:  "testdata/assets/fonts/luxisr.ttf",
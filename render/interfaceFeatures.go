@@ -14,6 +14,13 @@ type Triggerable interface {
 	SetTriggerID(event.CallerID)
 }
 
+// BusTriggerable types are Triggerable on a chosen event handler, rather than
+// on event.DefaultBus.
+type BusTriggerable interface {
+	Triggerable
+	SetTriggerBus(event.Handler)
+}
+
 type updates interface {
 	update()
 }
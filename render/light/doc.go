@@ -0,0 +1,4 @@
+// Package light provides a lighting stackable, which darkens the layers
+// beneath it on a draw stack except where point, spot and ambient lights
+// shine, with shadows cast by collision spaces.
+package light
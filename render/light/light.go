@@ -0,0 +1,183 @@
+package light
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"math/rand"
+
+	"github.com/oakmound/oak/v4/alg"
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/render"
+)
+
+// A Light shines on a circle around its position. Lights are renderables so
+// they can be added to a Lighting through a draw stack, but unlike most
+// renderables their position is their center.
+type Light struct {
+	render.LayeredPoint
+	// Color is the color of the light. Its alpha is ignored.
+	Color color.RGBA
+	// Radius is how far the light reaches.
+	Radius float64
+	// Falloff is how quickly the light fades with distance: light at a
+	// distance d is scaled by (1 - d/Radius)^Falloff. A Falloff of 0 is
+	// treated as 1, fading linearly.
+	Falloff float64
+	// Intensity scales the light. An Intensity of 0 is treated as 1.
+	Intensity float64
+	// Direction is the way a spot light points, where 0 is right and 90 is
+	// down.
+	Direction alg.Degree
+	// Cone is how wide a spot light is. Lights with a cone of 0, or of 360
+	// or more, shine in every direction.
+	Cone alg.Degree
+	// Flicker varies the light's intensity over time.
+	Flicker Flicker
+	// NoShadows stops occluders from blocking the light.
+	NoShadows bool
+
+	phase float64
+}
+
+// A Flicker varies the intensity of a light.
+type Flicker struct {
+	// Amount is how much intensity the light can lose, from 0 to 1.
+	Amount float64
+	// Speed is roughly how many times a second the light flickers.
+	Speed float64
+}
+
+// NewPoint returns a light centered on x, y shining in every direction.
+func NewPoint(x, y, radius float64, c color.RGBA) *Light {
+	return &Light{
+		LayeredPoint: render.NewLayeredPoint(x, y, 0),
+		Color:        c,
+		Radius:       radius,
+		phase:        rand.Float64() * 2 * math.Pi,
+	}
+}
+
+// NewSpot returns a light centered on x, y shining in a cone around a
+// direction.
+func NewSpot(x, y, radius float64, c color.RGBA, direction, cone alg.Degree) *Light {
+	l := NewPoint(x, y, radius, c)
+	l.Direction = direction
+	l.Cone = cone
+	return l
+}
+
+// GetDims returns the size of the square the light shines within.
+func (l *Light) GetDims() (int, int) {
+	d := int(math.Ceil(l.Radius * 2))
+	return d, d
+}
+
+// Draw adds the light, without shadows, to an *image.RGBA buffer at +xOff,
+// +yOff. Other buffers are not drawn to.
+func (l *Light) Draw(buff draw.Image, xOff, yOff float64) {
+	if rgba, ok := buff.(*image.RGBA); ok {
+		l.shine(rgba, -xOff, -yOff, 1, nil, l.strength(0))
+	}
+}
+
+// strength returns the light's intensity t seconds into a Lighting's life.
+func (l *Light) strength(t float64) float64 {
+	s := l.Intensity
+	if s == 0 {
+		s = 1
+	}
+	if f := l.Flicker; f.Amount != 0 {
+		// Two out of step waves make an uneven flicker.
+		w := 2 * math.Pi * f.Speed * t
+		noise := (math.Sin(w+l.phase) + math.Sin(2.3*w+1.7*l.phase)) / 4
+		s *= 1 - f.Amount*(.5+noise)
+	}
+	return s
+}
+
+// shine adds the light to a light map whose pixels each cover scale world
+// pixels, the first with its top left at originX, originY. Light is blocked
+// by occluders unless the light has no shadows.
+func (l *Light) shine(lm *image.RGBA, originX, originY float64, scale int, occluders []*collision.Space, strength float64) {
+	if l.Radius <= 0 || strength <= 0 {
+		return
+	}
+	cx, cy := l.X(), l.Y()
+	sc := float64(scale)
+	area := image.Rect(
+		int(math.Floor((cx-l.Radius-originX)/sc)),
+		int(math.Floor((cy-l.Radius-originY)/sc)),
+		int(math.Ceil((cx+l.Radius-originX)/sc)),
+		int(math.Ceil((cy+l.Radius-originY)/sc)),
+	).Intersect(lm.Bounds())
+	if area.Empty() {
+		return
+	}
+	falloff := l.Falloff
+	if falloff == 0 {
+		falloff = 1
+	}
+	spot := l.Cone > 0 && l.Cone < 360
+	half := float64(l.Cone.Radians()) / 2
+	dirX, dirY := math.Cos(float64(l.Direction.Radians())), math.Sin(float64(l.Direction.Radians()))
+	// Spot lights fade out over the outer tenth of their cone.
+	edge := math.Cos(half * .9)
+	outer := math.Cos(half)
+	if l.NoShadows {
+		occluders = nil
+	}
+	center := floatgeom.Point2{cx, cy}
+	cr, cg, cb := float64(l.Color.R)*strength, float64(l.Color.G)*strength, float64(l.Color.B)*strength
+	for j := area.Min.Y; j < area.Max.Y; j++ {
+		wy := originY + (float64(j)+.5)*sc
+		for i := area.Min.X; i < area.Max.X; i++ {
+			wx := originX + (float64(i)+.5)*sc
+			dx, dy := wx-cx, wy-cy
+			d := math.Sqrt(dx*dx + dy*dy)
+			if d >= l.Radius {
+				continue
+			}
+			f := math.Pow(1-d/l.Radius, falloff)
+			if spot && d > 0 {
+				cos := (dx*dirX + dy*dirY) / d
+				if cos <= outer {
+					continue
+				}
+				if cos < edge {
+					f *= (cos - outer) / (edge - outer)
+				}
+			}
+			if d > 0 && blocked(occluders, center, floatgeom.Point2{dx / d, dy / d}, d, scale) {
+				continue
+			}
+			px := lm.PixOffset(i, j)
+			lm.Pix[px] = addClamped(lm.Pix[px], cr*f)
+			lm.Pix[px+1] = addClamped(lm.Pix[px+1], cg*f)
+			lm.Pix[px+2] = addClamped(lm.Pix[px+2], cb*f)
+			lm.Pix[px+3] = addClamped(lm.Pix[px+3], math.Max(cr, math.Max(cg, cb))*f)
+		}
+	}
+}
+
+// blocked reports whether an occluder lies between a light and a point dist
+// away from it. Occluders are lit along their edges, so the first pixel of an
+// occluder a ray meets is not blocked.
+func blocked(occluders []*collision.Space, origin, dir floatgeom.Point2, dist float64, scale int) bool {
+	for _, o := range occluders {
+		if hit, ok := o.Raycast(origin, dir, dist); ok && hit.Distance < dist-float64(scale) {
+			return true
+		}
+	}
+	return false
+}
+
+func addClamped(v uint8, add float64) uint8 {
+	out := float64(v) + add
+	if out >= 255 {
+		return 255
+	}
+	return uint8(out)
+}
@@ -0,0 +1,138 @@
+package light
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/oakmound/oak/v4/alg/intgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/render"
+)
+
+func gray(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:], []uint8{200, 200, 200, 255})
+	}
+	return img
+}
+
+func drawLighting(lt *Lighting, view intgeom.Point2, w, h int) *image.RGBA {
+	world := gray(w, h)
+	lt.PreDraw()
+	lt.DrawToScreen(world, &view, w, h)
+	return world
+}
+
+func TestLightingAmbient(t *testing.T) {
+	lt := New(Ambient(color.RGBA{128, 255, 0, 255}))
+	world := drawLighting(lt, intgeom.Point2{}, 4, 4)
+	if got := world.RGBAAt(2, 2); got != (color.RGBA{100, 200, 0, 255}) {
+		t.Fatalf("expected ambient tint, got %v", got)
+	}
+}
+
+func TestLightingPoint(t *testing.T) {
+	lt := New()
+	l := NewPoint(10, 10, 8, color.RGBA{255, 255, 255, 255})
+	if lt.Add(l) != l {
+		t.Fatalf("add should return the light")
+	}
+	if lt.Add(render.EmptyRenderable()) == nil {
+		t.Fatalf("add should return other renderables")
+	}
+	world := drawLighting(lt, intgeom.Point2{}, 20, 20)
+	near, mid, far := world.RGBAAt(10, 10), world.RGBAAt(14, 10), world.RGBAAt(19, 10)
+	if near.R < 180 || mid.R >= near.R || mid.R == 0 || far.R != 0 {
+		t.Fatalf("expected light to fade from its center, got %v %v %v", near, mid, far)
+	}
+	if len(lt.Lights()) != 1 {
+		t.Fatalf("expected only the light to be added")
+	}
+
+	l.Undraw()
+	world = drawLighting(lt, intgeom.Point2{}, 20, 20)
+	if world.RGBAAt(10, 10).R != 0 || len(lt.Lights()) != 0 {
+		t.Fatalf("undrawn lights should be removed")
+	}
+}
+
+func TestLightingSpot(t *testing.T) {
+	lt := New()
+	lt.Add(NewSpot(10, 10, 8, color.RGBA{255, 255, 255, 255}, 0, 90))
+	world := drawLighting(lt, intgeom.Point2{}, 20, 20)
+	if world.RGBAAt(13, 10).R == 0 || world.RGBAAt(7, 10).R != 0 || world.RGBAAt(10, 14).R != 0 {
+		t.Fatalf("spot light should shine only to its right")
+	}
+}
+
+func TestLightingShadows(t *testing.T) {
+	const wall collision.Label = 1
+	tree := collision.NewTree()
+	tree.Add(collision.NewLabeledSpace(12, 0, 2, 20, wall))
+	tree.Add(collision.NewLabeledSpace(0, 0, 2, 20, wall+1))
+	lt := New(Occluders(tree, wall))
+	lt.Add(NewPoint(10, 10, 9, color.RGBA{255, 255, 255, 255}))
+	world := drawLighting(lt, intgeom.Point2{}, 20, 20)
+	if world.RGBAAt(8, 10).R == 0 {
+		t.Fatalf("light should reach the open side")
+	}
+	if world.RGBAAt(16, 10).R != 0 {
+		t.Fatalf("light should not pass the wall")
+	}
+	if world.RGBAAt(2, 10).R == 0 {
+		t.Fatalf("unlabeled spaces should not cast shadows")
+	}
+
+	// Lights inside an occluder are not blocked by it.
+	lt = New(Occluders(tree, wall))
+	lt.Add(NewPoint(13, 10, 9, color.RGBA{255, 255, 255, 255}))
+	world = drawLighting(lt, intgeom.Point2{}, 20, 20)
+	if world.RGBAAt(16, 10).R == 0 || world.RGBAAt(10, 10).R == 0 {
+		t.Fatalf("light inside a wall should shine out of it")
+	}
+}
+
+func TestLightingViewport(t *testing.T) {
+	lt := New(Resolution(2))
+	lt.Add(NewPoint(110, 50, 4, color.RGBA{255, 255, 255, 255}))
+	world := drawLighting(lt, intgeom.Point2{100, 40}, 20, 20)
+	if world.RGBAAt(10, 10).R == 0 || world.RGBAAt(2, 2).R != 0 {
+		t.Fatalf("light should be drawn relative to the viewport")
+	}
+}
+
+func TestLightingFogOfWar(t *testing.T) {
+	fog := color.RGBA{0, 0, 50, 255}
+	lt := New(FogOfWar(fog))
+	lt.Add(NewPoint(10, 10, 8, color.RGBA{255, 0, 0, 255}))
+	world := drawLighting(lt, intgeom.Point2{}, 20, 20)
+	if got := world.RGBAAt(19, 19); got != fog {
+		t.Fatalf("unlit areas should be fog, got %v", got)
+	}
+	if got := world.RGBAAt(10, 10); got.G < 180 {
+		t.Fatalf("lit areas should be revealed untinted, got %v", got)
+	}
+
+	cp := lt.Copy().(*Lighting)
+	if !cp.fog || cp.fogColor != fog || len(cp.Lights()) != 0 {
+		t.Fatalf("copies should keep settings without lights")
+	}
+	lt.Clear()
+	if len(lt.Lights()) != 0 {
+		t.Fatalf("clear should remove lights")
+	}
+}
+
+func TestLightingNonRGBA(t *testing.T) {
+	lt := New(Ambient(color.RGBA{128, 128, 128, 255}))
+	world := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < len(world.Pix); i += 4 {
+		copy(world.Pix[i:], []uint8{200, 200, 200, 255})
+	}
+	lt.DrawToScreen(world, &intgeom.Point2{}, 4, 4)
+	if got := world.NRGBAAt(1, 1); got != (color.NRGBA{100, 100, 100, 255}) {
+		t.Fatalf("expected darkened pixel, got %v", got)
+	}
+}
@@ -0,0 +1,271 @@
+package light
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/alg/intgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/render"
+)
+
+// Lighting is a render.Stackable which darkens everything drawn beneath it on
+// a draw stack to its ambient color, except where its lights shine.
+type Lighting struct {
+	ambient  color.RGBA
+	tree     *collision.Tree
+	labels   []collision.Label
+	fog      bool
+	fogColor color.RGBA
+	scale    int
+
+	lights   []*Light
+	toPush   []*Light
+	lock     sync.Mutex
+	lightMap *image.RGBA
+	start    time.Time
+}
+
+// An Option sets up a Lighting.
+type Option func(*Lighting)
+
+// Ambient sets the light shining everywhere. It defaults to black.
+func Ambient(c color.RGBA) Option {
+	return func(lt *Lighting) {
+		lt.ambient = c
+	}
+}
+
+// Occluders sets the spaces which cast shadows to those in the given tree,
+// with any of the given labels. With no labels every space in the tree casts
+// shadows. A nil tree is collision.DefaultTree. Without this option nothing
+// casts shadows.
+func Occluders(tree *collision.Tree, labels ...collision.Label) Option {
+	return func(lt *Lighting) {
+		if tree == nil {
+			tree = collision.DefaultTree
+		}
+		lt.tree = tree
+		lt.labels = labels
+	}
+}
+
+// FogOfWar changes a Lighting from tinting what is beneath it by its lights
+// to hiding what is beneath it in fog of the given color, wherever no light
+// shines. Light colors then only matter in how bright they are.
+func FogOfWar(c color.RGBA) Option {
+	return func(lt *Lighting) {
+		lt.fog = true
+		lt.fogColor = c
+	}
+}
+
+// Resolution sets how many screen pixels wide and tall each pixel of the light
+// map is. Larger resolutions draw faster and give softer edges. It defaults to
+// 1.
+func Resolution(scale int) Option {
+	return func(lt *Lighting) {
+		if scale > 0 {
+			lt.scale = scale
+		}
+	}
+}
+
+// New returns a Lighting with no lights.
+func New(opts ...Option) *Lighting {
+	lt := &Lighting{
+		ambient: color.RGBA{0, 0, 0, 255},
+		scale:   1,
+		start:   time.Now(),
+	}
+	for _, opt := range opts {
+		opt(lt)
+	}
+	return lt
+}
+
+// SetAmbient sets the light shining everywhere.
+func (lt *Lighting) SetAmbient(c color.RGBA) {
+	lt.lock.Lock()
+	lt.ambient = c
+	lt.lock.Unlock()
+}
+
+// Lights returns the lights which were shining at the last draw.
+func (lt *Lighting) Lights() []*Light {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+	return append([]*Light(nil), lt.lights...)
+}
+
+// Add stages a light to be added at the next PreDraw. Renderables which are
+// not *Lights are returned without being added.
+func (lt *Lighting) Add(r render.Renderable, _ ...int) render.Renderable {
+	if l, ok := r.(*Light); ok {
+		lt.lock.Lock()
+		lt.toPush = append(lt.toPush, l)
+		lt.lock.Unlock()
+	}
+	return r
+}
+
+// Replace undraws old and stages new to be added at the next PreDraw.
+func (lt *Lighting) Replace(old, new render.Renderable, _ int) {
+	old.Undraw()
+	lt.Add(new)
+}
+
+// PreDraw adds staged lights.
+func (lt *Lighting) PreDraw() {
+	lt.lock.Lock()
+	lt.lights = append(lt.lights, lt.toPush...)
+	lt.toPush = nil
+	lt.lock.Unlock()
+}
+
+// Copy returns a Lighting with the same settings and no lights.
+func (lt *Lighting) Copy() render.Stackable {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+	return &Lighting{
+		ambient:  lt.ambient,
+		tree:     lt.tree,
+		labels:   lt.labels,
+		fog:      lt.fog,
+		fogColor: lt.fogColor,
+		scale:    lt.scale,
+		start:    time.Now(),
+	}
+}
+
+// Clear removes all lights.
+func (lt *Lighting) Clear() {
+	lt.lock.Lock()
+	lt.lights = nil
+	lt.toPush = nil
+	lt.lock.Unlock()
+}
+
+// DrawToScreen lights the screen-sized area of world viewed from viewPos.
+// Lights which have been undrawn are removed.
+func (lt *Lighting) DrawToScreen(world draw.Image, viewPos *intgeom.Point2, screenW, screenH int) {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+	lm := lt.prepareLightMap(screenW, screenH)
+	view := floatgeom.NewRect2WH(float64(viewPos[0]), float64(viewPos[1]), float64(screenW), float64(screenH))
+	t := time.Since(lt.start).Seconds()
+	kept := lt.lights[:0]
+	for _, l := range lt.lights {
+		if l == nil || l.GetLayer() == render.Undraw {
+			continue
+		}
+		kept = append(kept, l)
+		area := floatgeom.NewRect2WH(l.X()-l.Radius, l.Y()-l.Radius, l.Radius*2, l.Radius*2)
+		if !area.Intersects(view) {
+			continue
+		}
+		lt.shine(l, lm, area, view, t)
+	}
+	for i := len(kept); i < len(lt.lights); i++ {
+		lt.lights[i] = nil
+	}
+	lt.lights = kept
+	lt.composite(world, screenW, screenH)
+}
+
+// prepareLightMap sizes the light map to cover the screen and fills it with
+// the ambient light.
+func (lt *Lighting) prepareLightMap(screenW, screenH int) *image.RGBA {
+	w := (screenW + lt.scale - 1) / lt.scale
+	h := (screenH + lt.scale - 1) / lt.scale
+	if lt.lightMap == nil || lt.lightMap.Rect.Dx() != w || lt.lightMap.Rect.Dy() != h {
+		lt.lightMap = image.NewRGBA(image.Rect(0, 0, w, h))
+	}
+	a := lt.ambient
+	fill := [4]uint8{a.R, a.G, a.B, 255}
+	pix := lt.lightMap.Pix
+	if len(pix) > 0 {
+		copy(pix, fill[:])
+		for filled := 4; filled < len(pix); filled *= 2 {
+			copy(pix[filled:], pix[:filled])
+		}
+	}
+	return lt.lightMap
+}
+
+// shine adds l to the light map, with shadows from the occluders around it.
+func (lt *Lighting) shine(l *Light, lm *image.RGBA, area, view floatgeom.Rect2, t float64) {
+	var occluders []*collision.Space
+	if lt.tree != nil && !l.NoShadows {
+		var filters []collision.Filter
+		if len(lt.labels) != 0 {
+			filters = append(filters, collision.WithLabels(lt.labels...))
+		}
+		query := collision.NewUnassignedSpace(area.Min.X(), area.Min.Y(), area.W(), area.H())
+		center := floatgeom.Point2{l.X(), l.Y()}
+		for _, o := range lt.tree.Hit(query, filters...) {
+			// A light inside an occluder, like a torch held by a character,
+			// is not blocked by it.
+			if !o.Location.ProjectZ().Contains(center) {
+				occluders = append(occluders, o)
+			}
+		}
+	}
+	l.shine(lm, view.Min.X(), view.Min.Y(), lt.scale, occluders, l.strength(t))
+}
+
+// composite applies the light map to world.
+func (lt *Lighting) composite(world draw.Image, screenW, screenH int) {
+	lm := lt.lightMap
+	bounds := image.Rect(0, 0, screenW, screenH).Intersect(world.Bounds())
+	rgba, fast := world.(*image.RGBA)
+	for j := bounds.Min.Y; j < bounds.Max.Y; j++ {
+		for i := bounds.Min.X; i < bounds.Max.X; i++ {
+			lp := lm.Pix[lm.PixOffset(i/lt.scale, j/lt.scale):]
+			var px []uint8
+			var slow [4]uint8
+			if fast {
+				px = rgba.Pix[rgba.PixOffset(i, j):]
+			} else {
+				r, g, b, a := world.At(i, j).RGBA()
+				slow = [4]uint8{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+				px = slow[:]
+			}
+			if lt.fog {
+				lt.fogPixel(px, lp)
+			} else {
+				px[0] = mul255(px[0], lp[0])
+				px[1] = mul255(px[1], lp[1])
+				px[2] = mul255(px[2], lp[2])
+			}
+			if !fast {
+				world.Set(i, j, color.RGBA{px[0], px[1], px[2], px[3]})
+			}
+		}
+	}
+}
+
+// fogPixel mixes fog into px by how dark the light map pixel lp is.
+func (lt *Lighting) fogPixel(px, lp []uint8) {
+	level := uint32(lp[0])
+	if g := uint32(lp[1]); g > level {
+		level = g
+	}
+	if b := uint32(lp[2]); b > level {
+		level = b
+	}
+	fog := [4]uint32{uint32(lt.fogColor.R), uint32(lt.fogColor.G), uint32(lt.fogColor.B), uint32(lt.fogColor.A)}
+	for k := 0; k < 4; k++ {
+		px[k] = uint8((uint32(px[k])*level + fog[k]*(255-level) + 127) / 255)
+	}
+}
+
+func mul255(a, b uint8) uint8 {
+	return uint8((uint32(a)*uint32(b) + 127) / 255)
+}
+
+var _ render.Stackable = &Lighting{}
@@ -0,0 +1,149 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"path/filepath"
+
+	"github.com/oakmound/oak/v4/fileutil"
+	"github.com/oakmound/oak/v4/oakerr"
+)
+
+type atlasRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type atlasFrame struct {
+	Filename         string    `json:"filename"`
+	Frame            atlasRect `json:"frame"`
+	Rotated          bool      `json:"rotated"`
+	Trimmed          bool      `json:"trimmed"`
+	SpriteSourceSize atlasRect `json:"spriteSourceSize"`
+	SourceSize       atlasRect `json:"sourceSize"`
+}
+
+// LoadAtlas loads an atlas file in the JSON hash or array formats written by
+// TexturePacker and similar tools. Each of its frames is cached as an image
+// under its name, and the last element of its name, for access through
+// GetSprite. Frames which were not trimmed or rotated are views into the
+// atlas' page image rather than copies. It returns the names of the frames.
+func (c *Cache) LoadAtlas(file string) ([]string, error) {
+	data, err := fileutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var atlas struct {
+		Frames json.RawMessage `json:"frames"`
+		Meta   struct {
+			Image string `json:"image"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(data, &atlas); err != nil {
+		return nil, err
+	}
+	frames, err := decodeAtlasFrames(atlas.Frames)
+	if err != nil {
+		return nil, err
+	}
+	if atlas.Meta.Image == "" {
+		return nil, oakerr.InsufficientInputs{AtLeast: 1, InputName: "meta.image"}
+	}
+	page, err := loadSpriteNoCache(filepath.Join(filepath.Dir(file), atlas.Meta.Image), 0)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(frames))
+	images := make([]*image.RGBA, len(frames))
+	for i, f := range frames {
+		if !f.stored().In(page.Bounds()) {
+			return nil, oakerr.InvalidInput{InputName: "frames." + f.Filename + ".frame"}
+		}
+		names[i] = f.Filename
+		images[i] = atlasImage(page, f)
+	}
+	c.imageLock.Lock()
+	for i, name := range names {
+		c.loadedImages[name] = images[i]
+		c.loadedImages[filepath.Base(name)] = images[i]
+	}
+	c.imageLock.Unlock()
+	return names, nil
+}
+
+// decodeAtlasFrames reads frames from an array, or from a hash in the order
+// they are written.
+func decodeAtlasFrames(raw json.RawMessage) ([]atlasFrame, error) {
+	var frames []atlasFrame
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, oakerr.InsufficientInputs{AtLeast: 1, InputName: "frames"}
+	}
+	if raw[0] == '[' {
+		if err := json.Unmarshal(raw, &frames); err != nil {
+			return nil, err
+		}
+		return frames, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		name, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var f atlasFrame
+		if err := dec.Decode(&f); err != nil {
+			return nil, err
+		}
+		f.Filename, _ = name.(string)
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+// stored returns where a frame is stored on its page. Rotated frames are
+// stored with their width and height swapped.
+func (f atlasFrame) stored() image.Rectangle {
+	r := f.Frame
+	w, h := r.W, r.H
+	if f.Rotated {
+		w, h = h, w
+	}
+	if w < 0 || h < 0 {
+		// Not a rectangle that lies in any page
+		return image.Rect(-1, -1, 0, 0)
+	}
+	return image.Rect(r.X, r.Y, r.X+w, r.Y+h)
+}
+
+// atlasImage cuts a frame out of an atlas page. Rotated frames are stored
+// turned 90 degrees clockwise, and trimmed frames have the space trimmed
+// from around them restored.
+func atlasImage(page *image.RGBA, f atlasFrame) *image.RGBA {
+	r := f.Frame
+	if !f.Rotated && !f.Trimmed {
+		return view(page, image.Rect(r.X, r.Y, r.X+r.W, r.Y+r.H))
+	}
+	w, h := f.SourceSize.W, f.SourceSize.H
+	if w == 0 || h == 0 {
+		w, h = r.W, r.H
+	}
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	off := image.Pt(f.SpriteSourceSize.X, f.SpriteSourceSize.Y)
+	for y := 0; y < r.H; y++ {
+		for x := 0; x < r.W; x++ {
+			sx, sy := r.X+x, r.Y+y
+			if f.Rotated {
+				sx, sy = r.X+r.H-1-y, r.Y+x
+			}
+			out.SetRGBA(off.X+x, off.Y+y, page.RGBAAt(sx, sy))
+		}
+	}
+	return out
+}
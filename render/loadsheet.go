@@ -22,7 +22,16 @@ func (c *Cache) LoadSheet(file string, cellSize intgeom.Point2) (*Sheet, error)
 		}
 	}
 
-	sheet, err := MakeSheet(rgba, cellSize)
+	cut := subImage
+	c.imageLock.RLock()
+	if c.atlas != nil {
+		cut = func(rgba *image.RGBA, x, y, w, h int) *image.RGBA {
+			return view(rgba, image.Rect(x, y, x+w, y+h))
+		}
+	}
+	c.imageLock.RUnlock()
+
+	sheet, err := makeSheet(rgba, cellSize, cut)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +46,10 @@ func (c *Cache) LoadSheet(file string, cellSize intgeom.Point2) (*Sheet, error)
 
 // MakeSheet converts an image into a sheet with cellSize sized sprites
 func MakeSheet(rgba *image.RGBA, cellSize intgeom.Point2) (*Sheet, error) {
+	return makeSheet(rgba, cellSize, subImage)
+}
+
+func makeSheet(rgba *image.RGBA, cellSize intgeom.Point2, cut func(*image.RGBA, int, int, int, int) *image.RGBA) (*Sheet, error) {
 
 	w := cellSize.X()
 	h := cellSize.Y()
@@ -70,7 +83,7 @@ func MakeSheet(rgba *image.RGBA, cellSize intgeom.Point2) (*Sheet, error) {
 		sheet[i] = make([]*image.RGBA, sheetH)
 		j := 0
 		for y := 0; y < bounds.Max.Y; y += h {
-			sheet[i][j] = subImage(rgba, x, y, w, h)
+			sheet[i][j] = cut(rgba, x, y, w, h)
 			j++
 		}
 		i++
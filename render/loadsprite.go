@@ -69,6 +69,11 @@ func (c *Cache) loadSprite(file string, maxFileSize int64) (*image.RGBA, error)
 		return nil, err
 	}
 	c.imageLock.Lock()
+	if c.atlas != nil {
+		if packed, err := c.atlas.Pack(rgba); err == nil {
+			rgba = packed
+		}
+	}
 	c.loadedImages[file] = rgba
 	c.loadedImages[filepath.Base(file)] = rgba
 	c.imageLock.Unlock()
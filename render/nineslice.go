@@ -0,0 +1,172 @@
+package render
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/oakmound/oak/v4/oakerr"
+	"github.com/oakmound/oak/v4/render/mod"
+)
+
+// A SliceMode is how a NineSlice fills its edges or center to its size.
+type SliceMode uint8
+
+// SliceModes.
+const (
+	// Stretch scales a slice to fill its space.
+	Stretch SliceMode = iota
+	// Tile repeats a slice to fill its space.
+	Tile
+)
+
+// A NineSlice is an image split into a three by three grid by four insets,
+// which can be drawn at any size. Its corners are drawn as they are, its edges
+// are stretched or tiled along their length, and its center is stretched or
+// tiled to fill the rest. This keeps the borders of UI panels and buttons from
+// distorting as they are resized.
+type NineSlice struct {
+	LayeredPoint
+	source                   *image.RGBA
+	left, top, right, bottom int
+	edges, center            SliceMode
+	w, h                     int
+	r                        *image.RGBA
+}
+
+// NewNineSlice returns a NineSlice of the source image with the given insets
+// from each side, w by h in size. The insets must fit within the source.
+func NewNineSlice(source *image.RGBA, left, top, right, bottom, w, h int) (*NineSlice, error) {
+	if source == nil {
+		return nil, oakerr.NilInput{InputName: "source"}
+	}
+	b := source.Bounds()
+	if left < 0 || right < 0 || left+right > b.Dx() {
+		return nil, oakerr.InvalidInput{InputName: "left"}
+	}
+	if top < 0 || bottom < 0 || top+bottom > b.Dy() {
+		return nil, oakerr.InvalidInput{InputName: "top"}
+	}
+	ns := &NineSlice{
+		LayeredPoint: NewLayeredPoint(0, 0, 0),
+		source:       source,
+		left:         left,
+		top:          top,
+		right:        right,
+		bottom:       bottom,
+	}
+	ns.Resize(w, h)
+	return ns, nil
+}
+
+// Resize redraws the NineSlice at a new size. It cannot be made smaller than
+// its insets.
+func (ns *NineSlice) Resize(w, h int) {
+	if min := ns.left + ns.right; w < min {
+		w = min
+	}
+	if min := ns.top + ns.bottom; h < min {
+		h = min
+	}
+	ns.w, ns.h = w, h
+	ns.redraw()
+}
+
+// SetModes sets whether the edges and center of the NineSlice are stretched or
+// tiled. Both are stretched by default.
+func (ns *NineSlice) SetModes(edges, center SliceMode) {
+	ns.edges, ns.center = edges, center
+	ns.redraw()
+}
+
+func (ns *NineSlice) redraw() {
+	b := ns.source.Bounds()
+	srcX := [4]int{b.Min.X, b.Min.X + ns.left, b.Max.X - ns.right, b.Max.X}
+	srcY := [4]int{b.Min.Y, b.Min.Y + ns.top, b.Max.Y - ns.bottom, b.Max.Y}
+	dstX := [4]int{0, ns.left, ns.w - ns.right, ns.w}
+	dstY := [4]int{0, ns.top, ns.h - ns.bottom, ns.h}
+	ns.r = image.NewRGBA(image.Rect(0, 0, ns.w, ns.h))
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			mode := ns.edges
+			if i == 1 && j == 1 {
+				mode = ns.center
+			}
+			fillSlice(ns.r,
+				image.Rect(dstX[i], dstY[j], dstX[i+1], dstY[j+1]),
+				ns.source,
+				image.Rect(srcX[i], srcY[j], srcX[i+1], srcY[j+1]),
+				mode)
+		}
+	}
+}
+
+// fillSlice fills dst in out with src in source. Slices drawn at their own
+// size, such as corners, are copied as they are by either mode.
+func fillSlice(out *image.RGBA, dst image.Rectangle, source *image.RGBA, src image.Rectangle, mode SliceMode) {
+	if dst.Empty() || src.Empty() {
+		return
+	}
+	if dst.Size() == src.Size() {
+		draw.Draw(out, dst, source, src.Min, draw.Src)
+		return
+	}
+	sw, sh := src.Dx(), src.Dy()
+	dw, dh := dst.Dx(), dst.Dy()
+	for y := 0; y < dh; y++ {
+		sy := y % sh
+		if mode == Stretch {
+			sy = y * sh / dh
+		}
+		for x := 0; x < dw; x++ {
+			sx := x % sw
+			if mode == Stretch {
+				sx = x * sw / dw
+			}
+			out.SetRGBA(dst.Min.X+x, dst.Min.Y+y, source.RGBAAt(src.Min.X+sx, src.Min.Y+sy))
+		}
+	}
+}
+
+// GetDims returns the size the NineSlice is drawn at.
+func (ns *NineSlice) GetDims() (int, int) {
+	return ns.w, ns.h
+}
+
+// GetRGBA returns the NineSlice as drawn at its current size.
+func (ns *NineSlice) GetRGBA() *image.RGBA {
+	return ns.r
+}
+
+// Draw draws the NineSlice at +xOff, +yOff.
+func (ns *NineSlice) Draw(buff draw.Image, xOff, yOff float64) {
+	DrawImage(buff, ns.r, int(ns.X()+xOff), int(ns.Y()+yOff))
+}
+
+// Modify alters the NineSlice's source image by the given modifications, and
+// redraws it. Modifications which change the source's size may leave its
+// insets in the wrong places.
+func (ns *NineSlice) Modify(ms ...mod.Mod) Modifiable {
+	for _, m := range ms {
+		ns.source = m(ns.source)
+	}
+	ns.redraw()
+	return ns
+}
+
+// Filter filters the NineSlice's source image, and redraws it.
+func (ns *NineSlice) Filter(fs ...mod.Filter) {
+	for _, f := range fs {
+		f(ns.source)
+	}
+	ns.redraw()
+}
+
+// Copy returns a copy of this NineSlice.
+func (ns *NineSlice) Copy() Modifiable {
+	newNs := new(NineSlice)
+	*newNs = *ns
+	newNs.source = rgbaCopy(ns.source)
+	newNs.r = rgbaCopy(ns.r)
+	newNs.LayeredPoint = ns.LayeredPoint.Copy()
+	return newNs
+}
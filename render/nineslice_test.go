@@ -0,0 +1,113 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/oakmound/oak/v4/render/mod"
+)
+
+// nineSliceSource returns a 5x5 image with a 1 pixel border: red corners,
+// green edges and a blue center, except that the center's top left pixel
+// and the top edge's leftmost pixel are white, to show tiling.
+func nineSliceSource() *image.RGBA {
+	src := image.NewRGBA(image.Rect(0, 0, 5, 5))
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			edgeX, edgeY := x == 0 || x == 4, y == 0 || y == 4
+			switch {
+			case edgeX && edgeY:
+				src.SetRGBA(x, y, color.RGBA{255, 0, 0, 255})
+			case edgeX || edgeY:
+				src.SetRGBA(x, y, color.RGBA{0, 255, 0, 255})
+			default:
+				src.SetRGBA(x, y, color.RGBA{0, 0, 255, 255})
+			}
+		}
+	}
+	src.SetRGBA(1, 1, color.RGBA{255, 255, 255, 255})
+	src.SetRGBA(1, 0, color.RGBA{255, 255, 255, 255})
+	return src
+}
+
+func TestNewNineSlice(t *testing.T) {
+	if _, err := NewNineSlice(nil, 1, 1, 1, 1, 10, 10); err == nil {
+		t.Fatalf("expected nil source to fail")
+	}
+	if _, err := NewNineSlice(nineSliceSource(), 3, 1, 3, 1, 10, 10); err == nil {
+		t.Fatalf("expected insets wider than the source to fail")
+	}
+	if _, err := NewNineSlice(nineSliceSource(), 1, -1, 1, 1, 10, 10); err == nil {
+		t.Fatalf("expected negative insets to fail")
+	}
+}
+
+func TestNineSlice(t *testing.T) {
+	var (
+		red   = color.RGBA{255, 0, 0, 255}
+		green = color.RGBA{0, 255, 0, 255}
+		blue  = color.RGBA{0, 0, 255, 255}
+		white = color.RGBA{255, 255, 255, 255}
+	)
+	ns, err := NewNineSlice(nineSliceSource(), 1, 1, 1, 1, 10, 8)
+	if err != nil {
+		t.Fatalf("new nine slice failed: %v", err)
+	}
+	if w, h := ns.GetDims(); w != 10 || h != 8 {
+		t.Fatalf("expected 10x8, got %vx%v", w, h)
+	}
+	rgba := ns.GetRGBA()
+	for _, corner := range []image.Point{{0, 0}, {9, 0}, {0, 7}, {9, 7}} {
+		if rgba.RGBAAt(corner.X, corner.Y) != red {
+			t.Fatalf("corner %v should be red", corner)
+		}
+	}
+	if rgba.RGBAAt(0, 4) != green || rgba.RGBAAt(9, 4) != green || rgba.RGBAAt(5, 7) != green {
+		t.Fatalf("edges should be green")
+	}
+	// Stretching the 3 pixel center to 8 pixels leaves the white pixel 2 or
+	// 3 pixels wide.
+	if rgba.RGBAAt(1, 1) != white || rgba.RGBAAt(2, 1) != white || rgba.RGBAAt(4, 1) == white || rgba.RGBAAt(5, 4) != blue {
+		t.Fatalf("center should be stretched")
+	}
+
+	ns.SetModes(Tile, Tile)
+	rgba = ns.GetRGBA()
+	if rgba.RGBAAt(1, 1) != white || rgba.RGBAAt(2, 1) == white || rgba.RGBAAt(4, 1) != white || rgba.RGBAAt(7, 4) != white {
+		t.Fatalf("center should be tiled")
+	}
+	if rgba.RGBAAt(1, 0) != white || rgba.RGBAAt(4, 0) != white || rgba.RGBAAt(2, 0) != green {
+		t.Fatalf("edges should be tiled")
+	}
+
+	ns.Resize(1, 1)
+	if w, h := ns.GetDims(); w != 2 || h != 2 {
+		t.Fatalf("nine slices should not shrink past their insets, got %vx%v", w, h)
+	}
+	ns.Resize(20, 20)
+	cp := ns.Copy().(*NineSlice)
+	cp.Resize(4, 4)
+	if w, _ := ns.GetDims(); w != 20 {
+		t.Fatalf("resizing a copy should not resize the original")
+	}
+
+	ns.Filter(func(rgba *image.RGBA) {
+		draw.Draw(rgba, rgba.Bounds(), image.NewUniform(color.RGBA{0, 0, 0, 255}), image.Point{}, draw.Src)
+	})
+	if ns.GetRGBA().RGBAAt(10, 10) != (color.RGBA{0, 0, 0, 255}) {
+		t.Fatalf("filters should apply to the source and redraw")
+	}
+	ns.Modify(mod.FlipX)
+	if w, h := ns.GetDims(); w != 20 || h != 20 {
+		t.Fatalf("modify should keep size")
+	}
+
+	buff := image.NewRGBA(image.Rect(0, 0, 30, 30))
+	ns.SetPos(5, 5)
+	ns.Draw(buff, 1, 1)
+	if buff.RGBAAt(6, 6) != (color.RGBA{0, 0, 0, 255}) || buff.RGBAAt(5, 5) != (color.RGBA{}) {
+		t.Fatalf("nine slice drawn to wrong position")
+	}
+}
@@ -61,8 +61,13 @@ func (cp *ColorParticle) DrawOffsetGen(generator Generator, buff draw.Image, xOf
 
 	for i := 0; i < size; i++ {
 		for j := 0; j < size; j++ {
-			if gen.Shape.In(i, j, size) {
+			if !gen.Shape.In(i, j, size) {
+				continue
+			}
+			if gen.BlendMode == render.BlendOver {
 				buff.Set(xOffi+i, yOffi+j, c)
+			} else {
+				render.BlendAt(buff, xOffi+i, yOffi+j, c, gen.BlendMode)
 			}
 		}
 	}
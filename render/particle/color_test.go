@@ -45,3 +45,22 @@ func TestColorParticle(t *testing.T) {
 		t.Fatalf("get particle size not particle-specified")
 	}
 }
+
+func TestColorParticleBlend(t *testing.T) {
+	g := NewColorGenerator(
+		Color(color.RGBA{100, 0, 0, 255}, color.RGBA{}, color.RGBA{100, 0, 0, 255}, color.RGBA{}),
+		Size(span.NewConstant(4)),
+		EndSize(span.NewConstant(4)),
+		Blend(render.BlendAdd),
+	)
+	src := g.Generate(0)
+	src.addParticles()
+	p := src.particles[0].(*ColorParticle)
+
+	buff := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	p.Draw(buff, 0, 0)
+	p.Draw(buff, 0, 0)
+	if got := buff.RGBAAt(0, 0); got != (color.RGBA{200, 0, 0, 255}) {
+		t.Fatalf("additive particles should add up, got %v", got)
+	}
+}
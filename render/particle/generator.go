@@ -60,6 +60,10 @@ type BaseGenerator struct {
 	EndFunc       func(Particle)
 	LayerFunc     func(physics.Vector) int
 	ParticleLimit int
+	// BlendMode is how particles are blended with what is drawn beneath
+	// them. Color and gradient particles replace the pixels beneath them
+	// when it is render.BlendOver, as they always have.
+	BlendMode render.BlendMode
 }
 
 // GetBaseGenerator returns this
@@ -118,6 +118,13 @@ func Limit(limit int) func(Generator) {
 	}
 }
 
+// Blend sets how particles are blended with what is drawn beneath them.
+func Blend(mode render.BlendMode) func(Generator) {
+	return func(g Generator) {
+		g.GetBaseGenerator().BlendMode = mode
+	}
+}
+
 // DrawStack sets the current drawstack so that we dont use the globaldrawstack
 func DrawStack(drawStack *render.DrawStack) func(Generator) {
 	return func(g Generator) {
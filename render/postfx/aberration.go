@@ -0,0 +1,76 @@
+package postfx
+
+import (
+	"image"
+	"math"
+	"time"
+)
+
+// ChromaticAberration splits the red and blue of the screen apart towards
+// its edges, like a cheap lens.
+type ChromaticAberration struct {
+	// Offset is how many pixels red and blue are each moved at the edges of
+	// the screen.
+	Offset float64
+	// Pulse is how much Offset rises and falls over time.
+	Pulse float64
+	// Speed is how many times a second the aberration pulses.
+	Speed float64
+
+	scratch []uint8
+	// Red and blue read from these columns and rows.
+	redX, blueX, redY, blueY []int
+}
+
+// Apply splits the colors of buf.
+func (ca *ChromaticAberration) Apply(buf *image.RGBA, t time.Duration) {
+	w, h := buf.Rect.Dx(), buf.Rect.Dy()
+	offset := ca.Offset
+	if ca.Pulse != 0 {
+		offset += ca.Pulse * math.Sin(2*math.Pi*ca.Speed*t.Seconds())
+	}
+	if offset == 0 || w == 0 || h == 0 {
+		return
+	}
+	// Red is pulled out from the center and blue pulled in, by an amount
+	// growing towards the edges.
+	scale := offset / math.Max(float64(w), float64(h)) * 2
+	ca.redX = aberrate(ca.redX, w, 1+scale)
+	ca.blueX = aberrate(ca.blueX, w, 1-scale)
+	ca.redY = aberrate(ca.redY, h, 1+scale)
+	ca.blueY = aberrate(ca.blueY, h, 1-scale)
+	ca.scratch = grow(ca.scratch, len(buf.Pix))
+	copy(ca.scratch, buf.Pix)
+	src := ca.scratch
+	parallelRows(h, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			row := buf.Pix[y*buf.Stride:]
+			redRow := src[ca.redY[y]*buf.Stride:]
+			blueRow := src[ca.blueY[y]*buf.Stride:]
+			for x := 0; x < w; x++ {
+				row[x*4] = redRow[ca.redX[x]*4]
+				row[x*4+2] = blueRow[ca.blueX[x]*4+2]
+			}
+		}
+	})
+}
+
+// aberrate returns, for each of n pixels, the pixel scale times as far from
+// the center.
+func aberrate(s []int, n int, scale float64) []int {
+	if cap(s) < n {
+		s = make([]int, n)
+	}
+	s = s[:n]
+	c := float64(n) / 2
+	for i := range s {
+		v := int(c + (float64(i)+.5-c)/scale)
+		if v < 0 {
+			v = 0
+		} else if v >= n {
+			v = n - 1
+		}
+		s[i] = v
+	}
+	return s
+}
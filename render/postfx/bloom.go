@@ -0,0 +1,144 @@
+package postfx
+
+import (
+	"image"
+	"math"
+	"time"
+)
+
+// Bloom makes bright parts of the screen glow onto their surroundings.
+type Bloom struct {
+	// Threshold is how bright, by its brightest channel, a pixel must be to
+	// glow.
+	Threshold uint8
+	// Sigma is how far glows spread, in pixels.
+	Sigma float64
+	// Intensity scales how bright glows are. An Intensity of 0 is treated as
+	// 1.
+	Intensity float64
+	// Downsample is how many pixels wide and tall each pixel of the glow is
+	// found at. Larger values are faster and blurrier. A Downsample of 0 is
+	// treated as 4.
+	Downsample int
+
+	gauss         gaussian
+	glow, scratch []uint8
+	sums          []int32
+	xs, ys        []sample
+}
+
+// A sample is where a pixel reads from in a smaller image: between x0 and
+// x1, weighted towards x1 by w out of 256.
+type sample struct {
+	x0, x1 int
+	w      int32
+}
+
+// Apply adds glows to buf.
+func (b *Bloom) Apply(buf *image.RGBA, _ time.Duration) {
+	w, h := buf.Rect.Dx(), buf.Rect.Dy()
+	d := b.Downsample
+	if d <= 0 {
+		d = 4
+	}
+	sw, sh := (w+d-1)/d, (h+d-1)/d
+	if sw == 0 || sh == 0 {
+		return
+	}
+	b.glow = grow(b.glow, sw*sh*4)
+	b.scratch = grow(b.scratch, sw*sh*4)
+	b.brightPass(buf, d, sw, sh)
+	if radii := b.gauss.radii(b.Sigma / float64(d)); radii != nil {
+		b.sums = blurPix(b.glow, b.scratch, b.sums, sw, sh, sw*4, radii)
+	}
+	b.xs = upsamples(b.xs, w, sw, d)
+	b.ys = upsamples(b.ys, h, sh, d)
+	intensity := int32(unit(b.Intensity) * 256)
+	parallelRows(h, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			sy := b.ys[y]
+			row0 := b.glow[sy.x0*sw*4:]
+			row1 := b.glow[sy.x1*sw*4:]
+			out := buf.Pix[y*buf.Stride:]
+			for x, sx := range b.xs {
+				for c := 0; c < 3; c++ {
+					top := int32(row0[sx.x0*4+c])*(256-sx.w) + int32(row0[sx.x1*4+c])*sx.w
+					bot := int32(row1[sx.x0*4+c])*(256-sx.w) + int32(row1[sx.x1*4+c])*sx.w
+					g := (top*(256-sy.w) + bot*sy.w) >> 16
+					out[x*4+c] = clamp255(int32(out[x*4+c]) + (g*intensity)>>8)
+				}
+			}
+		}
+	})
+}
+
+// brightPass fills the glow buffer with the parts of buf above the threshold,
+// averaged over d by d blocks.
+func (b *Bloom) brightPass(buf *image.RGBA, d, sw, sh int) {
+	w, h := buf.Rect.Dx(), buf.Rect.Dy()
+	threshold := int32(b.Threshold)
+	over := 255 - threshold
+	if over == 0 {
+		over = 1
+	}
+	parallelRows(sh, func(y0, y1 int) {
+		for sy := y0; sy < y1; sy++ {
+			for sx := 0; sx < sw; sx++ {
+				var sum [3]int32
+				n := int32(0)
+				for y := sy * d; y < sy*d+d && y < h; y++ {
+					row := buf.Pix[y*buf.Stride:]
+					for x := sx * d; x < sx*d+d && x < w; x++ {
+						p := row[x*4 : x*4+3 : x*4+3]
+						n++
+						bright := int32(p[0])
+						if g := int32(p[1]); g > bright {
+							bright = g
+						}
+						if bl := int32(p[2]); bl > bright {
+							bright = bl
+						}
+						if bright <= threshold {
+							continue
+						}
+						// Pixels glow more the further over the threshold they are.
+						f := (bright - threshold) * 256 / over
+						sum[0] += int32(p[0]) * f >> 8
+						sum[1] += int32(p[1]) * f >> 8
+						sum[2] += int32(p[2]) * f >> 8
+					}
+				}
+				o := b.glow[(sy*sw+sx)*4:]
+				o[0] = uint8(sum[0] / n)
+				o[1] = uint8(sum[1] / n)
+				o[2] = uint8(sum[2] / n)
+				o[3] = 255
+			}
+		}
+	})
+}
+
+// upsamples returns, for each of n pixels, where it reads from in an image
+// of sn pixels, each covering d pixels.
+func upsamples(s []sample, n, sn, d int) []sample {
+	if cap(s) < n {
+		s = make([]sample, n)
+	}
+	s = s[:n]
+	for i := range s {
+		f := (float64(i)+.5)/float64(d) - .5
+		if f < 0 {
+			f = 0
+		}
+		x0 := int(math.Floor(f))
+		x1 := x0 + 1
+		if x1 >= sn {
+			x1 = sn - 1
+		}
+		if x0 >= sn {
+			x0 = sn - 1
+		}
+		s[i] = sample{x0, x1, int32((f - float64(x0)) * 256)}
+	}
+	return s
+}
@@ -0,0 +1,165 @@
+package postfx
+
+import (
+	"image"
+	"math"
+	"time"
+)
+
+// Blur softens the screen with a Gaussian blur. The blur is approximated by
+// three box blurs, so it takes as long whatever its sigma.
+type Blur struct {
+	// Sigma is the standard deviation of the blur, in pixels.
+	Sigma float64
+
+	gauss   gaussian
+	scratch []uint8
+	sums    []int32
+}
+
+// Apply blurs buf.
+func (b *Blur) Apply(buf *image.RGBA, _ time.Duration) {
+	radii := b.gauss.radii(b.Sigma)
+	if radii == nil {
+		return
+	}
+	b.scratch = grow(b.scratch, len(buf.Pix))
+	b.sums = blurPix(buf.Pix, b.scratch, b.sums, buf.Rect.Dx(), buf.Rect.Dy(), buf.Stride, radii)
+}
+
+// A gaussian caches the box blurs approximating a Gaussian blur for the last
+// sigma asked for.
+type gaussian struct {
+	sigma float64
+	r     []int
+}
+
+// radii returns the radii of three box blurs which together approximate a
+// Gaussian blur of sigma, or nil if sigma blurs nothing.
+func (g *gaussian) radii(sigma float64) []int {
+	if sigma <= 0 {
+		return nil
+	}
+	if g.r != nil && g.sigma == sigma {
+		return g.r
+	}
+	g.sigma = sigma
+	// From Kovesi, "Fast Almost-Gaussian Filtering".
+	const n = 3
+	ideal := math.Sqrt(12*sigma*sigma/n + 1)
+	lower := int(ideal)
+	if lower%2 == 0 {
+		lower--
+	}
+	upper := lower + 2
+	wl := float64(lower)
+	m := int(math.Round((12*sigma*sigma - n*wl*wl - 4*n*wl - 3*n) / (-4*wl - 4)))
+	g.r = g.r[:0]
+	for i := 0; i < n; i++ {
+		size := upper
+		if i < m {
+			size = lower
+		}
+		if size > 1 {
+			g.r = append(g.r, size/2)
+		}
+	}
+	if len(g.r) == 0 {
+		g.r = nil
+	}
+	return g.r
+}
+
+// blurPix blurs the w by h RGBA pixels in pix with box blurs of each radius,
+// using tmp, which must be as long as pix, as scratch space. Column sums are
+// kept in sums, grown to fit and returned.
+func blurPix(pix, tmp []uint8, sums []int32, w, h, stride int, radii []int) []int32 {
+	n, band := bands(h)
+	sums = grow(sums, n*w*4)
+	for _, r := range radii {
+		parallelRows(h, func(y0, y1 int) {
+			for y := y0; y < y1; y++ {
+				boxRow(tmp[y*stride:y*stride+w*4], pix[y*stride:y*stride+w*4], r)
+			}
+		})
+		parallelRows(h, func(y0, y1 int) {
+			b := y0 / band
+			boxColumns(pix, tmp, sums[b*w*4:(b+1)*w*4], w, h, stride, r, y0, y1)
+		})
+	}
+	return sums
+}
+
+// boxRow box blurs the RGBA pixels of src into dst.
+func boxRow(dst, src []uint8, r int) {
+	n := len(src) / 4
+	recip := reciprocal(r)
+	last := (n - 1) * 4
+	at := func(i int) int {
+		if i < 0 {
+			return 0
+		}
+		if i > n-1 {
+			return last
+		}
+		return i * 4
+	}
+	var sum [4]int32
+	for i := -r; i <= r; i++ {
+		p := at(i)
+		for c := 0; c < 4; c++ {
+			sum[c] += int32(src[p+c])
+		}
+	}
+	for x := 0; x < n; x++ {
+		o := dst[x*4 : x*4+4 : x*4+4]
+		add, sub := at(x+r+1), at(x-r)
+		for c := 0; c < 4; c++ {
+			o[c] = divide(sum[c], recip)
+			sum[c] += int32(src[add+c]) - int32(src[sub+c])
+		}
+	}
+}
+
+// boxColumns box blurs rows y0 to y1 of src's columns into dst, working a row
+// at a time and keeping each column's sum in sums.
+func boxColumns(dst, src []uint8, sums []int32, w, h, stride, r, y0, y1 int) {
+	recip := reciprocal(r)
+	row := func(y int) []uint8 {
+		if y < 0 {
+			y = 0
+		} else if y > h-1 {
+			y = h - 1
+		}
+		return src[y*stride : y*stride+w*4]
+	}
+	for i := range sums {
+		sums[i] = 0
+	}
+	for y := y0 - r; y <= y0+r; y++ {
+		for i, v := range row(y) {
+			sums[i] += int32(v)
+		}
+	}
+	for y := y0; y < y1; y++ {
+		out := dst[y*stride : y*stride+w*4]
+		add, sub := row(y+r+1), row(y-r)
+		for i := range out {
+			out[i] = divide(sums[i], recip)
+			sums[i] += int32(add[i]) - int32(sub[i])
+		}
+	}
+}
+
+// reciprocal returns 1/(2r+1), the weight of each pixel in a box blur of
+// radius r, in 8.24 fixed point.
+func reciprocal(r int) uint64 {
+	d := uint64(2*r + 1)
+	return (1<<24 + d/2) / d
+}
+
+// divide divides a box blur's sum by its size, given its reciprocal. This is
+// much faster than dividing directly.
+func divide(sum int32, recip uint64) uint8 {
+	return uint8((uint64(sum)*recip + 1<<23) >> 24)
+}
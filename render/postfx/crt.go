@@ -0,0 +1,117 @@
+package postfx
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"time"
+)
+
+// CRT makes the screen look like an old tube television, with dark
+// scanlines and a bulging, curved screen.
+type CRT struct {
+	// Scanlines is how dark scanlines are, from 0 to 1.
+	Scanlines float64
+	// LineHeight is how many pixels apart scanlines are. A LineHeight of 0
+	// is treated as 2.
+	LineHeight int
+	// Roll is how many pixels a second scanlines move down the screen.
+	Roll float64
+	// Curvature is how much the screen bulges. 0 is flat, and .1 is
+	// noticeably curved.
+	Curvature float64
+	// Border is the color shown around a curved screen.
+	Border color.RGBA
+
+	scratch []uint8
+	// warp holds the offset into the screen each pixel reads from, or -1 for
+	// the border.
+	warp       []int32
+	warpW      int
+	warpH      int
+	warpStride int
+	warpCurve  float64
+}
+
+// Apply draws scanlines on buf, then curves it.
+func (crt *CRT) Apply(buf *image.RGBA, t time.Duration) {
+	w, h := buf.Rect.Dx(), buf.Rect.Dy()
+	if w == 0 || h == 0 {
+		return
+	}
+	crt.scanlines(buf, t)
+	if crt.Curvature == 0 {
+		return
+	}
+	warp := crt.warpFor(w, h, buf.Stride)
+	crt.scratch = grow(crt.scratch, len(buf.Pix))
+	copy(crt.scratch, buf.Pix)
+	border := [4]uint8{crt.Border.R, crt.Border.G, crt.Border.B, crt.Border.A}
+	parallelRows(h, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			row := buf.Pix[y*buf.Stride:]
+			for x, src := range warp[y*w : y*w+w] {
+				if src < 0 {
+					copy(row[x*4:x*4+4], border[:])
+					continue
+				}
+				copy(row[x*4:x*4+4], crt.scratch[src:src+4])
+			}
+		}
+	})
+}
+
+// scanlines darkens every LineHeight-th row of buf.
+func (crt *CRT) scanlines(buf *image.RGBA, t time.Duration) {
+	dark := int32(math.Max(0, math.Min(1, crt.Scanlines)) * 256)
+	if dark == 0 {
+		return
+	}
+	lh := crt.LineHeight
+	if lh <= 0 {
+		lh = 2
+	}
+	roll := (int(crt.Roll*t.Seconds())%lh + lh) % lh
+	keep := 256 - dark
+	w := buf.Rect.Dx()
+	for y := (lh - 1 + roll) % lh; y < buf.Rect.Dy(); y += lh {
+		row := buf.Pix[y*buf.Stride : y*buf.Stride+w*4]
+		for x := 0; x < len(row); x += 4 {
+			row[x] = uint8(int32(row[x]) * keep >> 8)
+			row[x+1] = uint8(int32(row[x+1]) * keep >> 8)
+			row[x+2] = uint8(int32(row[x+2]) * keep >> 8)
+		}
+	}
+}
+
+// warpFor returns where each pixel of a curved w by h screen reads from,
+// recalculating only when the size or curvature change.
+func (crt *CRT) warpFor(w, h, stride int) []int32 {
+	if crt.warp != nil && crt.warpW == w && crt.warpH == h && crt.warpStride == stride && crt.warpCurve == crt.Curvature {
+		return crt.warp
+	}
+	if cap(crt.warp) < w*h {
+		crt.warp = make([]int32, w*h)
+	}
+	crt.warp = crt.warp[:w*h]
+	crt.warpW, crt.warpH, crt.warpStride, crt.warpCurve = w, h, stride, crt.Curvature
+	k := crt.Curvature
+	for y := 0; y < h; y++ {
+		v := (float64(y)+.5)/float64(h)*2 - 1
+		for x := 0; x < w; x++ {
+			u := (float64(x)+.5)/float64(w)*2 - 1
+			// Points further from the center read from further out, so the
+			// middle of the screen bulges towards the viewer.
+			su := u * (1 + k*v*v)
+			sv := v * (1 + k*u*u)
+			if su < -1 || su >= 1 || sv < -1 || sv >= 1 {
+				crt.warp[y*w+x] = -1
+				continue
+			}
+			sx := int((su + 1) / 2 * float64(w))
+			sy := int((sv + 1) / 2 * float64(h))
+			crt.warp[y*w+x] = int32(sy*stride + sx*4)
+		}
+	}
+	return crt.warp
+}
@@ -0,0 +1,5 @@
+// Package postfx provides post-processing effects for the screen buffer,
+// like CRT scanlines, bloom and color grading, which can animate over time.
+// Effects are chained together and given to a window through
+// Window.SetDrawFilter with Chain.Filter.
+package postfx
@@ -0,0 +1,79 @@
+package postfx
+
+import (
+	"image"
+	"image/color"
+	"sync"
+	"time"
+)
+
+// Flash briefly washes the screen in a color, fading out, each time it is
+// triggered.
+type Flash struct {
+	// Color is the color of the flash. Its alpha is ignored.
+	Color color.RGBA
+	// Strength is how opaque the flash starts, from 0 to 1. A Strength of 0
+	// is treated as 1.
+	Strength float64
+	// Duration is how long the flash takes to fade out.
+	Duration time.Duration
+
+	lock    sync.Mutex
+	pending bool
+	active  bool
+	started time.Duration
+}
+
+// Trigger starts the flash at the next Apply, restarting it if it is
+// already fading.
+func (f *Flash) Trigger() {
+	f.lock.Lock()
+	f.pending = true
+	f.lock.Unlock()
+}
+
+// Active reports whether the flash is showing.
+func (f *Flash) Active() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.active || f.pending
+}
+
+// Apply draws the flash over buf, if it is active.
+func (f *Flash) Apply(buf *image.RGBA, t time.Duration) {
+	f.lock.Lock()
+	if f.pending {
+		f.pending = false
+		f.active = true
+		f.started = t
+	}
+	elapsed := t - f.started
+	if f.active && (f.Duration <= 0 || elapsed >= f.Duration) {
+		f.active = false
+	}
+	active := f.active
+	f.lock.Unlock()
+	if !active {
+		return
+	}
+	fade := 1 - float64(elapsed)/float64(f.Duration)
+	a := int32(unit(f.Strength) * fade * 256)
+	if a <= 0 {
+		return
+	}
+	if a > 256 {
+		a = 256
+	}
+	fill := [3]int32{int32(f.Color.R) * a, int32(f.Color.G) * a, int32(f.Color.B) * a}
+	w := buf.Rect.Dx()
+	parallelRows(buf.Rect.Dy(), func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			row := buf.Pix[y*buf.Stride : y*buf.Stride+w*4]
+			for x := 0; x < len(row); x += 4 {
+				row[x] = uint8((int32(row[x])*(256-a) + fill[0]) >> 8)
+				row[x+1] = uint8((int32(row[x+1])*(256-a) + fill[1]) >> 8)
+				row[x+2] = uint8((int32(row[x+2])*(256-a) + fill[2]) >> 8)
+			}
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package postfx
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/oakmound/oak/v4/oakerr"
+)
+
+// A LUT is a color lookup table, mapping each color to a graded one. Colors
+// between the table's entries are interpolated.
+type LUT struct {
+	size int
+	// table holds size^3 RGB triples, red changing fastest, then green,
+	// then blue.
+	table []uint8
+	cells [256]lutCell
+}
+
+// NewLUT reads a LUT from an image laid out as a horizontal strip of size
+// squares, each size pixels wide and tall, as most image editors export
+// them. Within each square red increases rightwards and green downwards,
+// and each square has more blue than the last.
+func NewLUT(img image.Image) (*LUT, error) {
+	b := img.Bounds()
+	size := b.Dy()
+	if size < 2 || b.Dx() != size*size {
+		return nil, oakerr.InvalidInput{InputName: "img"}
+	}
+	l := &LUT{size: size, table: make([]uint8, size*size*size*3)}
+	for bl := 0; bl < size; bl++ {
+		for g := 0; g < size; g++ {
+			for r := 0; r < size; r++ {
+				c := color.RGBAModel.Convert(img.At(b.Min.X+bl*size+r, b.Min.Y+g)).(color.RGBA)
+				i := l.index(r, g, bl)
+				l.table[i], l.table[i+1], l.table[i+2] = c.R, c.G, c.B
+			}
+		}
+	}
+	l.setCells()
+	return l, nil
+}
+
+// NewLUTFunc builds a LUT of the given size, at least 2, by sampling fn.
+func NewLUTFunc(size int, fn func(color.RGBA) color.RGBA) *LUT {
+	if size < 2 {
+		size = 2
+	}
+	l := &LUT{size: size, table: make([]uint8, size*size*size*3)}
+	step := func(i int) uint8 {
+		return uint8(i * 255 / (size - 1))
+	}
+	for b := 0; b < size; b++ {
+		for g := 0; g < size; g++ {
+			for r := 0; r < size; r++ {
+				c := fn(color.RGBA{step(r), step(g), step(b), 255})
+				i := l.index(r, g, b)
+				l.table[i], l.table[i+1], l.table[i+2] = c.R, c.G, c.B
+			}
+		}
+	}
+	l.setCells()
+	return l
+}
+
+// Size returns how many entries the LUT has along each channel.
+func (l *LUT) Size() int {
+	return l.size
+}
+
+func (l *LUT) index(r, g, b int) int {
+	return ((b*l.size+g)*l.size + r) * 3
+}
+
+// Lookup returns the graded color for r, g, b.
+func (l *LUT) Lookup(r, g, b uint8) (uint8, uint8, uint8) {
+	cr, cg, cb := l.cells[r], l.cells[g], l.cells[b]
+	sg, sb := l.size*3, l.size*l.size*3
+	base := cr.i*3 + cg.i*sg + cb.i*sb
+	dr, dg, db := cr.step*3, cg.step*sg, cb.step*sb
+	// The eight corners of the cube the color lies in.
+	c000 := l.table[base : base+3 : base+3]
+	c100 := l.table[base+dr : base+dr+3 : base+dr+3]
+	c010 := l.table[base+dg : base+dg+3 : base+dg+3]
+	c110 := l.table[base+dg+dr : base+dg+dr+3 : base+dg+dr+3]
+	c001 := l.table[base+db : base+db+3 : base+db+3]
+	c101 := l.table[base+db+dr : base+db+dr+3 : base+db+dr+3]
+	c011 := l.table[base+db+dg : base+db+dg+3 : base+db+dg+3]
+	c111 := l.table[base+db+dg+dr : base+db+dg+dr+3 : base+db+dg+dr+3]
+	fr, fg, fb := cr.f, cg.f, cb.f
+	var out [3]uint8
+	for c := 0; c < 3; c++ {
+		x00 := int32(c000[c])*(256-fr) + int32(c100[c])*fr
+		x10 := int32(c010[c])*(256-fr) + int32(c110[c])*fr
+		x01 := int32(c001[c])*(256-fr) + int32(c101[c])*fr
+		x11 := int32(c011[c])*(256-fr) + int32(c111[c])*fr
+		lo := (x00*(256-fg) + x10*fg + 128) >> 8
+		hi := (x01*(256-fg) + x11*fg + 128) >> 8
+		out[c] = uint8((lo*(256-fb) + hi*fb + 1<<15) >> 16)
+	}
+	return out[0], out[1], out[2]
+}
+
+// A lutCell is where a channel value lies in a LUT: between entry i and
+// i+step, f out of 256 of the way to the second.
+type lutCell struct {
+	i, step int
+	f       int32
+}
+
+// setCells finds where each channel value lies in the LUT.
+func (l *LUT) setCells() {
+	for v := range l.cells {
+		pos := (v*(l.size-1)*256 + 127) / 255
+		c := lutCell{i: pos >> 8, step: 1, f: int32(pos & 255)}
+		if c.i >= l.size-1 {
+			c.i, c.step = l.size-1, 0
+		}
+		l.cells[v] = c
+	}
+}
+
+// Grade color grades the screen through a LUT. The screen is treated as
+// opaque.
+type Grade struct {
+	LUT *LUT
+	// Strength is how much of the graded color replaces the original, from
+	// 0 to 1. A Strength of 0 is treated as 1.
+	Strength float64
+}
+
+// Apply grades buf.
+func (gr *Grade) Apply(buf *image.RGBA, _ time.Duration) {
+	if gr.LUT == nil {
+		return
+	}
+	s := int32(unit(gr.Strength) * 256)
+	w := buf.Rect.Dx()
+	parallelRows(buf.Rect.Dy(), func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			row := buf.Pix[y*buf.Stride : y*buf.Stride+w*4]
+			// Screens are mostly runs of one color, so the last pixel's
+			// result is kept to skip looking it up again.
+			var last, graded [3]uint8
+			cached := false
+			for x := 0; x < len(row); x += 4 {
+				p := row[x : x+3 : x+3]
+				in := [3]uint8{p[0], p[1], p[2]}
+				if !cached || in != last {
+					r, g, b := gr.LUT.Lookup(in[0], in[1], in[2])
+					if s < 256 {
+						r = uint8((int32(in[0])*(256-s) + int32(r)*s) >> 8)
+						g = uint8((int32(in[1])*(256-s) + int32(g)*s) >> 8)
+						b = uint8((int32(in[2])*(256-s) + int32(b)*s) >> 8)
+					}
+					last, graded, cached = in, [3]uint8{r, g, b}, true
+				}
+				p[0], p[1], p[2] = graded[0], graded[1], graded[2]
+			}
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package postfx
+
+import (
+	"image"
+	"time"
+)
+
+// Pixelate averages the screen into large blocks.
+type Pixelate struct {
+	// Size is how many pixels wide and tall each block is. Sizes below 2 do
+	// nothing.
+	Size int
+}
+
+// Apply pixelates buf.
+func (p *Pixelate) Apply(buf *image.RGBA, _ time.Duration) {
+	d := p.Size
+	if d < 2 {
+		return
+	}
+	w, h := buf.Rect.Dx(), buf.Rect.Dy()
+	blocks := (h + d - 1) / d
+	parallelRows(blocks, func(b0, b1 int) {
+		for by := b0; by < b1; by++ {
+			yMax := by*d + d
+			if yMax > h {
+				yMax = h
+			}
+			for bx := 0; bx < w; bx += d {
+				xMax := bx + d
+				if xMax > w {
+					xMax = w
+				}
+				var sum [4]int
+				for y := by * d; y < yMax; y++ {
+					row := buf.Pix[y*buf.Stride:]
+					for x := bx; x < xMax; x++ {
+						for c := 0; c < 4; c++ {
+							sum[c] += int(row[x*4+c])
+						}
+					}
+				}
+				n := (yMax - by*d) * (xMax - bx)
+				avg := [4]uint8{uint8(sum[0] / n), uint8(sum[1] / n), uint8(sum[2] / n), uint8(sum[3] / n)}
+				for y := by * d; y < yMax; y++ {
+					row := buf.Pix[y*buf.Stride:]
+					for x := bx; x < xMax; x++ {
+						copy(row[x*4:x*4+4], avg[:])
+					}
+				}
+			}
+		}
+	})
+}
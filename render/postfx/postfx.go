@@ -0,0 +1,149 @@
+package postfx
+
+import (
+	"image"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/oakmound/oak/v4/render/mod"
+)
+
+// An Effect modifies a screen buffer in place. t is how long the effect has
+// been running, for effects which animate.
+type Effect interface {
+	Apply(buf *image.RGBA, t time.Duration)
+}
+
+// An EffectFunc is a function acting as an Effect.
+type EffectFunc func(buf *image.RGBA, t time.Duration)
+
+// Apply calls f.
+func (f EffectFunc) Apply(buf *image.RGBA, t time.Duration) {
+	f(buf, t)
+}
+
+// FromFilter returns an Effect which ignores time and applies f.
+func FromFilter(f mod.Filter) Effect {
+	return EffectFunc(func(buf *image.RGBA, _ time.Duration) {
+		f(buf)
+	})
+}
+
+// A Chain applies a list of effects in order.
+type Chain struct {
+	lock    sync.Mutex
+	effects []Effect
+	start   time.Time
+}
+
+// NewChain returns a Chain of the given effects, whose clock starts now.
+func NewChain(effects ...Effect) *Chain {
+	return &Chain{
+		effects: effects,
+		start:   time.Now(),
+	}
+}
+
+// Add appends effects to the end of the chain.
+func (c *Chain) Add(effects ...Effect) {
+	c.lock.Lock()
+	c.effects = append(c.effects, effects...)
+	c.lock.Unlock()
+}
+
+// SetEffects replaces the effects in the chain.
+func (c *Chain) SetEffects(effects ...Effect) {
+	c.lock.Lock()
+	c.effects = effects
+	c.lock.Unlock()
+}
+
+// Apply applies each effect in the chain to buf.
+func (c *Chain) Apply(buf *image.RGBA, t time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, e := range c.effects {
+		e.Apply(buf, t)
+	}
+}
+
+// Filter returns a mod.Filter applying the chain at the time since it was
+// created, for Window.SetDrawFilter.
+func (c *Chain) Filter() mod.Filter {
+	return func(buf *image.RGBA) {
+		c.Apply(buf, time.Since(c.start))
+	}
+}
+
+// minBand is the fewest rows worth giving their own goroutine.
+const minBand = 32
+
+// parallelRows splits the rows from 0 to h into bands and calls fn on each
+// band, concurrently when there are enough rows to be worth it.
+func parallelRows(h int, fn func(y0, y1 int)) {
+	n, band := bands(h)
+	if n == 1 {
+		fn(0, h)
+		return
+	}
+	var wg sync.WaitGroup
+	for y := 0; y < h; y += band {
+		y1 := y + band
+		if y1 > h {
+			y1 = h
+		}
+		wg.Add(1)
+		go func(y0, y1 int) {
+			fn(y0, y1)
+			wg.Done()
+		}(y, y1)
+	}
+	wg.Wait()
+}
+
+// bands returns how many bands parallelRows splits h rows into, and how many
+// rows are in each band but the last. The band starting at row y is band
+// y/band.
+func bands(h int) (n, band int) {
+	n = runtime.GOMAXPROCS(0)
+	if max := h / minBand; n > max {
+		n = max
+	}
+	if n <= 1 {
+		if h < 1 {
+			return 1, 1
+		}
+		return 1, h
+	}
+	band = (h + n - 1) / n
+	return (h + band - 1) / band, band
+}
+
+// grow returns buf resized to n elements, reallocating only when it is too
+// small.
+func grow[T any](buf []T, n int) []T {
+	if cap(buf) < n {
+		return make([]T, n)
+	}
+	return buf[:n]
+}
+
+// clamp255 clamps v to a byte.
+func clamp255(v int32) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
+
+// unit returns v, or 1 if v is 0.
+func unit(v float64) float64 {
+	if v == 0 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,232 @@
+package postfx
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/oakmound/oak/v4/render/mod"
+)
+
+func solid(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:], []uint8{c.R, c.G, c.B, c.A})
+	}
+	return img
+}
+
+var (
+	black = color.RGBA{0, 0, 0, 255}
+	white = color.RGBA{255, 255, 255, 255}
+	gray  = color.RGBA{100, 100, 100, 255}
+)
+
+func TestChain(t *testing.T) {
+	var times []time.Duration
+	record := EffectFunc(func(_ *image.RGBA, t time.Duration) {
+		times = append(times, t)
+	})
+	c := NewChain(FromFilter(mod.Fade(255)))
+	c.Add(record)
+	buf := solid(2, 2, white)
+	c.Filter()(buf)
+	if buf.RGBAAt(0, 0) != (color.RGBA{}) {
+		t.Fatalf("chain should apply filters, got %v", buf.RGBAAt(0, 0))
+	}
+	if len(times) != 1 || times[0] < 0 {
+		t.Fatalf("chain should pass time since it began")
+	}
+	c.SetEffects()
+	c.Apply(buf, 0)
+	if len(times) != 1 {
+		t.Fatalf("set effects should replace effects")
+	}
+}
+
+func TestBlur(t *testing.T) {
+	buf := solid(11, 11, black)
+	buf.SetRGBA(5, 5, white)
+	(&Blur{}).Apply(buf, 0)
+	if buf.RGBAAt(4, 5) != black {
+		t.Fatalf("a zero sigma should not blur")
+	}
+	(&Blur{Sigma: 2}).Apply(buf, 0)
+	center, near, far := buf.RGBAAt(5, 5), buf.RGBAAt(6, 5), buf.RGBAAt(0, 0)
+	if center.R == 255 || near.R == 0 || near.R >= center.R || far.R != 0 || near.A != 255 {
+		t.Fatalf("expected the point to spread, got %v %v %v", center, near, far)
+	}
+	flat := solid(20, 20, gray)
+	(&Blur{Sigma: 2.5}).Apply(flat, 0)
+	if flat.RGBAAt(0, 0) != gray || flat.RGBAAt(10, 10) != gray {
+		t.Fatalf("blurring a flat image should not change it")
+	}
+}
+
+func TestBloom(t *testing.T) {
+	buf := solid(16, 16, black)
+	for y := 6; y < 10; y++ {
+		for x := 6; x < 10; x++ {
+			buf.SetRGBA(x, y, white)
+		}
+	}
+	buf.SetRGBA(0, 15, gray)
+	(&Bloom{Threshold: 200, Sigma: 3, Downsample: 2}).Apply(buf, 0)
+	if buf.RGBAAt(4, 8).R == 0 {
+		t.Fatalf("bright pixels should glow onto their surroundings")
+	}
+	if buf.RGBAAt(15, 0).R != 0 || buf.RGBAAt(0, 15) != gray {
+		t.Fatalf("dim pixels should not glow")
+	}
+}
+
+func TestVignette(t *testing.T) {
+	buf := solid(20, 20, gray)
+	v := &Vignette{Strength: 1, Radius: .5}
+	v.Apply(buf, 0)
+	if buf.RGBAAt(10, 10) != gray || buf.RGBAAt(0, 0).R >= 50 || buf.RGBAAt(0, 10).R >= 100 {
+		t.Fatalf("expected darker edges, got %v %v", buf.RGBAAt(0, 0), buf.RGBAAt(0, 10))
+	}
+	pulsing := &Vignette{Pulse: 1, Speed: 1}
+	buf = solid(20, 20, gray)
+	pulsing.Apply(buf, 0)
+	if buf.RGBAAt(0, 0) != gray {
+		t.Fatalf("a pulse at its midpoint should be unchanged")
+	}
+	pulsing.Apply(buf, 250*time.Millisecond)
+	if buf.RGBAAt(0, 0).R >= 100 {
+		t.Fatalf("a pulse at its peak should darken")
+	}
+}
+
+func TestChromaticAberration(t *testing.T) {
+	buf := solid(20, 20, black)
+	for y := 0; y < 20; y++ {
+		buf.SetRGBA(16, y, white)
+	}
+	(&ChromaticAberration{Offset: 2}).Apply(buf, 0)
+	if c := buf.RGBAAt(17, 10); c.R == 0 || c.B != 0 {
+		t.Fatalf("red should be pushed out, got %v", c)
+	}
+	if c := buf.RGBAAt(15, 10); c.B == 0 || c.R != 0 {
+		t.Fatalf("blue should be pulled in, got %v", c)
+	}
+}
+
+func TestPixelate(t *testing.T) {
+	buf := solid(5, 5, black)
+	buf.SetRGBA(0, 0, white)
+	buf.SetRGBA(4, 4, white)
+	(&Pixelate{Size: 2}).Apply(buf, 0)
+	if buf.RGBAAt(1, 1) != (color.RGBA{63, 63, 63, 255}) || buf.RGBAAt(4, 4) != white || buf.RGBAAt(2, 2) != black {
+		t.Fatalf("expected block averages, got %v %v", buf.RGBAAt(1, 1), buf.RGBAAt(4, 4))
+	}
+}
+
+func TestCRT(t *testing.T) {
+	buf := solid(10, 10, gray)
+	crt := &CRT{Scanlines: .5}
+	crt.Apply(buf, 0)
+	if buf.RGBAAt(0, 0) != gray || buf.RGBAAt(0, 1).R != 50 || buf.RGBAAt(0, 3).R != 50 {
+		t.Fatalf("expected every other row darkened")
+	}
+	buf = solid(10, 10, gray)
+	crt.Roll = 1
+	crt.Apply(buf, time.Second)
+	if buf.RGBAAt(0, 0).R != 50 || buf.RGBAAt(0, 1) != gray {
+		t.Fatalf("expected scanlines to roll")
+	}
+
+	buf = solid(20, 20, gray)
+	buf.SetRGBA(10, 10, white)
+	border := color.RGBA{255, 0, 0, 255}
+	(&CRT{Curvature: .3, Border: border}).Apply(buf, 0)
+	if buf.RGBAAt(0, 0) != border || buf.RGBAAt(10, 10) != white || buf.RGBAAt(10, 5) != gray {
+		t.Fatalf("expected a curved screen with a border")
+	}
+}
+
+func TestLUT(t *testing.T) {
+	invert := NewLUTFunc(4, func(c color.RGBA) color.RGBA {
+		return color.RGBA{255 - c.R, 255 - c.G, 255 - c.B, 255}
+	})
+	if r, g, b := invert.Lookup(0, 100, 255); r != 255 || g != 155 || b != 0 {
+		t.Fatalf("expected inverted lookup, got %v %v %v", r, g, b)
+	}
+
+	strip := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for b := 0; b < 2; b++ {
+		for g := 0; g < 2; g++ {
+			for r := 0; r < 2; r++ {
+				// Swap red and blue.
+				strip.SetRGBA(b*2+r, g, color.RGBA{uint8(b * 255), uint8(g * 255), uint8(r * 255), 255})
+			}
+		}
+	}
+	swap, err := NewLUT(strip)
+	if err != nil {
+		t.Fatalf("failed to read lut: %v", err)
+	}
+	if swap.Size() != 2 {
+		t.Fatalf("expected a size 2 lut")
+	}
+	buf := solid(2, 2, color.RGBA{200, 50, 10, 255})
+	(&Grade{LUT: swap}).Apply(buf, 0)
+	if got := buf.RGBAAt(1, 1); got != (color.RGBA{10, 50, 200, 255}) {
+		t.Fatalf("expected swapped colors, got %v", got)
+	}
+	(&Grade{LUT: swap, Strength: .5}).Apply(buf, 0)
+	if got := buf.RGBAAt(1, 1); got.R != 105 || got.B != 105 {
+		t.Fatalf("expected half graded colors, got %v", got)
+	}
+
+	if _, err := NewLUT(image.NewRGBA(image.Rect(0, 0, 5, 2))); err == nil {
+		t.Fatalf("expected badly sized luts to fail")
+	}
+}
+
+func TestFlash(t *testing.T) {
+	f := &Flash{Color: white, Duration: time.Second}
+	buf := solid(2, 2, black)
+	f.Apply(buf, 0)
+	if buf.RGBAAt(0, 0) != black || f.Active() {
+		t.Fatalf("flash should not show before being triggered")
+	}
+	f.Trigger()
+	f.Apply(buf, 5*time.Second)
+	if buf.RGBAAt(0, 0) != white {
+		t.Fatalf("flash should start at full strength, got %v", buf.RGBAAt(0, 0))
+	}
+	buf = solid(2, 2, black)
+	f.Apply(buf, 5500*time.Millisecond)
+	if c := buf.RGBAAt(0, 0); c.R < 120 || c.R > 135 {
+		t.Fatalf("flash should fade, got %v", c)
+	}
+	buf = solid(2, 2, black)
+	f.Apply(buf, 6*time.Second)
+	if buf.RGBAAt(0, 0) != black || f.Active() {
+		t.Fatalf("flash should end")
+	}
+}
+
+func BenchmarkChain640x480(b *testing.B) {
+	buf := solid(640, 480, gray)
+	for i := 0; i < len(buf.Pix); i += 97 * 4 {
+		copy(buf.Pix[i:], []uint8{250, 240, 230})
+	}
+	flash := &Flash{Color: white, Strength: .5, Duration: time.Hour}
+	flash.Trigger()
+	c := NewChain(
+		&Bloom{Threshold: 200, Sigma: 6},
+		&ChromaticAberration{Offset: 2, Pulse: 1, Speed: 1},
+		&Grade{LUT: NewLUTFunc(16, func(c color.RGBA) color.RGBA { return color.RGBA{c.G, c.B, c.R, 255} })},
+		&Vignette{Strength: .6, Radius: .4},
+		&CRT{Scanlines: .3, Curvature: .1, Roll: 30},
+		flash,
+	)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Apply(buf, time.Duration(i)*time.Millisecond*16)
+	}
+}
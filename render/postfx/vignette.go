@@ -0,0 +1,88 @@
+package postfx
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"time"
+)
+
+// Vignette darkens the edges of the screen.
+type Vignette struct {
+	// Strength is how far the corners fade to Color, from 0 to 1.
+	Strength float64
+	// Radius is how far from the center the fade starts, as a fraction of
+	// the distance to the corners.
+	Radius float64
+	// Color is what the edges fade to. Its alpha is ignored.
+	Color color.RGBA
+	// Pulse is how much Strength rises and falls over time.
+	Pulse float64
+	// Speed is how many times a second the vignette pulses.
+	Speed float64
+
+	shape       []uint8
+	shapeW      int
+	shapeH      int
+	shapeRadius float64
+}
+
+// Apply darkens the edges of buf.
+func (v *Vignette) Apply(buf *image.RGBA, t time.Duration) {
+	w, h := buf.Rect.Dx(), buf.Rect.Dy()
+	strength := v.Strength
+	if v.Pulse != 0 {
+		strength += v.Pulse * math.Sin(2*math.Pi*v.Speed*t.Seconds())
+	}
+	s := int32(math.Max(0, math.Min(1, strength)) * 256)
+	if s == 0 || w == 0 || h == 0 {
+		return
+	}
+	shape := v.shapeFor(w, h)
+	fill := [3]int32{int32(v.Color.R), int32(v.Color.G), int32(v.Color.B)}
+	parallelRows(h, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			row := buf.Pix[y*buf.Stride:]
+			sh := shape[y*w : y*w+w]
+			for x, f := range sh {
+				if f == 0 {
+					continue
+				}
+				a := int32(f) * s >> 8
+				for c := 0; c < 3; c++ {
+					p := int32(row[x*4+c])
+					row[x*4+c] = uint8((p*(255-a) + fill[c]*a + 127) / 255)
+				}
+			}
+		}
+	})
+}
+
+// shapeFor returns how far each pixel fades, from 0 to 255, at full
+// strength, recalculating only when the size or radius change.
+func (v *Vignette) shapeFor(w, h int) []uint8 {
+	if v.shape != nil && v.shapeW == w && v.shapeH == h && v.shapeRadius == v.Radius {
+		return v.shape
+	}
+	v.shape = grow(v.shape, w*h)
+	v.shapeW, v.shapeH, v.shapeRadius = w, h, v.Radius
+	cx, cy := float64(w)/2, float64(h)/2
+	corner := math.Hypot(cx, cy)
+	span := math.Max(1-v.Radius, 1e-6)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			d := math.Hypot(float64(x)+.5-cx, float64(y)+.5-cy) / corner
+			f := (d - v.Radius) / span
+			if f <= 0 {
+				v.shape[y*w+x] = 0
+				continue
+			}
+			if f > 1 {
+				f = 1
+			}
+			// Smoothstep, so the fade starts gently.
+			v.shape[y*w+x] = uint8(f * f * (3 - 2*f) * 255)
+		}
+	}
+	return v.shape
+}
@@ -1,6 +1,8 @@
 package render
 
 import (
+	"image/draw"
+
 	"github.com/oakmound/oak/v4/event"
 	"github.com/oakmound/oak/v4/render/mod"
 )
@@ -108,6 +110,10 @@ func (rv *Reverting) Copy() Modifiable {
 	return newRv
 }
 
+func (rv *Reverting) drawBlend(buff draw.Image, xOff, yOff float64, mode BlendMode) {
+	drawBlended(rv.Modifiable, buff, xOff, yOff, mode)
+}
+
 // This might not ever be called?
 func (rv *Reverting) update() {
 	if u, ok := rv.Modifiable.(updates); ok {
@@ -128,6 +134,17 @@ func (rv *Reverting) SetTriggerID(cid event.CallerID) {
 	}
 }
 
+// SetTriggerBus sets the handler AnimationEnd will trigger on for animating
+// subtypes.
+func (rv *Reverting) SetTriggerBus(bus event.Handler) {
+	if t, ok := rv.Modifiable.(BusTriggerable); ok {
+		t.SetTriggerBus(bus)
+	}
+	if t, ok := rv.rs[0].(BusTriggerable); ok {
+		t.SetTriggerBus(bus)
+	}
+}
+
 // Pause ceases animating any renderable types that animate underneath this
 func (rv *Reverting) Pause() {
 	if cp, ok := rv.Modifiable.(CanPause); ok {
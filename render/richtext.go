@@ -0,0 +1,594 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/image/math/fixed"
+
+	"github.com/oakmound/oak/v4/oakerr"
+)
+
+// An Alignment is how lines of RichText are placed within its width.
+type Alignment uint8
+
+// Alignments.
+const (
+	AlignLeft Alignment = iota
+	AlignCenter
+	AlignRight
+	// AlignJustify stretches the spaces between words so lines fill the
+	// width of the text, except the last line of each paragraph.
+	AlignJustify
+)
+
+// A RichText is a renderable of text in mixed styles, word wrapped to a
+// width in pixels. Its style is set by markup in square brackets:
+//
+//	[color=red]...[/color]   colors by SVG 1.1 name, or #rrggbb or #rrggbbaa
+//	[font=name]...[/font]    a font given by RichTextFont
+//	[size=16]...[/size]      the current font regenerated at a new size
+//	[b]...[/b]               bold, by drawing each glyph twice
+//	[shadow]...[/shadow]     a drop shadow, black unless given as [shadow=color]
+//	[outline]...[/outline]   an outline, black unless given as [outline=color]
+//	[icon=name]              an image given by RichTextIcon, on the baseline
+//
+// Closing a tag closes any tags opened inside it. [[ writes a literal [.
+type RichText struct {
+	LayeredPoint
+	base        *Font
+	fonts       map[string]*Font
+	icons       map[string]*image.RGBA
+	sized       map[richFontKey]*Font
+	width       float64
+	align       Alignment
+	lineSpacing float64
+	visible     int
+
+	glyphs []richGlyph
+	lines  []richLine
+	w, h   float64
+}
+
+// A RichGlyph is where a glyph of a RichText is drawn, relative to the text.
+type RichGlyph struct {
+	// Rune is the glyph's character, or 0 for icons.
+	Rune rune
+	// Icon is the name of the glyph's icon, if it is one.
+	Icon string
+	// X is where the glyph starts and Y is the top of its line.
+	X, Y float64
+	// W is how far the glyph advances and H is the height of its line.
+	W, H float64
+	Line int
+}
+
+type richGlyph struct {
+	RichGlyph
+	s     string
+	icon  *image.RGBA
+	style *richStyle
+	// word counts the words before this glyph on its line, for justifying.
+	word     int
+	baseline float64
+}
+
+type richLine struct {
+	start, end int
+	width      float64
+	words      int
+	// last is whether the line ends its paragraph.
+	last         bool
+	y, h, ascent float64
+}
+
+type richStyle struct {
+	tag             string
+	font            *Font
+	baseFont        *Font
+	size            float64
+	color           image.Image
+	shadow, outline image.Image
+	bold            bool
+}
+
+type richFontKey struct {
+	font *Font
+	size float64
+}
+
+// A RichTextOption sets an optional property of a RichText.
+type RichTextOption func(*RichText)
+
+// RichTextWidth sets the width in pixels text is wrapped to. Text is only
+// broken at newlines by default.
+func RichTextWidth(w float64) RichTextOption {
+	return func(rt *RichText) {
+		rt.width = w
+	}
+}
+
+// RichTextAlignment sets how lines are aligned. Lines are aligned left by
+// default.
+func RichTextAlignment(a Alignment) RichTextOption {
+	return func(rt *RichText) {
+		rt.align = a
+	}
+}
+
+// RichTextLineSpacing sets the pixels added between lines.
+func RichTextLineSpacing(px float64) RichTextOption {
+	return func(rt *RichText) {
+		rt.lineSpacing = px
+	}
+}
+
+// RichTextFont names a font for [font=name] tags.
+func RichTextFont(name string, f *Font) RichTextOption {
+	return func(rt *RichText) {
+		rt.fonts[name] = f.Copy()
+	}
+}
+
+// RichTextIcon names an image for [icon=name] tags.
+func RichTextIcon(name string, img *image.RGBA) RichTextOption {
+	return func(rt *RichText) {
+		rt.icons[name] = img
+	}
+}
+
+// NewRichText creates a RichText drawn from the given markup in this font, or
+// an error if the markup is malformed or names unknown fonts or icons.
+func (f *Font) NewRichText(markup string, x, y float64, opts ...RichTextOption) (*RichText, error) {
+	rt := &RichText{
+		LayeredPoint: NewLayeredPoint(x, y, 0),
+		base:         f.Copy(),
+		fonts:        make(map[string]*Font),
+		icons:        make(map[string]*image.RGBA),
+		sized:        make(map[richFontKey]*Font),
+		visible:      -1,
+	}
+	for _, opt := range opts {
+		opt(rt)
+	}
+	if err := rt.SetMarkup(markup); err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// SetMarkup replaces the text with new markup, or returns an error and leaves
+// the text unchanged if the markup is malformed or names unknown fonts or icons.
+func (rt *RichText) SetMarkup(markup string) error {
+	glyphs, err := rt.parse(markup)
+	if err != nil {
+		return err
+	}
+	rt.glyphs = glyphs
+	rt.layout()
+	return nil
+}
+
+// SetWidth sets the width in pixels text is wrapped to, and wraps it again.
+// A width of 0 only breaks text at newlines.
+func (rt *RichText) SetWidth(w float64) {
+	rt.width = w
+	rt.layout()
+}
+
+// SetAlignment sets how lines are aligned.
+func (rt *RichText) SetAlignment(a Alignment) {
+	rt.align = a
+	rt.layout()
+}
+
+// SetLineSpacing sets the pixels added between lines.
+func (rt *RichText) SetLineSpacing(px float64) {
+	rt.lineSpacing = px
+	rt.layout()
+}
+
+// SetVisible limits drawing to the first n glyphs, for typewriter effects. A
+// negative n draws every glyph, as do new RichTexts.
+func (rt *RichText) SetVisible(n int) {
+	rt.visible = n
+}
+
+// Visible returns how many glyphs are drawn.
+func (rt *RichText) Visible() int {
+	if rt.visible < 0 || rt.visible > len(rt.glyphs) {
+		return len(rt.glyphs)
+	}
+	return rt.visible
+}
+
+// Glyphs returns where each glyph is drawn, in the order they were written.
+// Tags are not glyphs, and newlines and spaces dropped when wrapping have no
+// width.
+func (rt *RichText) Glyphs() []RichGlyph {
+	out := make([]RichGlyph, len(rt.glyphs))
+	for i, g := range rt.glyphs {
+		out[i] = g.RichGlyph
+	}
+	return out
+}
+
+// Lines returns the number of lines the text is wrapped to.
+func (rt *RichText) Lines() int {
+	return len(rt.lines)
+}
+
+// GetDims returns the size of the text: its wrap width, or its widest line
+// if it is not wrapped, by the height of its lines.
+func (rt *RichText) GetDims() (int, int) {
+	w := rt.w
+	if rt.width > 0 {
+		w = rt.width
+	}
+	return int(w + .5), int(rt.h + .5)
+}
+
+// Draw draws the visible glyphs of the text at +xOff, +yOff.
+func (rt *RichText) Draw(buff draw.Image, xOff, yOff float64) {
+	x, y := rt.X()+xOff, rt.Y()+yOff
+	for _, g := range rt.glyphs[:rt.Visible()] {
+		gx, by := int(x+g.X), int(y+g.baseline)
+		if g.icon != nil {
+			DrawImage(buff, g.icon, gx, by-g.icon.Bounds().Dy())
+			continue
+		}
+		if g.Rune == ' ' || g.Rune == '\n' {
+			continue
+		}
+		st := g.style
+		fnt := st.font
+		fnt.Drawer.Dst = buff
+		if st.outline != nil {
+			for _, off := range richOutline {
+				g.drawAt(fnt, st.outline, gx+off.X, by+off.Y, st.bold)
+			}
+		}
+		if st.shadow != nil {
+			g.drawAt(fnt, st.shadow, gx+1, by+1, st.bold)
+		}
+		g.drawAt(fnt, st.color, gx, by, st.bold)
+	}
+}
+
+var richOutline = []image.Point{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}}
+
+func (g *richGlyph) drawAt(fnt *Font, src image.Image, x, y int, bold bool) {
+	fnt.Drawer.Src = src
+	fnt.Drawer.Dot = fixed.P(x, y)
+	fnt.drawString(g.s)
+	if bold {
+		fnt.Drawer.Dot = fixed.P(x+1, y)
+		fnt.drawString(g.s)
+	}
+}
+
+// parse reads markup into unplaced glyphs.
+func (rt *RichText) parse(markup string) ([]richGlyph, error) {
+	stack := []*richStyle{{
+		font:     rt.base,
+		baseFont: rt.base,
+		color:    rt.base.gen.Color,
+	}}
+	glyphs := []richGlyph{}
+	for i := 0; i < len(markup); {
+		if strings.HasPrefix(markup[i:], "[[") {
+			glyphs = append(glyphs, richGlyph{RichGlyph: RichGlyph{Rune: '['}, s: "[", style: stack[len(stack)-1]})
+			i += 2
+			continue
+		}
+		if markup[i] != '[' {
+			end := strings.IndexByte(markup[i:], '[')
+			if end == -1 {
+				end = len(markup) - i
+			}
+			for _, r := range markup[i : i+end] {
+				glyphs = append(glyphs, richGlyph{RichGlyph: RichGlyph{Rune: r}, s: string(r), style: stack[len(stack)-1]})
+			}
+			i += end
+			continue
+		}
+		end := strings.IndexByte(markup[i:], ']')
+		if end == -1 {
+			return nil, oakerr.InvalidInput{InputName: "markup"}
+		}
+		tag := markup[i+1 : i+end]
+		i += end + 1
+		if strings.HasPrefix(tag, "/") {
+			name := tag[1:]
+			closed := false
+			for j := len(stack) - 1; j > 0; j-- {
+				if stack[j].tag == name {
+					stack = stack[:j]
+					closed = true
+					break
+				}
+			}
+			if !closed {
+				return nil, oakerr.InvalidInput{InputName: "markup [" + tag + "]"}
+			}
+			continue
+		}
+		name, value := tag, ""
+		if eq := strings.IndexByte(tag, '='); eq != -1 {
+			name, value = tag[:eq], tag[eq+1:]
+		}
+		if name == "icon" {
+			icon, ok := rt.icons[value]
+			if !ok {
+				return nil, oakerr.NotFound{InputName: "icon " + value}
+			}
+			glyphs = append(glyphs, richGlyph{RichGlyph: RichGlyph{Icon: value}, icon: icon, style: stack[len(stack)-1]})
+			continue
+		}
+		st, err := rt.push(stack[len(stack)-1], name, value)
+		if err != nil {
+			return nil, err
+		}
+		stack = append(stack, st)
+	}
+	return glyphs, nil
+}
+
+// push returns the style of text inside a tag opened within prev.
+func (rt *RichText) push(prev *richStyle, name, value string) (*richStyle, error) {
+	st := new(richStyle)
+	*st = *prev
+	st.tag = name
+	var err error
+	switch name {
+	case "color":
+		st.color, err = richColor(value)
+	case "font":
+		fnt, ok := rt.fonts[value]
+		if !ok {
+			return nil, oakerr.NotFound{InputName: "font " + value}
+		}
+		// Text not colored by a tag takes the new font's color.
+		if prev.color == prev.baseFont.gen.Color {
+			st.color = fnt.gen.Color
+		}
+		st.baseFont = fnt
+		st.font, err = rt.sizedFont(fnt, st.size)
+	case "size":
+		st.size, err = strconv.ParseFloat(value, 64)
+		if err != nil || st.size <= 0 {
+			return nil, oakerr.InvalidInput{InputName: "markup [size=" + value + "]"}
+		}
+		st.font, err = rt.sizedFont(st.baseFont, st.size)
+	case "b":
+		st.bold = true
+	case "shadow":
+		st.shadow = image.Black
+		if value != "" {
+			st.shadow, err = richColor(value)
+		}
+	case "outline":
+		st.outline = image.Black
+		if value != "" {
+			st.outline, err = richColor(value)
+		}
+	default:
+		return nil, oakerr.InvalidInput{InputName: "markup [" + name + "]"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// sizedFont returns a copy of fnt regenerated at size, reusing copies this
+// text has already made. A size of 0 keeps the font's own size.
+func (rt *RichText) sizedFont(fnt *Font, size float64) (*Font, error) {
+	if size == 0 || size == fnt.Height() {
+		return fnt, nil
+	}
+	key := richFontKey{fnt, size}
+	if sized, ok := rt.sized[key]; ok {
+		return sized, nil
+	}
+	sized, err := fnt.RegenerateWith(func(fg FontGenerator) FontGenerator {
+		fg.Size = size
+		return fg
+	})
+	if err != nil {
+		return nil, err
+	}
+	sized.Fallbacks = fnt.Fallbacks
+	rt.sized[key] = sized
+	return sized, nil
+}
+
+// richColor parses an SVG 1.1 color name, or a color as #rrggbb or #rrggbbaa.
+func richColor(s string) (image.Image, error) {
+	if !strings.HasPrefix(s, "#") {
+		return FontColor(s)
+	}
+	hex := s[1:]
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 8 {
+		return nil, oakerr.InvalidInput{InputName: "color " + s}
+	}
+	// Colors are given unpremultiplied.
+	return image.NewUniform(color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}), nil
+}
+
+// layout wraps glyphs into lines and places them.
+func (rt *RichText) layout() {
+	rt.lines = rt.lines[:0]
+	line := richLine{}
+	x := 0.0
+	// Spaces since the last word are pending; they are only part of the
+	// line's width if a word follows them on it.
+	pending, pendingFrom := 0.0, 0
+	wrapped := false
+	closeLine := func(end int, last bool) {
+		line.end = end
+		line.last = last
+		rt.lines = append(rt.lines, line)
+		line = richLine{start: end}
+		x, pending, pendingFrom = 0, 0, end
+		wrapped = !last
+	}
+	for i := 0; i < len(rt.glyphs); {
+		g := &rt.glyphs[i]
+		switch g.Rune {
+		case '\n':
+			g.X, g.W = x, 0
+			g.word = line.words
+			h, ascent := g.metrics()
+			line.h = math.Max(line.h, h)
+			line.ascent = math.Max(line.ascent, ascent)
+			closeLine(i+1, true)
+			// The line after a newline is at least as tall as the newline's
+			// font, even if it is empty.
+			line.h, line.ascent = h, ascent
+			i++
+			continue
+		case ' ':
+			g.W = measureRich(g.style, g.s)
+			if wrapped && line.words == 0 {
+				// Spaces starting a wrapped line are dropped.
+				g.W = 0
+			}
+			g.X = x + pending
+			g.word = line.words
+			pending += g.W
+			i++
+			continue
+		}
+		end := i
+		for end < len(rt.glyphs) && rt.glyphs[end].Rune != ' ' && rt.glyphs[end].Rune != '\n' {
+			end++
+		}
+		w := rt.placeWord(rt.glyphs[i:end])
+		if rt.width > 0 && line.words > 0 && x+pending+w > rt.width {
+			// Spaces before a wrapped word stay on the line before it, without
+			// width.
+			for j := pendingFrom; j < i; j++ {
+				rt.glyphs[j].X, rt.glyphs[j].W = x, 0
+			}
+			closeLine(i, false)
+		}
+		x += pending
+		for j := i; j < end; j++ {
+			g := &rt.glyphs[j]
+			g.X += x
+			g.word = line.words
+			h, ascent := g.metrics()
+			line.h = math.Max(line.h, h)
+			line.ascent = math.Max(line.ascent, ascent)
+		}
+		x += w
+		pending, pendingFrom = 0, end
+		line.width = x
+		line.words++
+		i = end
+	}
+	closeLine(len(rt.glyphs), true)
+
+	rt.w, rt.h = 0, 0
+	for _, l := range rt.lines {
+		rt.w = math.Max(rt.w, l.width)
+	}
+	boxW := rt.w
+	if rt.width > 0 {
+		boxW = rt.width
+	}
+	y := 0.0
+	for n := range rt.lines {
+		l := &rt.lines[n]
+		l.h = math.Max(l.h, l.ascent)
+		l.y = y
+		shift, gap := 0.0, 0.0
+		switch rt.align {
+		case AlignCenter:
+			shift = (boxW - l.width) / 2
+		case AlignRight:
+			shift = boxW - l.width
+		case AlignJustify:
+			if !l.last && l.words > 1 {
+				gap = (boxW - l.width) / float64(l.words-1)
+			}
+		}
+		for j := l.start; j < l.end; j++ {
+			g := &rt.glyphs[j]
+			g.X += shift + gap*float64(g.word)
+			g.Y, g.H = l.y, l.h
+			g.Line = n
+			g.baseline = l.y + l.ascent
+		}
+		y += l.h
+		if n != len(rt.lines)-1 {
+			y += rt.lineSpacing
+		}
+	}
+	rt.h = y
+}
+
+// placeWord sets the X and W of a word's glyphs relative to the word's start,
+// and returns the word's width. Glyphs in the same style are measured
+// together so kerning between them is kept.
+func (rt *RichText) placeWord(word []richGlyph) float64 {
+	x := 0.0
+	for i := 0; i < len(word); {
+		if word[i].icon != nil {
+			word[i].X = x
+			word[i].W = float64(word[i].icon.Bounds().Dx())
+			x += word[i].W
+			i++
+			continue
+		}
+		st := word[i].style
+		end := i
+		var run strings.Builder
+		for end < len(word) && word[end].icon == nil && word[end].style == st {
+			run.WriteString(word[end].s)
+			end++
+		}
+		text := run.String()
+		// Each glyph ends where the run up to it does, so kerning before a
+		// glyph moves where it starts.
+		off := 0
+		for j := i; j < end; j++ {
+			off += len(word[j].s)
+			word[j].W = measureRich(st, word[j].s)
+			word[j].X = x + measureRich(st, text[:off]) - word[j].W
+		}
+		x += measureRich(st, text)
+		i = end
+	}
+	return x
+}
+
+// measureRich measures s in a style, counting the extra pixel bold glyphs
+// take.
+func measureRich(st *richStyle, s string) float64 {
+	w := float64(st.font.MeasureString(s)) / 64
+	if st.bold {
+		w += float64(utf8.RuneCountInString(s))
+	}
+	return w
+}
+
+// metrics returns the height of the line a glyph needs and the distance
+// from the top of that line to its baseline.
+func (g *richGlyph) metrics() (h, ascent float64) {
+	if g.icon != nil {
+		h := float64(g.icon.Bounds().Dy())
+		return h, h
+	}
+	return g.style.font.Height(), g.style.font.ascent()
+}
@@ -0,0 +1,156 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// pixelFont is an uncolored font from the test BMFont: A and B are 4x6 glyphs
+// advancing 5 pixels, spaces advance 3, and B is kerned 1 pixel closer to A.
+func pixelFont(t *testing.T) *Font {
+	t.Helper()
+	fg := FontGenerator{
+		Cache: NewCache(),
+		File:  "testdata/assets/fonts/bmfont/pixel.fnt",
+	}
+	fnt, err := fg.Generate()
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	return fnt
+}
+
+func TestRichTextMarkupErrors(t *testing.T) {
+	fnt := pixelFont(t)
+	for _, markup := range []string{
+		"[b",
+		"[bogus]A",
+		"A[/b]",
+		"[color=notacolor]A",
+		"[color=#12345]A",
+		"[size=-1]A",
+		"[font=missing]A",
+		"[icon=missing]",
+	} {
+		if _, err := fnt.NewRichText(markup, 0, 0); err == nil {
+			t.Fatalf("expected %q to fail", markup)
+		}
+	}
+	rt, err := fnt.NewRichText("[[b]", 0, 0)
+	if err != nil {
+		t.Fatalf("escaped bracket failed: %v", err)
+	}
+	if gs := rt.Glyphs(); len(gs) != 3 || gs[0].Rune != '[' || gs[1].Rune != 'b' {
+		t.Fatalf("escaped bracket should be written literally, got %v", gs)
+	}
+	if err := rt.SetMarkup("[b"); err == nil || len(rt.Glyphs()) != 3 {
+		t.Fatalf("bad markup should leave the text unchanged")
+	}
+}
+
+func TestRichTextLayout(t *testing.T) {
+	fnt := pixelFont(t)
+	rt, err := fnt.NewRichText("AB AB AB", 0, 0, RichTextWidth(21), RichTextLineSpacing(2))
+	if err != nil {
+		t.Fatalf("new rich text failed: %v", err)
+	}
+	gs := rt.Glyphs()
+	if gs[1].X != 4 || gs[1].W != 5 {
+		t.Fatalf("kerned glyph placed at %v,%v", gs[1].X, gs[1].W)
+	}
+	if rt.Lines() != 2 || gs[6].Line != 1 || gs[6].X != 0 || gs[6].Y != 10 || gs[5].W != 0 {
+		t.Fatalf("text wrapped incorrectly: %+v", gs)
+	}
+	if w, h := rt.GetDims(); w != 21 || h != 18 {
+		t.Fatalf("expected 21x18, got %vx%v", w, h)
+	}
+	rt.SetWidth(0)
+	if rt.Lines() != 1 {
+		t.Fatalf("unwrapped text should be one line")
+	}
+	if w, _ := rt.GetDims(); w != 33 {
+		t.Fatalf("expected unwrapped width 33, got %v", w)
+	}
+
+	rt.SetWidth(30)
+	rt.SetAlignment(AlignRight)
+	if gs := rt.Glyphs(); gs[6].X != 21 {
+		t.Fatalf("right aligned line should end at the width, got %v", gs[6].X)
+	}
+	rt.SetAlignment(AlignCenter)
+	if gs := rt.Glyphs(); gs[6].X != 10.5 {
+		t.Fatalf("centered line should be centered, got %v", gs[6].X)
+	}
+
+	if err := rt.SetMarkup("A A A\nA A"); err != nil {
+		t.Fatalf("set markup failed: %v", err)
+	}
+	rt.SetWidth(20)
+	rt.SetAlignment(AlignJustify)
+	gs = rt.Glyphs()
+	if rt.Lines() != 3 || gs[2].X != 15 {
+		t.Fatalf("justified line should fill the width, got %v lines, %v", rt.Lines(), gs[2].X)
+	}
+	if gs[8].X != 8 {
+		t.Fatalf("last line of a paragraph should not be justified, got %v", gs[8].X)
+	}
+
+	if err := rt.SetMarkup("[b]A[/b]A"); err != nil {
+		t.Fatalf("set markup failed: %v", err)
+	}
+	if gs := rt.Glyphs(); gs[0].W != 6 || gs[1].X != 6 {
+		t.Fatalf("bold glyphs should be a pixel wider")
+	}
+
+	big, err := DefaultFont().NewRichText("a [size=24]b[/size] c", 0, 0)
+	if err != nil {
+		t.Fatalf("sized rich text failed: %v", err)
+	}
+	if _, h := big.GetDims(); h != 24 {
+		t.Fatalf("sized text should raise its line height, got %v", h)
+	}
+}
+
+func TestRichTextDraw(t *testing.T) {
+	var (
+		red   = color.RGBA{255, 0, 0, 255}
+		green = color.RGBA{0, 255, 0, 255}
+		blue  = color.RGBA{0, 0, 255, 255}
+		white = color.RGBA{255, 255, 255, 255}
+	)
+	gem := image.NewRGBA(image.Rect(0, 0, 2, 3))
+	for i := 0; i < len(gem.Pix); i += 4 {
+		copy(gem.Pix[i:], []uint8{0, 255, 0, 255})
+	}
+	fnt := pixelFont(t)
+	rt, err := fnt.NewRichText("[color=#ff0000]A[/color]B[icon=gem]", 0, 0, RichTextIcon("gem", gem))
+	if err != nil {
+		t.Fatalf("new rich text failed: %v", err)
+	}
+	buff := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	rt.Draw(buff, 0, 0)
+	// Glyphs in different styles are not kerned.
+	if buff.RGBAAt(3, 1) != red || buff.RGBAAt(5, 1) != blue || buff.RGBAAt(10, 4) != green || buff.RGBAAt(10, 3) != (color.RGBA{}) {
+		t.Fatalf("rich text drawn incorrectly")
+	}
+	rt.SetVisible(1)
+	buff = image.NewRGBA(image.Rect(0, 0, 16, 16))
+	rt.Draw(buff, 0, 0)
+	if buff.RGBAAt(3, 1) != red || buff.RGBAAt(5, 1) != (color.RGBA{}) {
+		t.Fatalf("only visible glyphs should be drawn")
+	}
+
+	if err := rt.SetMarkup("[shadow=#00ff00]A[/shadow] [outline=red]A"); err != nil {
+		t.Fatalf("set markup failed: %v", err)
+	}
+	rt.SetVisible(-1)
+	buff = image.NewRGBA(image.Rect(0, 0, 16, 16))
+	rt.Draw(buff, 0, 0)
+	if buff.RGBAAt(0, 1) != white || buff.RGBAAt(4, 7) != green || buff.RGBAAt(0, 7) != (color.RGBA{}) {
+		t.Fatalf("shadow drawn incorrectly")
+	}
+	if buff.RGBAAt(8, 1) != white || buff.RGBAAt(7, 0) != red || buff.RGBAAt(12, 7) != red {
+		t.Fatalf("outline drawn incorrectly")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/oakerr"
 	"github.com/oakmound/oak/v4/render/mod"
 	"github.com/oakmound/oak/v4/timing"
 )
@@ -20,9 +21,33 @@ type Sequence struct {
 	lastChange time.Time
 	sheetPos   int
 	frameTime  int64
+	frameTimes []int64
+	direction  Direction
+	// step is 1 or -1, the way ping-pong sequences are currently playing.
+	step  int
+	t     *Transform
+	blend BlendMode
 	event.CallerID
+	// bus is the handler AnimationEnd is triggered on, or event.DefaultBus
+	// if nil.
+	bus event.Handler
 }
 
+// A Direction is the order a Sequence plays its frames in.
+type Direction uint8
+
+// Directions a Sequence can play in.
+const (
+	// Forward plays frames first to last, then loops.
+	Forward Direction = iota
+	// Reverse plays frames last to first, then loops.
+	Reverse
+	// PingPong plays frames first to last, then back to the first, then loops.
+	PingPong
+	// PingPongReverse plays frames last to first, then back to the last, then loops.
+	PingPongReverse
+)
+
 // NewSequence returns a new sequence from the input modifiables, playing at
 // the given fps rate.
 func NewSequence(fps float64, mods ...Modifiable) *Sequence {
@@ -38,6 +63,7 @@ func NewSequence(fps float64, mods ...Modifiable) *Sequence {
 		frameTime:  timing.FPSToNano(fps),
 		rs:         mods,
 		lastChange: time.Now(),
+		step:       1,
 	}
 }
 
@@ -47,6 +73,39 @@ func (sq *Sequence) SetFPS(fps float64) {
 	sq.frameTime = timing.FPSToNano(fps)
 }
 
+// SetFrameDurations sets how long each frame of this sequence shows for, in
+// place of its fps. There must be one duration for each frame.
+func (sq *Sequence) SetFrameDurations(durations ...time.Duration) error {
+	if len(durations) != len(sq.rs) {
+		return oakerr.InvalidInput{InputName: "durations"}
+	}
+	sq.frameTimes = make([]int64, len(durations))
+	for i, d := range durations {
+		sq.frameTimes[i] = d.Nanoseconds()
+	}
+	return nil
+}
+
+// FrameDurations returns how long each frame of this sequence shows for, or
+// nil if every frame shows for the same time, by its fps.
+func (sq *Sequence) FrameDurations() []time.Duration {
+	if sq.frameTimes == nil {
+		return nil
+	}
+	out := make([]time.Duration, len(sq.frameTimes))
+	for i, t := range sq.frameTimes {
+		out[i] = time.Duration(t)
+	}
+	return out
+}
+
+// SetDirection sets the order this sequence plays its frames in, and returns
+// it to the first frame it plays.
+func (sq *Sequence) SetDirection(d Direction) {
+	sq.direction = d
+	sq.Reset()
+}
+
 // GetDims of a Sequence returns the dims of the current Renderable for the sequence
 func (sq *Sequence) GetDims() (int, int) {
 	return sq.rs[sq.sheetPos].GetDims()
@@ -64,6 +123,11 @@ func (sq *Sequence) Copy() Modifiable {
 	}
 
 	newSq.rs = newRs
+	newSq.frameTimes = append([]int64(nil), sq.frameTimes...)
+	if sq.t != nil {
+		t := *sq.t
+		newSq.t = &t
+	}
 	newSq.LayeredPoint = sq.LayeredPoint.Copy()
 	return newSq
 }
@@ -71,22 +135,71 @@ func (sq *Sequence) Copy() Modifiable {
 var AnimationEnd = event.RegisterEvent[struct{}]()
 
 // SetTriggerID sets the ID that AnimationEnd will be triggered on when this
-// sequence loops over from its last frame to its first
+// sequence reaches the last frame it plays before looping
 func (sq *Sequence) SetTriggerID(id event.CallerID) {
 	sq.CallerID = id
 }
 
+// SetTriggerBus sets the handler AnimationEnd will be triggered on. A nil
+// handler, the default, is event.DefaultBus.
+func (sq *Sequence) SetTriggerBus(bus event.Handler) {
+	sq.bus = bus
+}
+
 func (sq *Sequence) update() {
-	if sq.playing && time.Since(sq.lastChange).Nanoseconds() > sq.frameTime {
+	frameTime := sq.frameTime
+	if sq.frameTimes != nil {
+		frameTime = sq.frameTimes[sq.sheetPos]
+	}
+	if sq.playing && time.Since(sq.lastChange).Nanoseconds() > frameTime {
 		sq.lastChange = time.Now()
-		sq.sheetPos = (sq.sheetPos + 1) % len(sq.rs)
-		if sq.sheetPos == (len(sq.rs)-1) && sq.CallerID != 0 {
-			// TODO: not default bus
-			event.TriggerForCallerOn(event.DefaultBus, sq.CallerID, AnimationEnd, struct{}{})
+		sq.sheetPos, sq.step = sq.next(sq.sheetPos, sq.step)
+		if sq.CallerID != 0 {
+			startPos, startStep := sq.start()
+			if pos, step := sq.next(sq.sheetPos, sq.step); pos == startPos && step == startStep {
+				bus := sq.bus
+				if bus == nil {
+					bus = event.DefaultBus
+				}
+				event.TriggerForCallerOn(bus, sq.CallerID, AnimationEnd, struct{}{})
+			}
 		}
 	}
 }
 
+// start returns the first frame this sequence plays and the way it steps from it.
+func (sq *Sequence) start() (pos, step int) {
+	switch sq.direction {
+	case Reverse, PingPongReverse:
+		return len(sq.rs) - 1, -1
+	}
+	return 0, 1
+}
+
+// next returns the frame played after pos, and the way it steps from it.
+func (sq *Sequence) next(pos, step int) (int, int) {
+	n := len(sq.rs)
+	switch sq.direction {
+	case Reverse:
+		return (pos - 1 + n) % n, -1
+	case PingPong, PingPongReverse:
+		if n == 1 {
+			return 0, step
+		}
+		if pos+step < 0 || pos+step >= n {
+			step = -step
+		}
+		return pos + step, step
+	}
+	return (pos + 1) % n, 1
+}
+
+// Reset returns this sequence to its first frame, restarting its frame timer.
+func (sq *Sequence) Reset() {
+	sq.sheetPos, sq.step = sq.start()
+	sq.lastChange = time.Now()
+}
+
 // Get returns the Modifiable stored at this sequence's ith index. If the sequence
 // does not have an ith index this returns nil
 func (sq *Sequence) Get(i int) Modifiable {
@@ -98,8 +211,43 @@ func (sq *Sequence) Get(i int) Modifiable {
 
 // Draw draws this sequence at +xOff, +yOff
 func (sq *Sequence) Draw(buff draw.Image, xOff, yOff float64) {
+	sq.drawBlend(buff, xOff, yOff, sq.blend)
+}
+
+func (sq *Sequence) drawBlend(buff draw.Image, xOff, yOff float64, mode BlendMode) {
 	sq.update()
-	sq.rs[sq.sheetPos].Draw(buff, sq.X()+xOff, sq.Y()+yOff)
+	r := sq.rs[sq.sheetPos]
+	switch {
+	case sq.t != nil:
+		DrawTransformedBlend(buff, r.GetRGBA(), r.X()+sq.X()+xOff, r.Y()+sq.Y()+yOff, sq.t, mode)
+	case mode != BlendOver:
+		DrawImageBlend(buff, r.GetRGBA(), int(r.X()+sq.X()+xOff), int(r.Y()+sq.Y()+yOff), mode)
+	default:
+		r.Draw(buff, sq.X()+xOff, sq.Y()+yOff)
+	}
+}
+
+// SetBlendMode sets how each frame of this sequence is blended with what is
+// drawn beneath it, in place of the blend modes of the frames themselves.
+func (sq *Sequence) SetBlendMode(mode BlendMode) {
+	sq.blend = mode
+}
+
+// GetBlendMode returns how this sequence is blended with what is drawn beneath it.
+func (sq *Sequence) GetBlendMode() BlendMode {
+	return sq.blend
+}
+
+// SetTransform sets a transform each frame of this sequence is drawn with,
+// in place of any transforms of the frames themselves. The transform is kept
+// rather than copied. A nil transform draws frames as they are.
+func (sq *Sequence) SetTransform(t *Transform) {
+	sq.t = t
+}
+
+// GetTransform returns the transform this sequence is drawn with, if any.
+func (sq *Sequence) GetTransform() *Transform {
+	return sq.t
 }
 
 // GetRGBA returns the RGBA of the currently showing frame of this sequence
@@ -37,6 +37,33 @@ func TestSequenceTrigger(t *testing.T) {
 	<-triggerCh
 }
 
+func TestSequenceTriggerBus(t *testing.T) {
+	cm := event.NewCallerMap()
+	bus := event.NewBus(cm)
+	sq := NewSequence(100,
+		NewColorBox(10, 10, color.RGBA{255, 0, 0, 255}),
+		NewColorBox(10, 10, color.RGBA{0, 255, 0, 255}))
+	d := Dummy{}
+	d.CallerID = cm.Register(d)
+	sq.SetTriggerID(d.CallerID)
+	sq.SetTriggerBus(bus)
+	triggerCh := make(chan struct{}, 1)
+	b := event.Bind(bus, AnimationEnd, d, func(_ Dummy, _ struct{}) event.Response {
+		triggerCh <- struct{}{}
+		return 0
+	})
+	<-b.Bound
+	for i := 0; i < 2; i++ {
+		time.Sleep(20 * time.Millisecond)
+		sq.update()
+	}
+	select {
+	case <-triggerCh:
+	case <-time.After(time.Second):
+		t.Fatalf("expected AnimationEnd on the sequence's bus")
+	}
+}
+
 func TestSequenceFunctions(t *testing.T) {
 	rgba1 := image.NewRGBA(image.Rect(0, 0, 10, 10))
 	rgba2 := image.NewRGBA(image.Rect(0, 0, 5, 5))
@@ -96,6 +123,11 @@ func TestSequenceFunctions(t *testing.T) {
 		t.Fatalf("get dims mismatch")
 	}
 
+	sq.Reset()
+	if sq.GetRGBA() != rgba1 {
+		t.Fatalf("reset did not return to first frame")
+	}
+
 	if sq.IsStatic() {
 		t.Fatalf("sequence should not have been static")
 	}
@@ -129,3 +161,69 @@ func TestTweenSequence(t *testing.T) {
 	TweenSequence(start.GetRGBA(), end.GetRGBA(), 2, 5)
 	// Tween behavior is tested elsewhere, this is just a "this doesn't crash" test
 }
+
+func TestSequenceDirections(t *testing.T) {
+	frames := func() []Modifiable {
+		ms := make([]Modifiable, 4)
+		for i := range ms {
+			ms[i] = NewEmptySprite(0, 0, i+1, 1)
+		}
+		return ms
+	}
+	advance := func(sq *Sequence) int {
+		sq.lastChange = time.Now().Add(-time.Hour)
+		sq.update()
+		w, _ := sq.GetDims()
+		return w - 1
+	}
+	tcs := []struct {
+		direction Direction
+		expected  []int
+	}{
+		{Forward, []int{0, 1, 2, 3, 0, 1}},
+		{Reverse, []int{3, 2, 1, 0, 3, 2}},
+		{PingPong, []int{0, 1, 2, 3, 2, 1, 0, 1}},
+		{PingPongReverse, []int{3, 2, 1, 0, 1, 2, 3, 2}},
+	}
+	for _, tc := range tcs {
+		sq := NewSequence(60, frames()...)
+		sq.SetDirection(tc.direction)
+		if w, _ := sq.GetDims(); w-1 != tc.expected[0] {
+			t.Fatalf("direction %v: expected to start on frame %v, got %v", tc.direction, tc.expected[0], w-1)
+		}
+		for i, exp := range tc.expected[1:] {
+			if got := advance(sq); got != exp {
+				t.Fatalf("direction %v: step %v expected frame %v, got %v", tc.direction, i, exp, got)
+			}
+		}
+	}
+}
+
+func TestSequenceFrameDurations(t *testing.T) {
+	sq := NewSequence(1000,
+		NewEmptySprite(0, 0, 1, 1),
+		NewEmptySprite(0, 0, 2, 2))
+	if err := sq.SetFrameDurations(time.Second); err == nil {
+		t.Fatalf("expected mismatched durations to fail")
+	}
+	if err := sq.SetFrameDurations(time.Millisecond, time.Hour); err != nil {
+		t.Fatalf("set frame durations failed: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	sq.update()
+	if sq.sheetPos != 1 {
+		t.Fatalf("expected short first frame to pass")
+	}
+	time.Sleep(5 * time.Millisecond)
+	sq.update()
+	if sq.sheetPos != 1 {
+		t.Fatalf("expected long second frame to hold")
+	}
+	sq2 := sq.Copy().(*Sequence)
+	if len(sq2.frameTimes) != 2 || sq2.frameTimes[1] != time.Hour.Nanoseconds() {
+		t.Fatalf("copy should keep frame durations")
+	}
+	if ds := sq2.FrameDurations(); len(ds) != 2 || ds[0] != time.Millisecond || ds[1] != time.Hour {
+		t.Fatalf("unexpected frame durations %v", ds)
+	}
+}
@@ -11,7 +11,9 @@ import (
 // A Sprite is a basic wrapper around image data and a point. The most basic Renderable.
 type Sprite struct {
 	LayeredPoint
-	r *image.RGBA
+	r     *image.RGBA
+	t     *Transform
+	blend BlendMode
 }
 
 // NewEmptySprite returns a sprite of the given dimensions with a blank RGBA
@@ -72,7 +74,37 @@ func (s *Sprite) Set(x, y int, c color.Color) {
 
 // Draw draws this sprite at +xOff, +yOff
 func (s *Sprite) Draw(buff draw.Image, xOff, yOff float64) {
-	DrawImage(buff, s.r, int(s.X()+xOff), int(s.Y()+yOff))
+	s.drawBlend(buff, xOff, yOff, s.blend)
+}
+
+func (s *Sprite) drawBlend(buff draw.Image, xOff, yOff float64, mode BlendMode) {
+	if s.t != nil {
+		DrawTransformedBlend(buff, s.r, s.X()+xOff, s.Y()+yOff, s.t, mode)
+		return
+	}
+	DrawImageBlend(buff, s.r, int(s.X()+xOff), int(s.Y()+yOff), mode)
+}
+
+// SetBlendMode sets how this sprite is blended with what is drawn beneath it.
+func (s *Sprite) SetBlendMode(mode BlendMode) {
+	s.blend = mode
+}
+
+// GetBlendMode returns how this sprite is blended with what is drawn beneath it.
+func (s *Sprite) GetBlendMode() BlendMode {
+	return s.blend
+}
+
+// SetTransform sets a transform this sprite is drawn with. The transform
+// is kept rather than copied, so changes to it show the next time the sprite
+// is drawn. A nil transform draws the sprite as it is.
+func (s *Sprite) SetTransform(t *Transform) {
+	s.t = t
+}
+
+// GetTransform returns the transform this sprite is drawn with, if any.
+func (s *Sprite) GetTransform() *Transform {
+	return s.t
 }
 
 // Copy returns a copy of this Sprite
@@ -81,16 +113,28 @@ func (s *Sprite) Copy() Modifiable {
 	if s.r != nil {
 		newS.r = rgbaCopy(s.r)
 	}
+	if s.t != nil {
+		t := *s.t
+		newS.t = &t
+	}
+	newS.blend = s.blend
 	newS.LayeredPoint = s.LayeredPoint.Copy()
 	return newS
 }
 
 func rgbaCopy(r *image.RGBA) *image.RGBA {
-	newRgba := new(image.RGBA)
-	newRgba.Rect = r.Rect
-	newRgba.Stride = r.Stride
-	newRgba.Pix = make([]uint8, len(r.Pix))
-	copy(newRgba.Pix, r.Pix)
+	b := r.Rect
+	rowLen := b.Dx() * 4
+	newRgba := &image.RGBA{
+		Rect:   b,
+		Stride: rowLen,
+		Pix:    make([]uint8, rowLen*b.Dy()),
+	}
+	// Copy row by row, as r may be a view into a larger image.
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		start := r.PixOffset(b.Min.X, y)
+		copy(newRgba.Pix[(y-b.Min.Y)*rowLen:], r.Pix[start:start+rowLen])
+	}
 	return newRgba
 }
 
@@ -132,6 +132,12 @@ func (c *Switch) Draw(buff draw.Image, xOff float64, yOff float64) {
 	c.lock.RUnlock()
 }
 
+func (c *Switch) drawBlend(buff draw.Image, xOff, yOff float64, mode BlendMode) {
+	c.lock.RLock()
+	drawBlended(c.subRenderables[c.curRenderable], buff, c.X()+xOff, c.Y()+yOff, mode)
+	c.lock.RUnlock()
+}
+
 // ShiftPos shifts the Switch's logical position
 func (c *Switch) ShiftPos(x, y float64) {
 	c.SetPos(c.X()+x, c.Y()+y)
@@ -197,6 +203,18 @@ func (c *Switch) SetTriggerID(cid event.CallerID) {
 	c.lock.RUnlock()
 }
 
+// SetTriggerBus sets the handler AnimationEnd will trigger on for animating
+// subtypes.
+func (c *Switch) SetTriggerBus(bus event.Handler) {
+	c.lock.RLock()
+	for _, r := range c.subRenderables {
+		if t, ok := r.(BusTriggerable); ok {
+			t.SetTriggerBus(bus)
+		}
+	}
+	c.lock.RUnlock()
+}
+
 // Revert will revert all parts of this Switch that can be reverted
 func (c *Switch) Revert(mod int) {
 	c.lock.RLock()
@@ -0,0 +1,148 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/oakmound/oak/v4/alg"
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+)
+
+// A Sampling is how a transformed image's pixels are read.
+type Sampling uint8
+
+// Samplings.
+const (
+	// Nearest reads the closest pixel, keeping pixel art sharp.
+	Nearest Sampling = iota
+	// Bilinear blends the four closest pixels, smoothing rotations and
+	// sub-pixel movement.
+	Bilinear
+)
+
+// A Transform is an affine transform applied to an image as it is drawn,
+// rather than to the image itself as a mod.Mod would, so changing it from
+// frame to frame does not allocate. The zero Transform draws images as they
+// are.
+type Transform struct {
+	// Rotation turns the image counter-clockwise, as mod.Rotate does.
+	Rotation alg.Degree
+	// ScaleX and ScaleY stretch the image. A scale of 0 is treated as 1.
+	ScaleX, ScaleY float64
+	FlipX, FlipY   bool
+	// Pivot is the point in the image it is scaled, flipped and rotated
+	// around. The pivot is drawn where it would be without the transform.
+	Pivot floatgeom.Point2
+	// Offset moves the image by fractions of a pixel as well as whole ones.
+	Offset   floatgeom.Point2
+	Sampling Sampling
+}
+
+// Transformable types can be drawn with a Transform. A nil Transform draws
+// them as they are.
+type Transformable interface {
+	SetTransform(*Transform)
+	GetTransform() *Transform
+}
+
+// DrawTransformed draws img to buff with its top left at x, y, changed by t.
+// Drawing to *image.RGBA buffers does not allocate.
+func DrawTransformed(buff draw.Image, img *image.RGBA, x, y float64, t *Transform) {
+	DrawTransformedBlend(buff, img, x, y, t, BlendOver)
+}
+
+// DrawTransformedBlend is DrawTransformed in the given blend mode.
+func DrawTransformedBlend(buff draw.Image, img *image.RGBA, x, y float64, t *Transform, mode BlendMode) {
+	if t == nil {
+		DrawImageBlend(buff, img, int(x), int(y), mode)
+		return
+	}
+	sx, sy := t.ScaleX, t.ScaleY
+	if sx == 0 {
+		sx = 1
+	}
+	if sy == 0 {
+		sy = 1
+	}
+	if t.FlipX {
+		sx = -sx
+	}
+	if t.FlipY {
+		sy = -sy
+	}
+	sin, cos := math.Sincos(float64(t.Rotation.Radians()))
+	// The image is scaled, then rotated counter-clockwise on screen, where y
+	// points down: forward takes a point relative to the pivot in the image
+	// to one relative to the pivot on screen.
+	fa, fb := cos*sx, sin*sy
+	fc, fd := -sin*sx, cos*sy
+	det := fa*fd - fb*fc
+	if det == 0 {
+		return
+	}
+	// inverse takes points on screen back to the image.
+	ia, ib := fd/det, -fb/det
+	ic, id := -fc/det, fa/det
+
+	src := img.Bounds()
+	px, py := t.Pivot.X(), t.Pivot.Y()
+	ox, oy := x+px+t.Offset.X(), y+py+t.Offset.Y()
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, c := range [4][2]float64{{0, 0}, {float64(src.Dx()), 0}, {0, float64(src.Dy())}, {float64(src.Dx()), float64(src.Dy())}} {
+		rx, ry := c[0]-px, c[1]-py
+		dx, dy := ox+fa*rx+fb*ry, oy+fc*rx+fd*ry
+		minX, maxX = math.Min(minX, dx), math.Max(maxX, dx)
+		minY, maxY = math.Min(minY, dy), math.Max(maxY, dy)
+	}
+	dst := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+	dst = dst.Intersect(buff.Bounds())
+	if dst.Empty() {
+		return
+	}
+	w, h := float64(src.Dx()), float64(src.Dy())
+	for j := dst.Min.Y; j < dst.Max.Y; j++ {
+		// Pixels are sampled at their centers.
+		ry := float64(j) + .5 - oy
+		rx := float64(dst.Min.X) + .5 - ox
+		u := px + ia*rx + ib*ry
+		v := py + ic*rx + id*ry
+		for i := dst.Min.X; i < dst.Max.X; i++ {
+			if u >= 0 && v >= 0 && u < w && v < h || t.Sampling == Bilinear && u > -.5 && v > -.5 && u < w+.5 && v < h+.5 {
+				var c color.RGBA
+				if t.Sampling == Bilinear {
+					c = sampleBilinear(img, u-.5, v-.5)
+				} else {
+					c = img.RGBAAt(src.Min.X+int(u), src.Min.Y+int(v))
+				}
+				setBlended(buff, i, j, c, mode)
+			}
+			u += ia
+			v += ic
+		}
+	}
+}
+
+// sampleBilinear blends the four pixels of img around x, y, in pixel
+// coordinates from its top left. Pixels outside img are transparent, so
+// edges fade out smoothly.
+func sampleBilinear(img *image.RGBA, x, y float64) color.RGBA {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+	b := img.Bounds()
+	ix, iy := b.Min.X+int(x0), b.Min.Y+int(y0)
+	var acc [4]float64
+	weights := [4]float64{(1 - fx) * (1 - fy), fx * (1 - fy), (1 - fx) * fy, fx * fy}
+	for n, p := range [4]image.Point{{ix, iy}, {ix + 1, iy}, {ix, iy + 1}, {ix + 1, iy + 1}} {
+		if weights[n] == 0 || !p.In(b) {
+			continue
+		}
+		i := img.PixOffset(p.X, p.Y)
+		for k := 0; k < 4; k++ {
+			acc[k] += float64(img.Pix[i+k]) * weights[n]
+		}
+	}
+	return color.RGBA{uint8(acc[0] + .5), uint8(acc[1] + .5), uint8(acc[2] + .5), uint8(acc[3] + .5)}
+}
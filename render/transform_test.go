@@ -0,0 +1,97 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+)
+
+// transformSource returns a 2x1 image, red then blue.
+func transformSource() *image.RGBA {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.SetRGBA(0, 0, color.RGBA{255, 0, 0, 255})
+	src.SetRGBA(1, 0, color.RGBA{0, 0, 255, 255})
+	return src
+}
+
+func TestDrawTransformed(t *testing.T) {
+	var (
+		red   = color.RGBA{255, 0, 0, 255}
+		blue  = color.RGBA{0, 0, 255, 255}
+		clear = color.RGBA{}
+	)
+	type check struct {
+		x, y int
+		c    color.RGBA
+	}
+	tcs := []struct {
+		name   string
+		t      *Transform
+		checks []check
+	}{
+		{"nil", nil, []check{{4, 4, red}, {5, 4, blue}}},
+		{"zero", &Transform{}, []check{{4, 4, red}, {5, 4, blue}, {6, 4, clear}}},
+		{"flipX", &Transform{FlipX: true, Pivot: floatgeom.Point2{1, 0}}, []check{{4, 4, blue}, {5, 4, red}}},
+		{"flipY", &Transform{FlipY: true}, []check{{4, 3, red}, {5, 3, blue}, {4, 4, clear}}},
+		{"scale", &Transform{ScaleX: 2, ScaleY: 3}, []check{{5, 6, red}, {6, 4, blue}, {7, 6, blue}, {8, 4, clear}}},
+		// Turning 90 degrees counter-clockwise points the image up.
+		{"rotate", &Transform{Rotation: 90}, []check{{4, 3, red}, {4, 2, blue}, {5, 4, clear}}},
+		{"offset", &Transform{Offset: floatgeom.Point2{1, 2}}, []check{{5, 6, red}, {6, 6, blue}}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			buff := image.NewRGBA(image.Rect(0, 0, 10, 10))
+			DrawTransformed(buff, transformSource(), 4, 4, tc.t)
+			for _, c := range tc.checks {
+				if got := buff.RGBAAt(c.x, c.y); got != c.c {
+					t.Fatalf("expected %v at %v,%v, got %v", c.c, c.x, c.y, got)
+				}
+			}
+		})
+	}
+
+	// Half a pixel over, bilinear sampling blends neighbors.
+	buff := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	DrawTransformed(buff, transformSource(), 4.5, 4, &Transform{Sampling: Bilinear})
+	if got := buff.RGBAAt(5, 4); got.R < 120 || got.R > 135 || got.B < 120 || got.B > 135 {
+		t.Fatalf("expected an even blend of red and blue, got %v", got)
+	}
+
+	// Drawing to other images still works.
+	nrgba := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	DrawTransformed(nrgba, transformSource(), 4, 4, &Transform{FlipX: true, Pivot: floatgeom.Point2{1, 0}})
+	if got := nrgba.NRGBAAt(4, 4); got != (color.NRGBA{0, 0, 255, 255}) {
+		t.Fatalf("expected blue, got %v", got)
+	}
+}
+
+func TestTransformableDraw(t *testing.T) {
+	sp := NewSprite(0, 0, transformSource())
+	tr := &Transform{Rotation: 30, ScaleX: 1.5, Sampling: Bilinear}
+	sp.SetTransform(tr)
+	if sp.GetTransform() != tr {
+		t.Fatalf("sprite should keep its transform")
+	}
+	buff := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	allocs := testing.AllocsPerRun(10, func() {
+		tr.Rotation += 10
+		sp.Draw(buff, 4, 4)
+	})
+	if allocs != 0 {
+		t.Fatalf("drawing a transformed sprite allocated %v times", allocs)
+	}
+	cp := sp.Copy().(*Sprite)
+	if cp.GetTransform() == tr || *cp.GetTransform() != *tr {
+		t.Fatalf("copies should copy their transform")
+	}
+
+	sq := NewSequence(0, NewSprite(0, 0, transformSource()))
+	sq.SetTransform(&Transform{FlipX: true, Pivot: floatgeom.Point2{1, 0}})
+	buff = image.NewRGBA(image.Rect(0, 0, 16, 16))
+	sq.Draw(buff, 4, 4)
+	if buff.RGBAAt(4, 4) != (color.RGBA{0, 0, 255, 255}) {
+		t.Fatalf("sequence should draw its frames transformed")
+	}
+}
@@ -0,0 +1,3 @@
+// Package tiled loads maps made in the Tiled map editor, in its XML (.tmx) or
+// JSON (.tmj) formats, as renderables and collision spaces.
+package tiled
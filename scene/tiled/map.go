@@ -0,0 +1,404 @@
+package tiled
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/fileutil"
+	"github.com/oakmound/oak/v4/oakerr"
+	"github.com/oakmound/oak/v4/render"
+)
+
+// Flags Tiled sets in the high bits of a tile's global ID to flip it.
+const (
+	FlipHorizontal uint32 = 0x80000000
+	FlipVertical   uint32 = 0x40000000
+	FlipDiagonal   uint32 = 0x20000000
+	// flipHexagonal is only meaningful on hexagonal maps, which are not
+	// supported, but is still cleared from IDs.
+	flipHexagonal uint32 = 0x10000000
+	gidMask              = ^(FlipHorizontal | FlipVertical | FlipDiagonal | flipHexagonal)
+)
+
+// DefaultLabelProperty is the custom property objects and object layers are
+// labeled by, unless a map's LabelProperty is changed.
+const DefaultLabelProperty = "label"
+
+// A Map is a loaded Tiled map. Only orthogonal, finite maps are supported.
+type Map struct {
+	// Width and Height are the map's size in tiles.
+	Width, Height int
+	// TileWidth and TileHeight are the size of the map's grid cells in pixels.
+	TileWidth, TileHeight int
+	Properties            Properties
+	Tilesets              []*Tileset
+	TileLayers            []*TileLayer
+	ImageLayers           []*ImageLayer
+	ObjectGroups          []*ObjectGroup
+	// LabelProperty names the integer custom property which sets the
+	// collision label of object spaces, see Spaces.
+	LabelProperty string
+}
+
+// A Tileset is a set of tiles, cut from one image or made of one image per tile.
+type Tileset struct {
+	// FirstGID is the global ID of this tileset's first tile in the map.
+	FirstGID              uint32
+	Name                  string
+	TileWidth, TileHeight int
+	TileCount, Columns    int
+	Properties            Properties
+	// Sheet holds the tileset's tiles, indexed by column then row, if the
+	// tileset is cut from one image.
+	Sheet *render.Sheet
+	// Tiles holds tiles given properties, animations or their own images, by
+	// ID within the tileset.
+	Tiles map[int]*Tile
+}
+
+// A Tile is a tile of a tileset which has more than an image.
+type Tile struct {
+	ID         int
+	Type       string
+	Properties Properties
+	// Image is the tile's own image, in tilesets made of one image per tile.
+	Image     *image.RGBA
+	Animation []Frame
+}
+
+// A Frame is one frame of an animated tile.
+type Frame struct {
+	// TileID is the ID within the tileset of the tile shown this frame.
+	TileID   int
+	Duration time.Duration
+}
+
+// A TileLayer is a grid of tiles.
+type TileLayer struct {
+	Name string
+	// Index is the layer's position among all of the map's layers, bottom up.
+	Index         int
+	Width, Height int
+	Offset        floatgeom.Point2
+	Opacity       float64
+	Visible       bool
+	Properties    Properties
+	// GIDs holds the global ID of each tile, row by row. Empty cells are 0,
+	// and IDs may include flip flags.
+	GIDs []uint32
+}
+
+// GID returns the global ID of the tile at x,y, or 0 if there is none.
+func (l *TileLayer) GID(x, y int) uint32 {
+	if x < 0 || y < 0 || x >= l.Width || y >= l.Height {
+		return 0
+	}
+	return l.GIDs[y*l.Width+x]
+}
+
+// An ImageLayer is a single image.
+type ImageLayer struct {
+	Name       string
+	Index      int
+	Offset     floatgeom.Point2
+	Opacity    float64
+	Visible    bool
+	Properties Properties
+	Image      *image.RGBA
+}
+
+// An ObjectGroup is a layer of objects.
+type ObjectGroup struct {
+	Name       string
+	Index      int
+	Offset     floatgeom.Point2
+	Opacity    float64
+	Visible    bool
+	Properties Properties
+	Objects    []*Object
+}
+
+// An Object is a rectangle, ellipse, point, polygon, polyline or tile placed
+// on an object layer.
+type Object struct {
+	ID int
+	// Type is the object's type, or class in Tiled 1.9.
+	Type                string
+	Name                string
+	X, Y, Width, Height float64
+	// Rotation is in degrees clockwise around X,Y.
+	Rotation float64
+	Visible  bool
+	Ellipse  bool
+	Point    bool
+	// Polygon and Polyline hold points relative to X,Y.
+	Polygon  []floatgeom.Point2
+	Polyline []floatgeom.Point2
+	// GID is set on tile objects, which are drawn from their bottom left.
+	GID        uint32
+	Properties Properties
+}
+
+// Tileset returns the tileset holding a global tile ID and the tile's ID
+// within it.
+func (m *Map) Tileset(gid uint32) (*Tileset, int, bool) {
+	gid &= gidMask
+	if gid == 0 {
+		return nil, 0, false
+	}
+	var found *Tileset
+	for _, ts := range m.Tilesets {
+		if ts.FirstGID <= gid && (found == nil || ts.FirstGID > found.FirstGID) {
+			found = ts
+		}
+	}
+	if found == nil {
+		return nil, 0, false
+	}
+	return found, int(gid - found.FirstGID), true
+}
+
+// TileLayer returns the first tile layer with the given name.
+func (m *Map) TileLayer(name string) (*TileLayer, bool) {
+	for _, l := range m.TileLayers {
+		if l.Name == name {
+			return l, true
+		}
+	}
+	return nil, false
+}
+
+// ObjectGroup returns the first object layer with the given name.
+func (m *Map) ObjectGroup(name string) (*ObjectGroup, bool) {
+	for _, g := range m.ObjectGroups {
+		if g.Name == name {
+			return g, true
+		}
+	}
+	return nil, false
+}
+
+// Object returns the first object in any object layer with the given name.
+func (m *Map) Object(name string) (*Object, bool) {
+	for _, g := range m.ObjectGroups {
+		for _, o := range g.Objects {
+			if o.Name == name {
+				return o, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// TileImage returns the image of the tile with the given ID within this
+// tileset, or nil if there is none.
+func (ts *Tileset) TileImage(id int) *image.RGBA {
+	if t, ok := ts.Tiles[id]; ok && t.Image != nil {
+		return t.Image
+	}
+	if ts.Sheet == nil || ts.Columns == 0 {
+		return nil
+	}
+	x, y := id%ts.Columns, id/ts.Columns
+	sh := *ts.Sheet
+	if x >= len(sh) || y >= len(sh[x]) {
+		return nil
+	}
+	return sh[x][y]
+}
+
+type loader struct {
+	cache *render.Cache
+}
+
+// Option configures how maps are loaded.
+type Option func(*loader)
+
+// WithCache sets the cache map and tileset images are loaded through. By
+// default, render.DefaultCache is used.
+func WithCache(c *render.Cache) Option {
+	return func(l *loader) {
+		l.cache = c
+	}
+}
+
+// Load reads a map from a .tmx or .tmj file. Tilesets and images the map
+// refers to are loaded relative to it.
+func Load(file string, opts ...Option) (*Map, error) {
+	f, err := fileutil.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".tmx", ".xml":
+		return ParseTMX(f, filepath.Dir(file), opts...)
+	case ".tmj", ".json":
+		return ParseTMJ(f, filepath.Dir(file), opts...)
+	}
+	return nil, oakerr.UnsupportedFormat{Format: filepath.Ext(file)}
+}
+
+func newLoader(opts []Option) *loader {
+	l := &loader{
+		cache: render.DefaultCache,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+func newMap(orientation string, infinite bool) (*Map, error) {
+	if orientation != "" && orientation != "orthogonal" {
+		return nil, oakerr.UnsupportedFormat{Format: orientation}
+	}
+	if infinite {
+		return nil, oakerr.UnsupportedFormat{Format: "infinite"}
+	}
+	return &Map{
+		Properties:    Properties{},
+		LabelProperty: DefaultLabelProperty,
+	}, nil
+}
+
+func (l *loader) loadImage(dir, source string) (*image.RGBA, error) {
+	sp, err := l.cache.LoadSprite(filepath.Join(dir, source))
+	if err != nil {
+		return nil, err
+	}
+	return sp.GetRGBA(), nil
+}
+
+// cutTileset cuts a tileset's image into its sheet.
+func (l *loader) cutTileset(ts *Tileset, dir, source string, spacing, margin int) error {
+	if ts.TileWidth <= 0 || ts.TileHeight <= 0 {
+		return oakerr.InvalidInput{InputName: "tilewidth"}
+	}
+	rgba, err := l.loadImage(dir, source)
+	if err != nil {
+		return err
+	}
+	b := rgba.Bounds()
+	if ts.Columns == 0 {
+		ts.Columns = (b.Dx() - 2*margin + spacing) / (ts.TileWidth + spacing)
+	}
+	rows := (b.Dy() - 2*margin + spacing) / (ts.TileHeight + spacing)
+	if ts.TileCount != 0 && ts.Columns != 0 {
+		rows = (ts.TileCount + ts.Columns - 1) / ts.Columns
+	}
+	sheet := make(render.Sheet, ts.Columns)
+	for x := range sheet {
+		sheet[x] = make([]*image.RGBA, rows)
+		for y := range sheet[x] {
+			min := b.Min.Add(image.Pt(
+				margin+x*(ts.TileWidth+spacing),
+				margin+y*(ts.TileHeight+spacing),
+			))
+			sheet[x][y] = cut(rgba, image.Rectangle{Min: min, Max: min.Add(image.Pt(ts.TileWidth, ts.TileHeight))})
+		}
+	}
+	ts.Sheet = &sheet
+	return nil
+}
+
+// cut copies part of an image into a new image at the origin.
+func cut(rgba *image.RGBA, r image.Rectangle) *image.RGBA {
+	out := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	for y := 0; y < r.Dy(); y++ {
+		for x := 0; x < r.Dx(); x++ {
+			out.SetRGBA(x, y, rgba.RGBAAt(r.Min.X+x, r.Min.Y+y))
+		}
+	}
+	return out
+}
+
+// decodeGIDs decodes a tile layer's data, as csv or base64 with optional zlib
+// or gzip compression, expecting n tiles.
+func decodeGIDs(data, encoding, compression string, n int) ([]uint32, error) {
+	var gids []uint32
+	switch encoding {
+	case "csv":
+		for _, field := range strings.Split(data, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			gids = append(gids, uint32(gid))
+		}
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+		if err != nil {
+			return nil, err
+		}
+		var r io.Reader = bytes.NewReader(raw)
+		switch compression {
+		case "":
+		case "zlib":
+			r, err = zlib.NewReader(r)
+		case "gzip":
+			r, err = gzip.NewReader(r)
+		default:
+			return nil, oakerr.UnsupportedFormat{Format: compression}
+		}
+		if err != nil {
+			return nil, err
+		}
+		raw, err = io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		gids = make([]uint32, len(raw)/4)
+		for i := range gids {
+			gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+		}
+	default:
+		return nil, oakerr.UnsupportedFormat{Format: encoding}
+	}
+	if len(gids) != n {
+		return nil, oakerr.InvalidInput{InputName: "data"}
+	}
+	return gids, nil
+}
+
+// parsePoints parses a list of points formatted as "x,y x,y ...".
+func parsePoints(s string) ([]floatgeom.Point2, error) {
+	var pts []floatgeom.Point2
+	for _, pair := range strings.Fields(s) {
+		xy := strings.Split(pair, ",")
+		if len(xy) != 2 {
+			return nil, oakerr.InvalidInput{InputName: "points"}
+		}
+		x, err := strconv.ParseFloat(xy[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		y, err := strconv.ParseFloat(xy[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		pts = append(pts, floatgeom.Point2{x, y})
+	}
+	return pts, nil
+}
+
+func orDefault(v *float64, def float64) float64 {
+	if v == nil {
+		return def
+	}
+	return *v
+}
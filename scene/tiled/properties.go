@@ -0,0 +1,44 @@
+package tiled
+
+import "strconv"
+
+// Properties are the custom properties set on a map, layer, tile or object in
+// Tiled, by name. Every value is held as text; members of class properties are
+// held as "property.member".
+type Properties map[string]string
+
+// Get returns the named property.
+func (p Properties) Get(name string) (string, bool) {
+	v, ok := p[name]
+	return v, ok
+}
+
+// Int returns the named property as an int, if it is set and is one.
+func (p Properties) Int(name string) (int, bool) {
+	v, ok := p[name]
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.Atoi(v)
+	return i, err == nil
+}
+
+// Float returns the named property as a float64, if it is set and is one.
+func (p Properties) Float(name string) (float64, bool) {
+	v, ok := p[name]
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	return f, err == nil
+}
+
+// Bool returns the named property as a bool, if it is set and is one.
+func (p Properties) Bool(name string) (bool, bool) {
+	v, ok := p[name]
+	if !ok {
+		return false, false
+	}
+	b, err := strconv.ParseBool(v)
+	return b, err == nil
+}
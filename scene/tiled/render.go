@@ -0,0 +1,214 @@
+package tiled
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/render"
+)
+
+// TileRenderable returns a renderable for the tile with the given global ID: a
+// sprite, or a sequence if the tile is animated. Flip flags in the ID are
+// applied. If no tileset holds the ID, it returns false.
+func (m *Map) TileRenderable(gid uint32) (render.Modifiable, bool) {
+	return m.tileRenderable(gid, 1)
+}
+
+func (m *Map) tileRenderable(gid uint32, opacity float64) (render.Modifiable, bool) {
+	ts, id, ok := m.Tileset(gid)
+	if !ok {
+		return nil, false
+	}
+	if t, ok := ts.Tiles[id]; ok && len(t.Animation) != 0 {
+		return m.animation(ts, t, gid, opacity), true
+	}
+	img := ts.TileImage(id)
+	if img == nil {
+		return nil, false
+	}
+	return render.NewSprite(0, 0, fade(flip(img, gid), opacity)), true
+}
+
+// animation builds a sequence for an animated tile, showing each frame for
+// its own duration. Frames without a duration show for a tenth of a second.
+func (m *Map) animation(ts *Tileset, t *Tile, gid uint32, opacity float64) *render.Sequence {
+	mods := []render.Modifiable{}
+	durations := []time.Duration{}
+	for _, f := range t.Animation {
+		img := ts.TileImage(f.TileID)
+		if img == nil {
+			continue
+		}
+		mods = append(mods, render.NewSprite(0, 0, fade(flip(img, gid), opacity)))
+		d := f.Duration
+		if d <= 0 {
+			d = 100 * time.Millisecond
+		}
+		durations = append(durations, d)
+	}
+	sq := render.NewSequence(10, mods...)
+	// There is always one duration per frame
+	sq.SetFrameDurations(durations...)
+	return sq
+}
+
+// LayerRenderables returns renderables drawing a tile layer: a single sprite
+// holding all of its still tiles, followed by a sequence for each animated
+// tile. Tiles larger than the map's grid are drawn from the bottom left of
+// their cell, as in Tiled.
+func (m *Map) LayerRenderables(l *TileLayer) []render.Modifiable {
+	type placed struct {
+		img *image.RGBA
+		at  image.Point
+	}
+	var (
+		still  []placed
+		bounds image.Rectangle
+		out    []render.Modifiable
+	)
+	for y := 0; y < l.Height; y++ {
+		for x := 0; x < l.Width; x++ {
+			gid := l.GID(x, y)
+			ts, id, ok := m.Tileset(gid)
+			if !ok {
+				continue
+			}
+			img := ts.TileImage(id)
+			if t, ok := ts.Tiles[id]; ok && len(t.Animation) != 0 {
+				img = ts.TileImage(t.Animation[0].TileID)
+				if img == nil {
+					continue
+				}
+				at := m.cellPosition(x, y, flip(img, gid))
+				sq := m.animation(ts, t, gid, l.Opacity)
+				sq.SetPos(l.Offset.X()+float64(at.X), l.Offset.Y()+float64(at.Y))
+				out = append(out, sq)
+				continue
+			}
+			if img == nil {
+				continue
+			}
+			img = flip(img, gid)
+			at := m.cellPosition(x, y, img)
+			still = append(still, placed{img: img, at: at})
+			bounds = bounds.Union(img.Bounds().Add(at))
+		}
+	}
+	if len(still) == 0 {
+		return out
+	}
+	canvas := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for _, p := range still {
+		r := p.img.Bounds().Add(p.at.Sub(bounds.Min))
+		draw.Draw(canvas, r, p.img, image.Point{}, draw.Over)
+	}
+	sp := render.NewSprite(
+		l.Offset.X()+float64(bounds.Min.X),
+		l.Offset.Y()+float64(bounds.Min.Y),
+		fade(canvas, l.Opacity),
+	)
+	return append([]render.Modifiable{sp}, out...)
+}
+
+func (m *Map) cellPosition(x, y int, img *image.RGBA) image.Point {
+	return image.Pt(x*m.TileWidth, (y+1)*m.TileHeight-img.Bounds().Dy())
+}
+
+// Draw draws the map's visible tile layers, image layers and tile objects to
+// a draw stack. Each layer is drawn to the given stack layers followed by its
+// Index, so that within a heap layers keep the order they have in Tiled.
+func (m *Map) Draw(ds *render.DrawStack, layers ...int) ([]render.Renderable, error) {
+	drawn := []render.Renderable{}
+	drawAt := func(r render.Renderable, index int) error {
+		r, err := ds.Draw(r, append(append([]int{}, layers...), index)...)
+		if err != nil {
+			return err
+		}
+		drawn = append(drawn, r)
+		return nil
+	}
+	for _, l := range m.TileLayers {
+		if !l.Visible {
+			continue
+		}
+		for _, r := range m.LayerRenderables(l) {
+			if err := drawAt(r, l.Index); err != nil {
+				return drawn, err
+			}
+		}
+	}
+	for _, il := range m.ImageLayers {
+		if !il.Visible || il.Image == nil {
+			continue
+		}
+		sp := render.NewSprite(il.Offset.X(), il.Offset.Y(), fade(il.Image, il.Opacity))
+		if err := drawAt(sp, il.Index); err != nil {
+			return drawn, err
+		}
+	}
+	for _, g := range m.ObjectGroups {
+		if !g.Visible {
+			continue
+		}
+		for _, o := range g.Objects {
+			if o.GID == 0 || !o.Visible {
+				continue
+			}
+			r, ok := m.tileRenderable(o.GID, g.Opacity)
+			if !ok {
+				continue
+			}
+			_, h := r.GetDims()
+			pos := g.Offset.Add(floatgeom.Point2{o.X, o.Y - float64(h)})
+			r.SetPos(pos.X(), pos.Y())
+			if err := drawAt(r, g.Index); err != nil {
+				return drawn, err
+			}
+		}
+	}
+	return drawn, nil
+}
+
+// flip applies the flip flags of a tile's global ID to its image. Diagonal
+// flips are applied first, as in Tiled.
+func flip(img *image.RGBA, gid uint32) *image.RGBA {
+	if gid&(FlipHorizontal|FlipVertical|FlipDiagonal) == 0 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if gid&FlipDiagonal != 0 {
+		w, h = h, w
+	}
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sx, sy := x, y
+			if gid&FlipHorizontal != 0 {
+				sx = w - 1 - sx
+			}
+			if gid&FlipVertical != 0 {
+				sy = h - 1 - sy
+			}
+			if gid&FlipDiagonal != 0 {
+				sx, sy = sy, sx
+			}
+			out.SetRGBA(x, y, img.RGBAAt(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return out
+}
+
+// fade returns a copy of img drawn at the given opacity.
+func fade(img *image.RGBA, opacity float64) *image.RGBA {
+	if opacity >= 1 {
+		return img
+	}
+	out := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	mask := image.NewUniform(color.Alpha{A: uint8(opacity * 255)})
+	draw.DrawMask(out, out.Bounds(), img, img.Bounds().Min, mask, image.Point{}, draw.Over)
+	return out
+}
@@ -0,0 +1,230 @@
+package tiled
+
+import (
+	"math"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+)
+
+// ellipsePoints is how many points approximate ellipses which are not circles.
+const ellipsePoints = 16
+
+// Spaces returns collision spaces for each rectangle, ellipse, polygon and
+// tile object in the map's object layers, whether or not they are visible.
+// Each space is labeled by its object's LabelProperty, or failing that its
+// layer's. Points and polylines have no area and are skipped. Concave
+// polygons are split into several convex spaces, and polygons which cannot be
+// split, like those crossing themselves, are covered by their bounding
+// rectangle.
+func (m *Map) Spaces() []*collision.Space {
+	spaces := []*collision.Space{}
+	for _, g := range m.ObjectGroups {
+		groupLabel, _ := g.Properties.Int(m.LabelProperty)
+		for _, o := range g.Objects {
+			label, ok := o.Properties.Int(m.LabelProperty)
+			if !ok {
+				label = groupLabel
+			}
+			for _, s := range objectSpaces(o, g.Offset) {
+				s.Label = collision.Label(label)
+				spaces = append(spaces, s)
+			}
+		}
+	}
+	return spaces
+}
+
+func objectSpaces(o *Object, offset floatgeom.Point2) []*collision.Space {
+	origin := offset.Add(floatgeom.Point2{o.X, o.Y})
+	var pts []floatgeom.Point2
+	switch {
+	case o.Point || o.Polyline != nil:
+		return nil
+	case o.Polygon != nil:
+		pts = o.Polygon
+	case o.Width <= 0 || o.Height <= 0:
+		return nil
+	case o.Ellipse:
+		rx, ry := o.Width/2, o.Height/2
+		if rx == ry && o.Rotation == 0 {
+			return []*collision.Space{collision.NewCircleSpace(origin.X()+rx, origin.Y()+ry, rx, 0)}
+		}
+		for i := 0; i < ellipsePoints; i++ {
+			angle := 2 * math.Pi * float64(i) / ellipsePoints
+			pts = append(pts, floatgeom.Point2{rx + rx*math.Cos(angle), ry + ry*math.Sin(angle)})
+		}
+	default:
+		min := floatgeom.Point2{}
+		if o.GID != 0 {
+			// Tile objects are placed by their bottom left corner.
+			min = floatgeom.Point2{0, -o.Height}
+		}
+		if o.Rotation == 0 {
+			min = origin.Add(min)
+			return []*collision.Space{collision.NewUnassignedSpace(min.X(), min.Y(), o.Width, o.Height)}
+		}
+		pts = []floatgeom.Point2{
+			min,
+			min.Add(floatgeom.Point2{o.Width, 0}),
+			min.Add(floatgeom.Point2{o.Width, o.Height}),
+			min.Add(floatgeom.Point2{0, o.Height}),
+		}
+	}
+	sin, cos := math.Sincos(o.Rotation * math.Pi / 180)
+	pg := make([]floatgeom.Point2, len(pts))
+	for i, p := range pts {
+		pg[i] = origin.Add(floatgeom.Point2{
+			p.X()*cos - p.Y()*sin,
+			p.X()*sin + p.Y()*cos,
+		})
+	}
+	return polygonSpaces(pg)
+}
+
+// polygonSpaces returns spaces covering a polygon: one if it is convex,
+// several if it is concave, or its bounding rectangle if it cannot be split.
+func polygonSpaces(pg []floatgeom.Point2) []*collision.Space {
+	if len(pg) < 3 {
+		return nil
+	}
+	if s, err := collision.NewPolygonSpace(floatgeom.Polygon2{Points: pg}, 0); err == nil {
+		return []*collision.Space{s}
+	}
+	pieces, ok := convexPieces(pg)
+	if ok {
+		spaces := make([]*collision.Space, 0, len(pieces))
+		for _, piece := range pieces {
+			if s, err := collision.NewPolygonSpace(floatgeom.Polygon2{Points: piece}, 0); err == nil {
+				spaces = append(spaces, s)
+			}
+		}
+		return spaces
+	}
+	r := floatgeom.NewBoundingRect2(pg...)
+	if r.W() <= 0 || r.H() <= 0 {
+		return nil
+	}
+	return []*collision.Space{collision.NewUnassignedSpace(r.Min.X(), r.Min.Y(), r.W(), r.H())}
+}
+
+// convexPieces splits a simple polygon into convex polygons, by cutting it
+// into triangles, then merging neighboring pieces while they stay convex. It
+// fails for polygons with no area or which cross themselves.
+func convexPieces(pg []floatgeom.Point2) ([][]floatgeom.Point2, bool) {
+	pts := append([]floatgeom.Point2(nil), pg...)
+	area := 0.0
+	for i, p := range pts {
+		area += cross(p, pts[(i+1)%len(pts)])
+	}
+	if area == 0 {
+		return nil, false
+	}
+	// Wind the polygon so convex corners turn positively
+	if area < 0 {
+		for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+			pts[i], pts[j] = pts[j], pts[i]
+		}
+	}
+	pieces := [][]floatgeom.Point2{}
+	for len(pts) > 3 {
+		clipped := false
+		for i := range pts {
+			a, b, c := pts[(i+len(pts)-1)%len(pts)], pts[i], pts[(i+1)%len(pts)]
+			turn := cross(b.Sub(a), c.Sub(b))
+			if turn < 0 {
+				continue
+			}
+			if turn > 0 && !isEar(pts, i, a, b, c) {
+				continue
+			}
+			// Ears are cut off; straight corners are just dropped.
+			if turn > 0 {
+				pieces = append(pieces, []floatgeom.Point2{a, b, c})
+			}
+			pts = append(pts[:i], pts[i+1:]...)
+			clipped = true
+			break
+		}
+		if !clipped {
+			return nil, false
+		}
+	}
+	if cross(pts[1].Sub(pts[0]), pts[2].Sub(pts[1])) > 0 {
+		pieces = append(pieces, pts)
+	}
+	return mergeConvex(pieces), true
+}
+
+// isEar returns whether no other corner of a polygon lies within the triangle
+// of its corner i and that corner's neighbors.
+func isEar(pts []floatgeom.Point2, i int, a, b, c floatgeom.Point2) bool {
+	prev, next := (i+len(pts)-1)%len(pts), (i+1)%len(pts)
+	for j, p := range pts {
+		if j == i || j == prev || j == next {
+			continue
+		}
+		if cross(b.Sub(a), p.Sub(a)) >= 0 && cross(c.Sub(b), p.Sub(b)) >= 0 && cross(a.Sub(c), p.Sub(c)) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeConvex joins pairs of pieces sharing an edge while their union is
+// convex.
+func mergeConvex(pieces [][]floatgeom.Point2) [][]floatgeom.Point2 {
+	for merged := true; merged; {
+		merged = false
+	search:
+		for i := range pieces {
+			for j := i + 1; j < len(pieces); j++ {
+				if u, ok := union(pieces[i], pieces[j]); ok && turnsPositively(u) {
+					pieces[i] = u
+					pieces = append(pieces[:j], pieces[j+1:]...)
+					merged = true
+					break search
+				}
+			}
+		}
+	}
+	return pieces
+}
+
+// union joins two polygons wound the same way along an edge they share.
+func union(p, q []floatgeom.Point2) ([]floatgeom.Point2, bool) {
+	for k := range p {
+		a, b := p[k], p[(k+1)%len(p)]
+		for m := range q {
+			if q[m] != b || q[(m+1)%len(q)] != a {
+				continue
+			}
+			// p from b around to a, then q between a and b
+			out := make([]floatgeom.Point2, 0, len(p)+len(q)-2)
+			for i := 1; i <= len(p); i++ {
+				out = append(out, p[(k+i)%len(p)])
+			}
+			for i := 2; i < len(q); i++ {
+				out = append(out, q[(m+i)%len(q)])
+			}
+			return out, true
+		}
+	}
+	return nil, false
+}
+
+// turnsPositively returns whether every corner of a polygon is convex or
+// straight.
+func turnsPositively(pts []floatgeom.Point2) bool {
+	for i := range pts {
+		a, b, c := pts[i], pts[(i+1)%len(pts)], pts[(i+2)%len(pts)]
+		if cross(b.Sub(a), c.Sub(b)) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func cross(a, b floatgeom.Point2) float64 {
+	return a.X()*b.Y() - a.Y()*b.X()
+}
@@ -0,0 +1,15 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" tiledversion="1.10.2" name="tiles" tilewidth="16" tileheight="16" tilecount="4" columns="2">
+ <image source="tiles.png" width="32" height="32"/>
+ <tile id="0">
+  <animation>
+   <frame tileid="0" duration="100"/>
+   <frame tileid="1" duration="200"/>
+  </animation>
+ </tile>
+ <tile id="3" type="ice">
+  <properties>
+   <property name="friction" type="float" value="0.1"/>
+  </properties>
+ </tile>
+</tileset>
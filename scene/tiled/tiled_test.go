@@ -0,0 +1,281 @@
+package tiled
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/collision"
+	"github.com/oakmound/oak/v4/render"
+)
+
+var (
+	red   = color.RGBA{255, 0, 0, 255}
+	green = color.RGBA{0, 255, 0, 255}
+	blue  = color.RGBA{0, 0, 255, 255}
+	white = color.RGBA{255, 255, 255, 255}
+)
+
+func TestLoad(t *testing.T) {
+	for _, file := range []string{"testdata/level.tmx", "testdata/level.tmj"} {
+		file := file
+		t.Run(file, func(t *testing.T) {
+			m, err := Load(file, WithCache(render.NewCache()))
+			if err != nil {
+				t.Fatalf("load failed: %v", err)
+			}
+			if m.Width != 3 || m.Height != 2 || m.TileWidth != 16 || m.TileHeight != 16 {
+				t.Fatalf("wrong map dimensions: %v %v %v %v", m.Width, m.Height, m.TileWidth, m.TileHeight)
+			}
+			if v, _ := m.Properties.Get("music"); v != "level1.wav" {
+				t.Fatalf("expected music property, got %q", v)
+			}
+			if par, ok := m.Properties.Int("par"); !ok || par != 90 {
+				t.Fatalf("expected par 90, got %v", par)
+			}
+			if n, ok := m.Properties.Int("spawn.enemies"); !ok || n != 3 {
+				t.Fatalf("expected class member spawn.enemies 3, got %v", n)
+			}
+
+			if len(m.Tilesets) != 2 {
+				t.Fatalf("expected 2 tilesets, got %v", len(m.Tilesets))
+			}
+			ts, id, ok := m.Tileset(4)
+			if !ok || ts != m.Tilesets[0] || id != 3 {
+				t.Fatalf("gid 4 should be tile 3 of the external tileset")
+			}
+			if ts.Tiles[3].Type != "ice" {
+				t.Fatalf("expected ice tile, got %q", ts.Tiles[3].Type)
+			}
+			if f, ok := ts.Tiles[3].Properties.Float("friction"); !ok || f != .1 {
+				t.Fatalf("expected friction .1, got %v", f)
+			}
+			if ts, id, _ := m.Tileset(6 | FlipVertical); ts != m.Tilesets[1] || id != 1 {
+				t.Fatalf("gid 6 should be tile 1 of the embedded tileset")
+			}
+			if ts.TileImage(2).RGBAAt(0, 0) != blue {
+				t.Fatalf("tile 2 should be blue")
+			}
+
+			ground, ok := m.TileLayer("ground")
+			if !ok || ground.Index != 0 || !ground.Visible || ground.Opacity != 1 {
+				t.Fatalf("bad ground layer: %+v", ground)
+			}
+			if b, ok := ground.Properties.Bool("parallax"); !ok || b {
+				t.Fatalf("expected parallax false")
+			}
+			if ground.GID(2, 0) != 3|FlipHorizontal || ground.GID(1, 1) != 0 || ground.GID(3, 0) != 0 {
+				t.Fatalf("bad ground gids: %v", ground.GIDs)
+			}
+			decor, ok := m.TileLayer("decor")
+			if !ok || decor.Index != 1 || decor.Opacity != .5 || decor.Offset != (floatgeom.Point2{8, 4}) {
+				t.Fatalf("bad decor layer: %+v", decor)
+			}
+			for i, gid := range ground.GIDs {
+				if decor.GIDs[i] != gid {
+					t.Fatalf("decor gids should match ground, got %v", decor.GIDs)
+				}
+			}
+			hidden, ok := m.TileLayer("hidden")
+			if !ok || hidden.Index != 3 || hidden.Visible || hidden.GID(2, 1) != 6 {
+				t.Fatalf("bad hidden layer: %+v", hidden)
+			}
+			walls, ok := m.ObjectGroup("walls")
+			if !ok || walls.Index != 2 || len(walls.Objects) != 5 {
+				t.Fatalf("bad walls layer: %+v", walls)
+			}
+			spawn, ok := m.Object("spawn")
+			if !ok || !spawn.Point || spawn.X != 5 || spawn.Y != 6 {
+				t.Fatalf("bad spawn object: %+v", spawn)
+			}
+			if floor, _ := m.Object("floor"); floor.Type != "solid" {
+				t.Fatalf("expected solid floor, got %q", floor.Type)
+			}
+
+			spaces := m.Spaces()
+			if len(spaces) != 4 {
+				t.Fatalf("expected 4 spaces, got %v", len(spaces))
+			}
+			floor, boulder, ramp, crate := spaces[0], spaces[1], spaces[2], spaces[3]
+			if floor.Label != 2 || floor.X() != 0 || floor.Y() != 32 || floor.W() != 48 || floor.H() != 8 {
+				t.Fatalf("bad floor space: %v", floor.Location)
+			}
+			if _, ok := boulder.Shape.(collision.Circle); !ok || boulder.X() != 10 || boulder.Y() != 0 {
+				t.Fatalf("boulder should be a circle at 10,0, got %v", boulder.Location)
+			}
+			if _, ok := ramp.Shape.(collision.ConvexPolygon); !ok || ramp.X() != 20 || ramp.Y() != 10 {
+				t.Fatalf("ramp should be a polygon at 20,10, got %v", ramp.Location)
+			}
+			if crate.Label != 3 || crate.X() != 32 || crate.Y() != 16 {
+				t.Fatalf("bad crate space: %v %v", crate.Label, crate.Location)
+			}
+
+			rs := m.LayerRenderables(ground)
+			if len(rs) != 2 {
+				t.Fatalf("expected a sprite and a sequence, got %v renderables", len(rs))
+			}
+			sp, ok := rs[0].(*render.Sprite)
+			if !ok {
+				t.Fatalf("expected still tiles as a sprite, got %T", rs[0])
+			}
+			if w, h := sp.GetDims(); w != 48 || h != 32 {
+				t.Fatalf("expected a 48x32 sprite, got %vx%v", w, h)
+			}
+			rgba := sp.GetRGBA()
+			if rgba.RGBAAt(0, 0) != (color.RGBA{}) || rgba.RGBAAt(16, 0) != green ||
+				rgba.RGBAAt(32, 0) != blue || rgba.RGBAAt(0, 16) != white || rgba.RGBAAt(32, 16) != red {
+				t.Fatalf("tiles drawn to the wrong places")
+			}
+			sq, ok := rs[1].(*render.Sequence)
+			if !ok {
+				t.Fatalf("expected animated tile as a sequence, got %T", rs[1])
+			}
+			if sq.Get(0).GetRGBA().RGBAAt(0, 0) != red || sq.Get(1).GetRGBA().RGBAAt(0, 0) != green {
+				t.Fatalf("frames should be in order")
+			}
+			if ds := sq.FrameDurations(); len(ds) != 2 || ds[0] != 100*time.Millisecond || ds[1] != 200*time.Millisecond {
+				t.Fatalf("frames should keep their durations, got %v", ds)
+			}
+			if rs := m.LayerRenderables(decor); rs[0].X() != 8 || rs[0].Y() != 4 ||
+				rs[0].GetRGBA().RGBAAt(16, 0).A != 127 {
+				t.Fatalf("decor should be offset and faded")
+			}
+
+			ds := render.NewDrawStack(render.NewDynamicHeap())
+			drawn, err := m.Draw(ds)
+			if err != nil {
+				t.Fatalf("draw failed: %v", err)
+			}
+			// Two renderables for each visible tile layer, plus the crate.
+			if len(drawn) != 5 {
+				t.Fatalf("expected 5 renderables drawn, got %v", len(drawn))
+			}
+			if layer := drawn[4].GetLayer(); layer != walls.Index {
+				t.Fatalf("crate should be drawn on the walls layer, got %v", layer)
+			}
+			if drawn[4].X() != 32 || drawn[4].Y() != 16 {
+				t.Fatalf("crate drawn at %v,%v", drawn[4].X(), drawn[4].Y())
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load("testdata/tiles.png"); err == nil {
+		t.Fatalf("expected unsupported format error")
+	}
+	if _, err := Load("testdata/missing.tmx"); err == nil {
+		t.Fatalf("expected missing file error")
+	}
+	if _, err := ParseTMX(strings.NewReader(`<map orientation="isometric"></map>`), "."); err == nil {
+		t.Fatalf("expected isometric maps to be unsupported")
+	}
+	if _, err := ParseTMJ(strings.NewReader(`{"orientation": "orthogonal", "infinite": true}`), "."); err == nil {
+		t.Fatalf("expected infinite maps to be unsupported")
+	}
+	if _, err := decodeGIDs("1,2,3", "csv", "", 4); err == nil {
+		t.Fatalf("expected too few tiles to fail")
+	}
+	if _, err := decodeGIDs("AAAA", "base64", "zstd", 1); err == nil {
+		t.Fatalf("expected zstd to be unsupported")
+	}
+}
+
+func TestConcaveSpaces(t *testing.T) {
+	m := &Map{LabelProperty: "label", ObjectGroups: []*ObjectGroup{{Objects: []*Object{{
+		X:          20,
+		Polygon:    []floatgeom.Point2{{0, 0}, {10, 0}, {5, 2}, {10, 10}, {0, 10}},
+		Properties: Properties{"label": "3"},
+	}, {
+		// Crosses itself, so is covered by its bounding rectangle
+		X:       40,
+		Polygon: []floatgeom.Point2{{0, 0}, {10, 10}, {10, 0}, {0, 10}},
+	}}}}}
+	spaces := m.Spaces()
+	if len(spaces) < 3 {
+		t.Fatalf("expected the concave polygon to be split, got %v spaces", len(spaces))
+	}
+	bowtie := spaces[len(spaces)-1]
+	if bowtie.X() != 40 || bowtie.W() != 10 || bowtie.H() != 10 {
+		t.Fatalf("expected a bounding rectangle, got %v", bowtie.Bounds())
+	}
+	covered := func(x, y float64) bool {
+		pt := collision.NewUnassignedSpace(x, y, .01, .01)
+		for _, s := range spaces[:len(spaces)-1] {
+			if s.Label != 3 {
+				t.Fatalf("expected pieces to keep their object's label, got %v", s.Label)
+			}
+			if pt.Collides(s) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, p := range []floatgeom.Point2{{22, 5}, {29, .2}, {29, 9.8}, {21, 9}} {
+		if !covered(p.X(), p.Y()) {
+			t.Fatalf("expected %v to be covered", p)
+		}
+	}
+	if covered(29, 3) || covered(27, 5) {
+		t.Fatalf("expected the notch to be left open")
+	}
+}
+
+func TestAnimationDurations(t *testing.T) {
+	tile := func(c color.RGBA) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+		img.SetRGBA(0, 0, c)
+		return img
+	}
+	ts := &Tileset{FirstGID: 1, Tiles: map[int]*Tile{
+		0: {ID: 0, Image: tile(red), Animation: []Frame{
+			{TileID: 0, Duration: 17 * time.Millisecond},
+			{TileID: 1, Duration: 33 * time.Millisecond},
+			{TileID: 2, Duration: time.Second},
+			{TileID: 1, Duration: time.Millisecond},
+		}},
+		1: {ID: 1, Image: tile(green)},
+		2: {ID: 2, Image: tile(blue)},
+	}}
+	m := &Map{Tilesets: []*Tileset{ts}}
+	r, ok := m.TileRenderable(1)
+	if !ok {
+		t.Fatalf("expected a renderable for the animated tile")
+	}
+	sq, ok := r.(*render.Sequence)
+	if !ok {
+		t.Fatalf("expected a sequence, got %T", r)
+	}
+	ds := sq.FrameDurations()
+	if len(ds) != 4 || ds[0] != 17*time.Millisecond || ds[1] != 33*time.Millisecond ||
+		ds[2] != time.Second || ds[3] != time.Millisecond {
+		t.Fatalf("expected one frame per tiled frame with its duration, got %v", ds)
+	}
+	if sq.Get(2).GetRGBA().RGBAAt(0, 0) != blue || sq.Get(3).GetRGBA().RGBAAt(0, 0) != green {
+		t.Fatalf("frames drawn from the wrong tiles")
+	}
+}
+
+func TestFlip(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.SetRGBA(0, 0, red)
+	img.SetRGBA(1, 0, blue)
+	if out := flip(img, FlipHorizontal); out.RGBAAt(0, 0) != blue || out.RGBAAt(1, 0) != red {
+		t.Fatalf("horizontal flip failed")
+	}
+	out := flip(img, FlipDiagonal)
+	if out.Bounds().Dx() != 1 || out.Bounds().Dy() != 2 || out.RGBAAt(0, 0) != red || out.RGBAAt(0, 1) != blue {
+		t.Fatalf("diagonal flip failed")
+	}
+	// Rotating 90 degrees clockwise is a diagonal then horizontal flip.
+	out = flip(img, FlipDiagonal|FlipHorizontal)
+	if out.RGBAAt(0, 0) != red || out.RGBAAt(0, 1) != blue {
+		t.Fatalf("rotation failed")
+	}
+	if flip(img, FlipDiagonal|FlipVertical).RGBAAt(0, 0) != blue {
+		t.Fatalf("counter clockwise rotation failed")
+	}
+}
@@ -0,0 +1,349 @@
+package tiled
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/fileutil"
+	"github.com/oakmound/oak/v4/oakerr"
+)
+
+type tmjMap struct {
+	Orientation string        `json:"orientation"`
+	Width       int           `json:"width"`
+	Height      int           `json:"height"`
+	TileWidth   int           `json:"tilewidth"`
+	TileHeight  int           `json:"tileheight"`
+	Infinite    bool          `json:"infinite"`
+	Properties  []tmjProperty `json:"properties"`
+	Tilesets    []tmjTileset  `json:"tilesets"`
+	Layers      []tmjLayer    `json:"layers"`
+}
+
+type tmjProperty struct {
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+}
+
+type tmjTileset struct {
+	FirstGID   uint32        `json:"firstgid"`
+	Source     string        `json:"source"`
+	Name       string        `json:"name"`
+	TileWidth  int           `json:"tilewidth"`
+	TileHeight int           `json:"tileheight"`
+	TileCount  int           `json:"tilecount"`
+	Columns    int           `json:"columns"`
+	Spacing    int           `json:"spacing"`
+	Margin     int           `json:"margin"`
+	Image      string        `json:"image"`
+	Properties []tmjProperty `json:"properties"`
+	Tiles      []struct {
+		ID         int           `json:"id"`
+		Type       string        `json:"type"`
+		Class      string        `json:"class"`
+		Image      string        `json:"image"`
+		Properties []tmjProperty `json:"properties"`
+		Animation  []struct {
+			TileID   int `json:"tileid"`
+			Duration int `json:"duration"`
+		} `json:"animation"`
+	} `json:"tiles"`
+}
+
+type tmjLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	OffsetX     float64         `json:"offsetx"`
+	OffsetY     float64         `json:"offsety"`
+	Opacity     *float64        `json:"opacity"`
+	Visible     *bool           `json:"visible"`
+	Properties  []tmjProperty   `json:"properties"`
+	Data        json.RawMessage `json:"data"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Objects     []tmjObject     `json:"objects"`
+	Image       string          `json:"image"`
+	Layers      []tmjLayer      `json:"layers"`
+}
+
+type tmjObject struct {
+	ID         int           `json:"id"`
+	Name       string        `json:"name"`
+	Type       string        `json:"type"`
+	Class      string        `json:"class"`
+	X          float64       `json:"x"`
+	Y          float64       `json:"y"`
+	Width      float64       `json:"width"`
+	Height     float64       `json:"height"`
+	Rotation   float64       `json:"rotation"`
+	GID        uint32        `json:"gid"`
+	Visible    *bool         `json:"visible"`
+	Ellipse    bool          `json:"ellipse"`
+	Point      bool          `json:"point"`
+	Polygon    []tmjPoint    `json:"polygon"`
+	Polyline   []tmjPoint    `json:"polyline"`
+	Properties []tmjProperty `json:"properties"`
+}
+
+type tmjPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+func tmjPoints(tps []tmjPoint) []floatgeom.Point2 {
+	if tps == nil {
+		return nil
+	}
+	pts := make([]floatgeom.Point2, len(tps))
+	for i, p := range tps {
+		pts[i] = floatgeom.Point2{p.X, p.Y}
+	}
+	return pts
+}
+
+// ParseTMJ reads a map in Tiled's JSON format. External tilesets and images
+// are loaded relative to dir.
+func ParseTMJ(r io.Reader, dir string, opts ...Option) (*Map, error) {
+	var tm tmjMap
+	if err := json.NewDecoder(r).Decode(&tm); err != nil {
+		return nil, err
+	}
+	m, err := newMap(tm.Orientation, tm.Infinite)
+	if err != nil {
+		return nil, err
+	}
+	m.Width, m.Height = tm.Width, tm.Height
+	m.TileWidth, m.TileHeight = tm.TileWidth, tm.TileHeight
+	if err := tmjProperties(m.Properties, "", tm.Properties); err != nil {
+		return nil, err
+	}
+	l := newLoader(opts)
+	for _, tts := range tm.Tilesets {
+		var ts *Tileset
+		if tts.Source != "" {
+			source := filepath.Join(dir, tts.Source)
+			if ext := strings.ToLower(filepath.Ext(source)); ext == ".tsx" || ext == ".xml" {
+				ts, err = l.tmxTileset(tmxTileset{FirstGID: tts.FirstGID, Source: tts.Source}, dir)
+			} else {
+				ts, err = l.loadTMJTileset(source, tts.FirstGID)
+			}
+		} else {
+			ts, err = l.tmjTileset(tts, dir)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+	fl := &flattener{m: m}
+	if err := fl.tmjLayers(l, tm.Layers, dir, layerState{opacity: 1, visible: true}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func tmjProperties(props Properties, prefix string, tps []tmjProperty) error {
+	for _, tp := range tps {
+		name := prefix + tp.Name
+		v := bytes.TrimSpace(tp.Value)
+		switch {
+		case len(v) > 0 && v[0] == '"':
+			var s string
+			if err := json.Unmarshal(v, &s); err != nil {
+				return err
+			}
+			props[name] = s
+		case len(v) > 0 && v[0] == '{':
+			// Class properties hold their members' values by name.
+			var members map[string]json.RawMessage
+			if err := json.Unmarshal(v, &members); err != nil {
+				return err
+			}
+			sub := make([]tmjProperty, 0, len(members))
+			for k, mv := range members {
+				sub = append(sub, tmjProperty{Name: k, Value: mv})
+			}
+			if err := tmjProperties(props, name+".", sub); err != nil {
+				return err
+			}
+		default:
+			props[name] = string(v)
+		}
+	}
+	return nil
+}
+
+func (l *loader) loadTMJTileset(source string, firstGID uint32) (*Tileset, error) {
+	f, err := fileutil.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var tts tmjTileset
+	if err := json.NewDecoder(f).Decode(&tts); err != nil {
+		return nil, err
+	}
+	tts.FirstGID = firstGID
+	return l.tmjTileset(tts, filepath.Dir(source))
+}
+
+func (l *loader) tmjTileset(tts tmjTileset, dir string) (*Tileset, error) {
+	ts := &Tileset{
+		FirstGID:   tts.FirstGID,
+		Name:       tts.Name,
+		TileWidth:  tts.TileWidth,
+		TileHeight: tts.TileHeight,
+		TileCount:  tts.TileCount,
+		Columns:    tts.Columns,
+		Properties: Properties{},
+		Tiles:      make(map[int]*Tile),
+	}
+	if err := tmjProperties(ts.Properties, "", tts.Properties); err != nil {
+		return nil, err
+	}
+	if tts.Image != "" {
+		if err := l.cutTileset(ts, dir, tts.Image, tts.Spacing, tts.Margin); err != nil {
+			return nil, err
+		}
+	}
+	for _, tt := range tts.Tiles {
+		t := &Tile{
+			ID:         tt.ID,
+			Type:       tt.Type,
+			Properties: Properties{},
+		}
+		if t.Type == "" {
+			t.Type = tt.Class
+		}
+		if err := tmjProperties(t.Properties, "", tt.Properties); err != nil {
+			return nil, err
+		}
+		for _, f := range tt.Animation {
+			t.Animation = append(t.Animation, Frame{
+				TileID:   f.TileID,
+				Duration: time.Duration(f.Duration) * time.Millisecond,
+			})
+		}
+		if tt.Image != "" {
+			var err error
+			if t.Image, err = l.loadImage(dir, tt.Image); err != nil {
+				return nil, err
+			}
+		}
+		ts.Tiles[t.ID] = t
+	}
+	return ts, nil
+}
+
+func (fl *flattener) tmjLayers(l *loader, tls []tmjLayer, dir string, st layerState) error {
+	for _, tl := range tls {
+		props := Properties{}
+		if err := tmjProperties(props, "", tl.Properties); err != nil {
+			return err
+		}
+		ls := layerState{
+			offset:  st.offset.Add(floatgeom.Point2{tl.OffsetX, tl.OffsetY}),
+			opacity: st.opacity * orDefault(tl.Opacity, 1),
+			visible: st.visible && (tl.Visible == nil || *tl.Visible),
+		}
+		switch tl.Type {
+		case "tilelayer":
+			layer := &TileLayer{
+				Name:       tl.Name,
+				Index:      fl.index,
+				Width:      tl.Width,
+				Height:     tl.Height,
+				Offset:     ls.offset,
+				Opacity:    ls.opacity,
+				Visible:    ls.visible,
+				Properties: props,
+			}
+			if tl.Encoding == "base64" {
+				var data string
+				if err := json.Unmarshal(tl.Data, &data); err != nil {
+					return err
+				}
+				var err error
+				if layer.GIDs, err = decodeGIDs(data, tl.Encoding, tl.Compression, tl.Width*tl.Height); err != nil {
+					return err
+				}
+			} else {
+				if err := json.Unmarshal(tl.Data, &layer.GIDs); err != nil {
+					return err
+				}
+				if len(layer.GIDs) != tl.Width*tl.Height {
+					return oakerr.InvalidInput{InputName: "data"}
+				}
+			}
+			fl.m.TileLayers = append(fl.m.TileLayers, layer)
+		case "objectgroup":
+			g := &ObjectGroup{
+				Name:       tl.Name,
+				Index:      fl.index,
+				Offset:     ls.offset,
+				Opacity:    ls.opacity,
+				Visible:    ls.visible,
+				Properties: props,
+			}
+			for _, to := range tl.Objects {
+				o := &Object{
+					ID:         to.ID,
+					Name:       to.Name,
+					Type:       to.Type,
+					X:          to.X,
+					Y:          to.Y,
+					Width:      to.Width,
+					Height:     to.Height,
+					Rotation:   to.Rotation,
+					Visible:    to.Visible == nil || *to.Visible,
+					Ellipse:    to.Ellipse,
+					Point:      to.Point,
+					Polygon:    tmjPoints(to.Polygon),
+					Polyline:   tmjPoints(to.Polyline),
+					GID:        to.GID,
+					Properties: Properties{},
+				}
+				if o.Type == "" {
+					o.Type = to.Class
+				}
+				if err := tmjProperties(o.Properties, "", to.Properties); err != nil {
+					return err
+				}
+				g.Objects = append(g.Objects, o)
+			}
+			fl.m.ObjectGroups = append(fl.m.ObjectGroups, g)
+		case "imagelayer":
+			il := &ImageLayer{
+				Name:       tl.Name,
+				Index:      fl.index,
+				Offset:     ls.offset,
+				Opacity:    ls.opacity,
+				Visible:    ls.visible,
+				Properties: props,
+			}
+			if tl.Image != "" {
+				var err error
+				if il.Image, err = l.loadImage(dir, tl.Image); err != nil {
+					return err
+				}
+			}
+			fl.m.ImageLayers = append(fl.m.ImageLayers, il)
+		case "group":
+			if err := fl.tmjLayers(l, tl.Layers, dir, ls); err != nil {
+				return err
+			}
+			continue
+		default:
+			continue
+		}
+		fl.index++
+	}
+	return nil
+}
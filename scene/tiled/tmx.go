@@ -0,0 +1,348 @@
+package tiled
+
+import (
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/fileutil"
+	"github.com/oakmound/oak/v4/oakerr"
+)
+
+type tmxMap struct {
+	Orientation string        `xml:"orientation,attr"`
+	Width       int           `xml:"width,attr"`
+	Height      int           `xml:"height,attr"`
+	TileWidth   int           `xml:"tilewidth,attr"`
+	TileHeight  int           `xml:"tileheight,attr"`
+	Infinite    bool          `xml:"infinite,attr"`
+	Properties  []tmxProperty `xml:"properties>property"`
+	Tilesets    []tmxTileset  `xml:"tileset"`
+	// Layers of every kind are read together to keep their order.
+	Layers []tmxLayer `xml:",any"`
+}
+
+type tmxProperty struct {
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Value      *string       `xml:"value,attr"`
+	Text       string        `xml:",chardata"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxTileset struct {
+	FirstGID   uint32        `xml:"firstgid,attr"`
+	Source     string        `xml:"source,attr"`
+	Name       string        `xml:"name,attr"`
+	TileWidth  int           `xml:"tilewidth,attr"`
+	TileHeight int           `xml:"tileheight,attr"`
+	TileCount  int           `xml:"tilecount,attr"`
+	Columns    int           `xml:"columns,attr"`
+	Spacing    int           `xml:"spacing,attr"`
+	Margin     int           `xml:"margin,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Image      *tmxImage     `xml:"image"`
+	Tiles      []tmxTile     `xml:"tile"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+}
+
+type tmxTile struct {
+	ID         int           `xml:"id,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Image      *tmxImage     `xml:"image"`
+	Animation  []struct {
+		TileID   int `xml:"tileid,attr"`
+		Duration int `xml:"duration,attr"`
+	} `xml:"animation>frame"`
+}
+
+type tmxLayer struct {
+	XMLName    xml.Name
+	Name       string        `xml:"name,attr"`
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	OffsetX    float64       `xml:"offsetx,attr"`
+	OffsetY    float64       `xml:"offsety,attr"`
+	Opacity    *float64      `xml:"opacity,attr"`
+	Visible    *bool         `xml:"visible,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Data       *struct {
+		Encoding    string `xml:"encoding,attr"`
+		Compression string `xml:"compression,attr"`
+		Text        string `xml:",chardata"`
+		Tiles       []struct {
+			GID uint32 `xml:"gid,attr"`
+		} `xml:"tile"`
+	} `xml:"data"`
+	Objects []tmxObject `xml:"object"`
+	Image   *tmxImage   `xml:"image"`
+	Layers  []tmxLayer  `xml:",any"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	Rotation   float64       `xml:"rotation,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Visible    *bool         `xml:"visible,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Ellipse    *struct{}     `xml:"ellipse"`
+	Point      *struct{}     `xml:"point"`
+	Polygon    *tmxPoints    `xml:"polygon"`
+	Polyline   *tmxPoints    `xml:"polyline"`
+}
+
+type tmxPoints struct {
+	Points string `xml:"points,attr"`
+}
+
+// ParseTMX reads a map in Tiled's XML format. External tilesets and images
+// are loaded relative to dir.
+func ParseTMX(r io.Reader, dir string, opts ...Option) (*Map, error) {
+	var tm tmxMap
+	if err := xml.NewDecoder(r).Decode(&tm); err != nil {
+		return nil, err
+	}
+	m, err := newMap(tm.Orientation, tm.Infinite)
+	if err != nil {
+		return nil, err
+	}
+	m.Width, m.Height = tm.Width, tm.Height
+	m.TileWidth, m.TileHeight = tm.TileWidth, tm.TileHeight
+	tmxProperties(m.Properties, "", tm.Properties)
+	l := newLoader(opts)
+	for _, tts := range tm.Tilesets {
+		ts, err := l.tmxTileset(tts, dir)
+		if err != nil {
+			return nil, err
+		}
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+	fl := &flattener{m: m}
+	if err := fl.tmxLayers(l, tm.Layers, dir, layerState{opacity: 1, visible: true}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func tmxProperties(props Properties, prefix string, tps []tmxProperty) {
+	for _, tp := range tps {
+		name := prefix + tp.Name
+		if tp.Type == "class" {
+			tmxProperties(props, name+".", tp.Properties)
+			continue
+		}
+		if tp.Value != nil {
+			props[name] = *tp.Value
+		} else {
+			props[name] = tp.Text
+		}
+	}
+}
+
+func (l *loader) tmxTileset(tts tmxTileset, dir string) (*Tileset, error) {
+	firstGID := tts.FirstGID
+	if tts.Source != "" {
+		source := filepath.Join(dir, tts.Source)
+		if ext := strings.ToLower(filepath.Ext(source)); ext == ".tsj" || ext == ".json" {
+			return l.loadTMJTileset(source, firstGID)
+		}
+		f, err := fileutil.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		tts = tmxTileset{}
+		if err := xml.NewDecoder(f).Decode(&tts); err != nil {
+			return nil, err
+		}
+		dir = filepath.Dir(source)
+	}
+	ts := &Tileset{
+		FirstGID:   firstGID,
+		Name:       tts.Name,
+		TileWidth:  tts.TileWidth,
+		TileHeight: tts.TileHeight,
+		TileCount:  tts.TileCount,
+		Columns:    tts.Columns,
+		Properties: Properties{},
+		Tiles:      make(map[int]*Tile),
+	}
+	tmxProperties(ts.Properties, "", tts.Properties)
+	if tts.Image != nil {
+		if err := l.cutTileset(ts, dir, tts.Image.Source, tts.Spacing, tts.Margin); err != nil {
+			return nil, err
+		}
+	}
+	for _, tt := range tts.Tiles {
+		t := &Tile{
+			ID:         tt.ID,
+			Type:       tt.Type,
+			Properties: Properties{},
+		}
+		if t.Type == "" {
+			t.Type = tt.Class
+		}
+		tmxProperties(t.Properties, "", tt.Properties)
+		for _, f := range tt.Animation {
+			t.Animation = append(t.Animation, Frame{
+				TileID:   f.TileID,
+				Duration: time.Duration(f.Duration) * time.Millisecond,
+			})
+		}
+		if tt.Image != nil {
+			var err error
+			if t.Image, err = l.loadImage(dir, tt.Image.Source); err != nil {
+				return nil, err
+			}
+		}
+		ts.Tiles[t.ID] = t
+	}
+	return ts, nil
+}
+
+// layerState is what layers inherit from the groups they are in.
+type layerState struct {
+	offset  floatgeom.Point2
+	opacity float64
+	visible bool
+}
+
+// A flattener collects a map's layers out of their groups, in order.
+type flattener struct {
+	m     *Map
+	index int
+}
+
+func (fl *flattener) tmxLayers(l *loader, tls []tmxLayer, dir string, st layerState) error {
+	for _, tl := range tls {
+		props := Properties{}
+		tmxProperties(props, "", tl.Properties)
+		ls := layerState{
+			offset:  st.offset.Add(floatgeom.Point2{tl.OffsetX, tl.OffsetY}),
+			opacity: st.opacity * orDefault(tl.Opacity, 1),
+			visible: st.visible && (tl.Visible == nil || *tl.Visible),
+		}
+		switch tl.XMLName.Local {
+		case "layer":
+			if tl.Data == nil {
+				return oakerr.InvalidInput{InputName: "data"}
+			}
+			layer := &TileLayer{
+				Name:       tl.Name,
+				Index:      fl.index,
+				Width:      tl.Width,
+				Height:     tl.Height,
+				Offset:     ls.offset,
+				Opacity:    ls.opacity,
+				Visible:    ls.visible,
+				Properties: props,
+			}
+			if tl.Data.Encoding == "" {
+				for _, t := range tl.Data.Tiles {
+					layer.GIDs = append(layer.GIDs, t.GID)
+				}
+				if len(layer.GIDs) != tl.Width*tl.Height {
+					return oakerr.InvalidInput{InputName: "data"}
+				}
+			} else {
+				var err error
+				layer.GIDs, err = decodeGIDs(tl.Data.Text, tl.Data.Encoding, tl.Data.Compression, tl.Width*tl.Height)
+				if err != nil {
+					return err
+				}
+			}
+			fl.m.TileLayers = append(fl.m.TileLayers, layer)
+		case "objectgroup":
+			g := &ObjectGroup{
+				Name:       tl.Name,
+				Index:      fl.index,
+				Offset:     ls.offset,
+				Opacity:    ls.opacity,
+				Visible:    ls.visible,
+				Properties: props,
+			}
+			for _, to := range tl.Objects {
+				o, err := tmxObjectOf(to)
+				if err != nil {
+					return err
+				}
+				g.Objects = append(g.Objects, o)
+			}
+			fl.m.ObjectGroups = append(fl.m.ObjectGroups, g)
+		case "imagelayer":
+			il := &ImageLayer{
+				Name:       tl.Name,
+				Index:      fl.index,
+				Offset:     ls.offset,
+				Opacity:    ls.opacity,
+				Visible:    ls.visible,
+				Properties: props,
+			}
+			if tl.Image != nil && tl.Image.Source != "" {
+				var err error
+				if il.Image, err = l.loadImage(dir, tl.Image.Source); err != nil {
+					return err
+				}
+			}
+			fl.m.ImageLayers = append(fl.m.ImageLayers, il)
+		case "group":
+			if err := fl.tmxLayers(l, tl.Layers, dir, ls); err != nil {
+				return err
+			}
+			continue
+		default:
+			continue
+		}
+		fl.index++
+	}
+	return nil
+}
+
+func tmxObjectOf(to tmxObject) (*Object, error) {
+	o := &Object{
+		ID:         to.ID,
+		Name:       to.Name,
+		Type:       to.Type,
+		X:          to.X,
+		Y:          to.Y,
+		Width:      to.Width,
+		Height:     to.Height,
+		Rotation:   to.Rotation,
+		Visible:    to.Visible == nil || *to.Visible,
+		Ellipse:    to.Ellipse != nil,
+		Point:      to.Point != nil,
+		GID:        to.GID,
+		Properties: Properties{},
+	}
+	if o.Type == "" {
+		o.Type = to.Class
+	}
+	tmxProperties(o.Properties, "", to.Properties)
+	var err error
+	if to.Polygon != nil {
+		if o.Polygon, err = parsePoints(to.Polygon.Points); err != nil {
+			return nil, err
+		}
+	}
+	if to.Polyline != nil {
+		if o.Polyline, err = parsePoints(to.Polyline.Points); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
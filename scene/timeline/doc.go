@@ -0,0 +1,3 @@
+// Package timeline provides keyframed timelines, for cutscenes and other
+// scripted sequences, which can be authored in Go or loaded from JSON.
+package timeline
@@ -0,0 +1,152 @@
+package timeline
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/fileutil"
+	"github.com/oakmound/oak/v4/oakerr"
+)
+
+// Load reads a timeline from a JSON file. See Parse.
+func Load(file string) (*Timeline, error) {
+	f, err := fileutil.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a timeline from JSON. Times are given in seconds, and each track
+// has a type naming which kind of track it is:
+//
+//	{
+//		"name": "intro",
+//		"tracks": [
+//			{"type": "position", "entity": "hero", "keys": [
+//				{"at": 0, "value": [0, 100]},
+//				{"at": 2, "value": [200, 100], "ease": "inout"}
+//			]},
+//			{"type": "viewport", "keys": [{"at": 0, "value": [0, 0]}]},
+//			{"type": "visible", "renderable": "logo", "layers": [1], "keys": [{"at": 1, "value": true}]},
+//			{"type": "alpha", "renderable": "logo", "keys": [{"at": 1, "value": 0}, {"at": 2, "value": 1}]},
+//			{"type": "audio", "cues": [{"at": 1, "file": "boom.wav"}]},
+//			{"type": "event", "cues": [{"at": 3, "name": "open-door", "onSkip": true}]},
+//			{"type": "text", "font": "dialog", "layers": [2], "cues": [
+//				{"at": 0, "duration": 2, "text": "Hello!", "position": [10, 10]}
+//			]}
+//		]
+//	}
+func Parse(r io.Reader) (*Timeline, error) {
+	var jtl jsonTimeline
+	if err := json.NewDecoder(r).Decode(&jtl); err != nil {
+		return nil, err
+	}
+	tl := &Timeline{
+		Name:   jtl.Name,
+		Tracks: make([]Track, len(jtl.Tracks)),
+	}
+	for i, jt := range jtl.Tracks {
+		var err error
+		switch jt.Type {
+		case "position":
+			tr := &PositionTrack{Entity: jt.Entity}
+			tr.Keys, err = parseKeys[floatgeom.Point2](jt.Keys)
+			tl.Tracks[i] = tr
+		case "viewport":
+			tr := &ViewportTrack{}
+			tr.Keys, err = parseKeys[floatgeom.Point2](jt.Keys)
+			tl.Tracks[i] = tr
+		case "visible":
+			tr := &VisibleTrack{Renderable: jt.Renderable, Layers: jt.Layers}
+			tr.Keys, err = parseKeys[bool](jt.Keys)
+			tl.Tracks[i] = tr
+		case "alpha":
+			tr := &AlphaTrack{Renderable: jt.Renderable}
+			tr.Keys, err = parseKeys[float64](jt.Keys)
+			tl.Tracks[i] = tr
+		case "audio":
+			tr := &AudioTrack{}
+			for _, c := range jt.Cues {
+				tr.Cues = append(tr.Cues, AudioCue{At: seconds(c.At), File: c.File})
+			}
+			tl.Tracks[i] = tr
+		case "event":
+			tr := &EventTrack{}
+			for _, c := range jt.Cues {
+				tr.Cues = append(tr.Cues, EventCue{At: seconds(c.At), Name: c.Name, OnSkip: c.OnSkip})
+			}
+			tl.Tracks[i] = tr
+		case "text":
+			tr := &TextTrack{Font: jt.Font, Layers: jt.Layers}
+			for _, c := range jt.Cues {
+				tr.Cues = append(tr.Cues, TextCue{
+					At:       seconds(c.At),
+					Duration: seconds(c.Duration),
+					Text:     c.Text,
+					Position: c.Position,
+				})
+			}
+			tl.Tracks[i] = tr
+		default:
+			return nil, oakerr.UnsupportedFormat{Format: jt.Type}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tl, nil
+}
+
+type jsonTimeline struct {
+	Name   string      `json:"name"`
+	Tracks []jsonTrack `json:"tracks"`
+}
+
+type jsonTrack struct {
+	Type       string    `json:"type"`
+	Entity     string    `json:"entity"`
+	Renderable string    `json:"renderable"`
+	Font       string    `json:"font"`
+	Layers     []int     `json:"layers"`
+	Keys       []jsonKey `json:"keys"`
+	Cues       []jsonCue `json:"cues"`
+}
+
+type jsonKey struct {
+	At    float64         `json:"at"`
+	Value json.RawMessage `json:"value"`
+	Ease  Ease            `json:"ease"`
+}
+
+type jsonCue struct {
+	At       float64          `json:"at"`
+	Duration float64          `json:"duration"`
+	File     string           `json:"file"`
+	Name     string           `json:"name"`
+	OnSkip   bool             `json:"onSkip"`
+	Text     string           `json:"text"`
+	Position floatgeom.Point2 `json:"position"`
+}
+
+func parseKeys[T any](jks []jsonKey) ([]Keyframe[T], error) {
+	keys := make([]Keyframe[T], len(jks))
+	for i, jk := range jks {
+		if !jk.Ease.valid() {
+			return nil, oakerr.InvalidInput{InputName: "ease:" + string(jk.Ease)}
+		}
+		keys[i].At = seconds(jk.At)
+		keys[i].Ease = jk.Ease
+		if err := json.Unmarshal(jk.Value, &keys[i].Value); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,121 @@
+package timeline
+
+import (
+	"sort"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/oakerr"
+)
+
+// An Ease controls how a value moves from one keyframe to the next.
+type Ease string
+
+// Ease values. The zero Ease is Linear.
+const (
+	Linear Ease = "linear"
+	// Step holds a keyframe's value until the next keyframe is reached.
+	Step  Ease = "step"
+	In    Ease = "in"
+	Out   Ease = "out"
+	InOut Ease = "inout"
+)
+
+func (e Ease) valid() bool {
+	switch e {
+	case "", Linear, Step, In, Out, InOut:
+		return true
+	}
+	return false
+}
+
+// apply maps linear progress from 0 to 1 onto this ease.
+func (e Ease) apply(p float64) float64 {
+	switch e {
+	case Step:
+		return 0
+	case In:
+		return p * p
+	case Out:
+		return p * (2 - p)
+	case InOut:
+		if p < .5 {
+			return 2 * p * p
+		}
+		return -1 + (4-2*p)*p
+	}
+	return p
+}
+
+// A Keyframe is the value a track should have at some point in a timeline.
+type Keyframe[T any] struct {
+	At    time.Duration
+	Value T
+	// Ease controls how the value moves from this keyframe to the next.
+	Ease Ease
+}
+
+// sortKeys returns a copy of keys sorted by time, or an error if keys is empty or
+// uses an unknown ease.
+func sortKeys[T any](keys []Keyframe[T]) ([]Keyframe[T], error) {
+	if len(keys) == 0 {
+		return nil, oakerr.InsufficientInputs{AtLeast: 1, InputName: "Keys"}
+	}
+	sorted := make([]Keyframe[T], len(keys))
+	copy(sorted, keys)
+	for _, k := range sorted {
+		if !k.Ease.valid() {
+			return nil, oakerr.InvalidInput{InputName: "Ease:" + string(k.Ease)}
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].At < sorted[j].At
+	})
+	return sorted, nil
+}
+
+func keysDuration[T any](keys []Keyframe[T]) time.Duration {
+	var d time.Duration
+	for _, k := range keys {
+		if k.At > d {
+			d = k.At
+		}
+	}
+	return d
+}
+
+// sample returns the value of sorted keys at time t.
+func sample[T any](keys []Keyframe[T], t time.Duration, lerp func(a, b T, p float64) T) T {
+	if t <= keys[0].At {
+		return keys[0].Value
+	}
+	for i := 1; i < len(keys); i++ {
+		if t < keys[i].At {
+			k0 := keys[i-1]
+			p := float64(t-k0.At) / float64(keys[i].At-k0.At)
+			return lerp(k0.Value, keys[i].Value, k0.Ease.apply(p))
+		}
+	}
+	return keys[len(keys)-1].Value
+}
+
+func lerpFloat(a, b float64, p float64) float64 {
+	return a + (b-a)*p
+}
+
+func lerpPoint(a, b floatgeom.Point2, p float64) floatgeom.Point2 {
+	return a.Add(b.Sub(a).MulConst(p))
+}
+
+func lerpBool(a, b bool, p float64) bool {
+	if p >= 1 {
+		return b
+	}
+	return a
+}
+
+// reached returns whether a cue at the given time is passed when moving from one
+// time to another.
+func reached(at, from, to time.Duration) bool {
+	return at > from && at <= to
+}
@@ -0,0 +1,235 @@
+package timeline
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/oakmound/oak/v4/entities"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/render"
+	"github.com/oakmound/oak/v4/scene"
+)
+
+// A Timeline is a set of tracks played together.
+type Timeline struct {
+	Name   string
+	Tracks []Track
+}
+
+// A Track describes how something changes over a timeline. Tracks refer to what
+// they change by name, and are bound to a Cast when their timeline is played.
+type Track interface {
+	// Duration is the time of the last change this track makes.
+	Duration() time.Duration
+	// Bind resolves the names this track refers to in a cast.
+	Bind(ctx *scene.Context, cast Cast) (Applier, error)
+}
+
+// An Applier applies a bound track as its timeline moves from one time to
+// another. Seeking is true when the timeline is being seeked or skipped rather
+// than played, in which case cues are generally not fired.
+type Applier interface {
+	Apply(from, to time.Duration, seeking bool)
+}
+
+// ApplierFunc is a function satisfying Applier.
+type ApplierFunc func(from, to time.Duration, seeking bool)
+
+// Apply calls af.
+func (af ApplierFunc) Apply(from, to time.Duration, seeking bool) {
+	af(from, to, seeking)
+}
+
+// A Cast holds what a timeline's tracks refer to by name.
+type Cast struct {
+	Entities    map[string]*entities.Entity
+	Renderables map[string]render.Renderable
+	Fonts       map[string]*render.Font
+	// Triggers are called by event tracks. Event cues without a trigger in the
+	// cast trigger Cued instead.
+	Triggers map[string]func(event.Handler)
+}
+
+// Trigger returns a function triggering an event with a payload, for use in a
+// Cast's Triggers.
+func Trigger[T any](ev event.EventID[T], payload T) func(event.Handler) {
+	return func(h event.Handler) {
+		event.TriggerOn(h, ev, payload)
+	}
+}
+
+var (
+	// Cued: when an event track reaches a cue not found in its cast's Triggers.
+	// Triggered globally with the cue's name.
+	Cued = event.RegisterEvent[string]()
+	// Finished: when a Player reaches the end of its timeline. Triggered globally.
+	Finished = event.RegisterEvent[*Player]()
+)
+
+// Duration returns the duration of this timeline's longest track.
+func (tl *Timeline) Duration() time.Duration {
+	var d time.Duration
+	for _, tr := range tl.Tracks {
+		if td := tr.Duration(); td > d {
+			d = td
+		}
+	}
+	return d
+}
+
+// Play binds this timeline's tracks to a cast and starts playing them, advancing
+// by the time elapsed each frame.
+func (tl *Timeline) Play(ctx *scene.Context, cast Cast) (*Player, error) {
+	p := &Player{
+		Timeline: tl,
+		duration: tl.Duration(),
+		done:     make(chan struct{}),
+	}
+	// Tracks are bound to a context that ends when the player is stopped, so
+	// anything they start, like audio, ends with it.
+	playCtx := *ctx
+	playCtx.Context, p.cancel = context.WithCancel(ctx)
+	p.ctx = &playCtx
+	for _, tr := range tl.Tracks {
+		a, err := tr.Bind(p.ctx, cast)
+		if err != nil {
+			p.cancel()
+			return nil, err
+		}
+		p.appliers = append(p.appliers, a)
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	// Start just before zero, so cues at zero are fired
+	p.t = -1
+	p.advance(1, false)
+	if p.finished {
+		return p, nil
+	}
+	p.binding = event.GlobalBind(ctx, event.Enter, func(ev event.EnterPayload) event.Response {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		if p.finished {
+			return event.ResponseUnbindThisBinding
+		}
+		if !p.paused {
+			p.advance(ev.SinceLastFrame, false)
+		}
+		return 0
+	})
+	return p, nil
+}
+
+// A Player plays a Timeline.
+type Player struct {
+	Timeline *Timeline
+
+	ctx      *scene.Context
+	cancel   context.CancelFunc
+	appliers []Applier
+	duration time.Duration
+	binding  event.Binding
+
+	lock     sync.Mutex
+	t        time.Duration
+	paused   bool
+	finished bool
+	done     chan struct{}
+}
+
+// Pause stops this player from advancing until it is resumed.
+func (p *Player) Pause() {
+	p.lock.Lock()
+	p.paused = true
+	p.lock.Unlock()
+}
+
+// Resume continues advancing a paused player.
+func (p *Player) Resume() {
+	p.lock.Lock()
+	p.paused = false
+	p.lock.Unlock()
+}
+
+// Paused returns whether this player is paused.
+func (p *Player) Paused() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.paused
+}
+
+// Time returns how far this player is through its timeline.
+func (p *Player) Time() time.Duration {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.t
+}
+
+// Seek moves this player to a time in its timeline, applying every track at
+// that time without firing cues along the way. Seeking to the end finishes the
+// player.
+func (p *Player) Seek(t time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.finished {
+		return
+	}
+	if t < 0 {
+		t = 0
+	}
+	p.advance(t-p.t, true)
+}
+
+// Skip seeks to the end of this player's timeline, finishing it.
+func (p *Player) Skip() {
+	p.Seek(p.duration)
+}
+
+// Stop ends this player without finishing its timeline. Tracks are left as
+// they were, except for text boxes, which are removed, and audio, which stops.
+func (p *Player) Stop() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.finished {
+		return
+	}
+	p.finished = true
+	p.binding.Unbind()
+	p.close()
+	p.cancel()
+	close(p.done)
+}
+
+// Done returns a channel which is closed once this player finishes or is stopped.
+func (p *Player) Done() <-chan struct{} {
+	return p.done
+}
+
+// advance moves this player by dt, applying its tracks, and finishes it if its
+// end is reached.
+func (p *Player) advance(dt time.Duration, seeking bool) {
+	from := p.t
+	p.t += dt
+	if p.t > p.duration {
+		p.t = p.duration
+	}
+	for _, a := range p.appliers {
+		a.Apply(from, p.t, seeking)
+	}
+	if p.t == p.duration {
+		p.finished = true
+		p.close()
+		close(p.done)
+		event.TriggerOn(p.ctx, Finished, p)
+	}
+}
+
+// close cleans up any tracks which need it.
+func (p *Player) close() {
+	for _, a := range p.appliers {
+		if c, ok := a.(interface{ Close() }); ok {
+			c.Close()
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package timeline
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/entities"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/render"
+	"github.com/oakmound/oak/v4/scene"
+)
+
+func expectCue(t *testing.T, cues chan string, name string) {
+	t.Helper()
+	select {
+	case cue := <-cues:
+		if cue != name {
+			t.Fatalf("expected cue %v, got %v", name, cue)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected cue %v", name)
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	tl, err := Load("testdata/intro.json")
+	if err != nil {
+		t.Fatalf("failed to load timeline: %v", err)
+	}
+	if tl.Name != "intro" || tl.Duration() != 3*time.Second {
+		t.Fatalf("unexpected timeline %v of duration %v", tl.Name, tl.Duration())
+	}
+
+	cm := event.NewCallerMap()
+	ctx := &scene.Context{
+		Context:   context.Background(),
+		CallerMap: cm,
+		Handler:   event.NewBus(cm),
+		DrawStack: render.NewDrawStack(render.NewDynamicHeap()),
+	}
+	hero := entities.New(ctx,
+		entities.WithRect(floatgeom.NewRect2WH(5, 5, 2, 2)),
+		entities.WithColor(color.RGBA{255, 0, 0, 255}),
+		entities.WithWithoutCollision(true),
+	)
+	logo := render.NewReverting(render.NewColorBox(2, 2, color.RGBA{0, 0, 255, 255}))
+	cues := make(chan string, 4)
+	event.GlobalBind(ctx, Cued, func(name string) event.Response {
+		cues <- name
+		return 0
+	})
+	// Let bindings settle
+	time.Sleep(50 * time.Millisecond)
+
+	p, err := tl.Play(ctx, Cast{
+		Entities:    map[string]*entities.Entity{"hero": hero},
+		Renderables: map[string]render.Renderable{"logo": logo},
+		Triggers: map[string]func(event.Handler){
+			"start": func(event.Handler) {
+				cues <- "triggered start"
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to play timeline: %v", err)
+	}
+	expectCue(t, cues, "triggered start")
+	if hero.Rect.Min != (floatgeom.Point2{0, 0}) {
+		t.Fatalf("expected hero at start position, got %v", hero.Rect.Min)
+	}
+	if logo.GetLayer() != render.Undraw {
+		t.Fatalf("expected logo to start hidden")
+	}
+
+	p.Seek(time.Second)
+	text := p.appliers[4].(*textApplier)
+	if hero.Rect.Min != (floatgeom.Point2{10, 5}) {
+		t.Fatalf("expected hero halfway, got %v", hero.Rect.Min)
+	}
+	if logo.GetLayer() == render.Undraw || !text.shown[0] {
+		t.Fatalf("expected logo and text to be shown")
+	}
+	if _, _, _, a := logo.GetRGBA().At(0, 0).RGBA(); a != 0 {
+		t.Fatalf("expected logo to be transparent, got alpha %v", a)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	p.Pause()
+	<-event.TriggerOn(ctx, event.Enter, event.EnterPayload{SinceLastFrame: time.Second})
+	if p.Time() != time.Second {
+		t.Fatalf("expected paused timeline not to advance, got %v", p.Time())
+	}
+	p.Resume()
+	<-event.TriggerOn(ctx, event.Enter, event.EnterPayload{SinceLastFrame: time.Second})
+	expectCue(t, cues, "door")
+	if p.Time() != 2*time.Second || text.shown[0] {
+		t.Fatalf("expected timeline at 2s with text hidden, got %v", p.Time())
+	}
+
+	p.Skip()
+	expectCue(t, cues, "end")
+	select {
+	case <-p.Done():
+	default:
+		t.Fatalf("expected skipped timeline to be done")
+	}
+	if hero.Rect.Min != (floatgeom.Point2{20, 10}) {
+		t.Fatalf("expected hero at end position, got %v", hero.Rect.Min)
+	}
+	if _, _, _, a := logo.GetRGBA().At(0, 0).RGBA(); a != 0xffff {
+		t.Fatalf("expected logo to be opaque, got alpha %v", a)
+	}
+}
+
+func TestPlayErrors(t *testing.T) {
+	ctx := &scene.Context{Context: context.Background()}
+	for name, tr := range map[string]Track{
+		"missing entity":     &PositionTrack{Entity: "x", Keys: []Keyframe[floatgeom.Point2]{{}}},
+		"no keys":            &VisibleTrack{Renderable: "r"},
+		"not reverting":      &AlphaTrack{Renderable: "r", Keys: []Keyframe[float64]{{}}},
+		"unknown ease":       &VisibleTrack{Renderable: "r", Keys: []Keyframe[bool]{{Ease: "bounce"}}},
+		"missing font":       &TextTrack{Font: "f"},
+		"viewport no window": &ViewportTrack{Keys: []Keyframe[floatgeom.Point2]{{}}},
+	} {
+		tl := &Timeline{Tracks: []Track{tr}}
+		_, err := tl.Play(ctx, Cast{
+			Renderables: map[string]render.Renderable{"r": render.NewColorBox(1, 1, color.RGBA{})},
+		})
+		if err == nil {
+			t.Fatalf("%v: expected error playing timeline", name)
+		}
+	}
+}
+
+func TestScaleAlpha(t *testing.T) {
+	page := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range page.Pix {
+		page.Pix[i] = 200
+	}
+	// Scaling part of an image leaves the rest of it alone.
+	scaleAlpha(.5)(page.SubImage(image.Rect(1, 1, 3, 3)).(*image.RGBA))
+	if page.RGBAAt(1, 1) != (color.RGBA{100, 100, 100, 100}) || page.RGBAAt(2, 2) != (color.RGBA{100, 100, 100, 100}) {
+		t.Fatalf("expected scaled pixels, got %v", page.RGBAAt(1, 1))
+	}
+	for _, p := range []image.Point{{0, 1}, {3, 1}, {3, 2}, {0, 3}} {
+		if page.RGBAAt(p.X, p.Y) != (color.RGBA{200, 200, 200, 200}) {
+			t.Fatalf("pixel %v outside the image should not be scaled", p)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, js := range []string{
+		`{`,
+		`{"tracks": [{"type": "unknown"}]}`,
+		`{"tracks": [{"type": "position", "keys": [{"value": true}]}]}`,
+		`{"tracks": [{"type": "alpha", "keys": [{"value": 1, "ease": "bounce"}]}]}`,
+	} {
+		if _, err := Parse(strings.NewReader(js)); err == nil {
+			t.Fatalf("expected error parsing %v", js)
+		}
+	}
+}
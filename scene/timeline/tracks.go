@@ -0,0 +1,364 @@
+package timeline
+
+import (
+	"image"
+	"time"
+
+	"github.com/oakmound/oak/v4/alg/floatgeom"
+	"github.com/oakmound/oak/v4/alg/intgeom"
+	"github.com/oakmound/oak/v4/audio"
+	"github.com/oakmound/oak/v4/dlog"
+	"github.com/oakmound/oak/v4/event"
+	"github.com/oakmound/oak/v4/oakerr"
+	"github.com/oakmound/oak/v4/render"
+	"github.com/oakmound/oak/v4/render/mod"
+	"github.com/oakmound/oak/v4/scene"
+)
+
+var (
+	_ Track = &PositionTrack{}
+	_ Track = &ViewportTrack{}
+	_ Track = &VisibleTrack{}
+	_ Track = &AlphaTrack{}
+	_ Track = &AudioTrack{}
+	_ Track = &EventTrack{}
+	_ Track = &TextTrack{}
+)
+
+// A PositionTrack moves an entity.
+type PositionTrack struct {
+	Entity string
+	Keys   []Keyframe[floatgeom.Point2]
+}
+
+// Duration satisfies Track.
+func (pt *PositionTrack) Duration() time.Duration {
+	return keysDuration(pt.Keys)
+}
+
+// Bind satisfies Track.
+func (pt *PositionTrack) Bind(ctx *scene.Context, cast Cast) (Applier, error) {
+	e, ok := cast.Entities[pt.Entity]
+	if !ok {
+		return nil, oakerr.NotFound{InputName: "Entity:" + pt.Entity}
+	}
+	keys, err := sortKeys(pt.Keys)
+	if err != nil {
+		return nil, err
+	}
+	return ApplierFunc(func(_, to time.Duration, _ bool) {
+		e.SetPos(sample(keys, to, lerpPoint))
+	}), nil
+}
+
+// A ViewportTrack moves the viewport of a scene's window.
+type ViewportTrack struct {
+	Keys []Keyframe[floatgeom.Point2]
+}
+
+// Duration satisfies Track.
+func (vt *ViewportTrack) Duration() time.Duration {
+	return keysDuration(vt.Keys)
+}
+
+// Bind satisfies Track.
+func (vt *ViewportTrack) Bind(ctx *scene.Context, cast Cast) (Applier, error) {
+	if ctx.Window == nil {
+		return nil, oakerr.NilInput{InputName: "ctx.Window"}
+	}
+	keys, err := sortKeys(vt.Keys)
+	if err != nil {
+		return nil, err
+	}
+	return ApplierFunc(func(_, to time.Duration, _ bool) {
+		pt := sample(keys, to, lerpPoint)
+		ctx.Window.SetViewport(intgeom.Point2{int(pt.X()), int(pt.Y())})
+	}), nil
+}
+
+// A VisibleTrack draws and undraws a renderable.
+type VisibleTrack struct {
+	Renderable string
+	// Layers are the layers the renderable is drawn to when shown. If empty, it
+	// is drawn to layer 0.
+	Layers []int
+	Keys   []Keyframe[bool]
+}
+
+// Duration satisfies Track.
+func (vt *VisibleTrack) Duration() time.Duration {
+	return keysDuration(vt.Keys)
+}
+
+// Bind satisfies Track.
+func (vt *VisibleTrack) Bind(ctx *scene.Context, cast Cast) (Applier, error) {
+	r, ok := cast.Renderables[vt.Renderable]
+	if !ok {
+		return nil, oakerr.NotFound{InputName: "Renderable:" + vt.Renderable}
+	}
+	keys, err := sortKeys(vt.Keys)
+	if err != nil {
+		return nil, err
+	}
+	layers := vt.Layers
+	if len(layers) == 0 {
+		layers = []int{0}
+	}
+	visible := r.GetLayer() != render.Undraw
+	return ApplierFunc(func(_, to time.Duration, _ bool) {
+		v := sample(keys, to, lerpBool)
+		if v == visible {
+			return
+		}
+		visible = v
+		if v {
+			ctx.DrawStack.Draw(r, layers...)
+		} else {
+			r.Undraw()
+		}
+	}), nil
+}
+
+// An AlphaTrack fades a renderable in and out. Its renderable must be a
+// *render.Reverting, and alpha values range from 0, transparent, to 1, opaque.
+type AlphaTrack struct {
+	Renderable string
+	Keys       []Keyframe[float64]
+}
+
+// Duration satisfies Track.
+func (at *AlphaTrack) Duration() time.Duration {
+	return keysDuration(at.Keys)
+}
+
+// Bind satisfies Track.
+func (at *AlphaTrack) Bind(ctx *scene.Context, cast Cast) (Applier, error) {
+	r, ok := cast.Renderables[at.Renderable]
+	if !ok {
+		return nil, oakerr.NotFound{InputName: "Renderable:" + at.Renderable}
+	}
+	rv, ok := r.(*render.Reverting)
+	if !ok {
+		return nil, oakerr.InvalidInput{InputName: "Renderable:" + at.Renderable}
+	}
+	keys, err := sortKeys(at.Keys)
+	if err != nil {
+		return nil, err
+	}
+	applied := false
+	last := 1.0
+	return ApplierFunc(func(_, to time.Duration, _ bool) {
+		alpha := sample(keys, to, lerpFloat)
+		if alpha < 0 {
+			alpha = 0
+		} else if alpha > 1 {
+			alpha = 1
+		}
+		if applied && alpha == last {
+			return
+		}
+		revert := 0
+		if applied {
+			revert = 1
+		}
+		rv.RevertAndFilter(revert, scaleAlpha(alpha))
+		applied = true
+		last = alpha
+	}), nil
+}
+
+// scaleAlpha multiplies the alpha of an image. As image.RGBA is premultiplied,
+// every channel is scaled.
+func scaleAlpha(alpha float64) mod.Filter {
+	return func(rgba *image.RGBA) {
+		bounds := rgba.Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			start := rgba.PixOffset(bounds.Min.X, y)
+			row := rgba.Pix[start : start+bounds.Dx()*4]
+			for i, v := range row {
+				row[i] = uint8(float64(v) * alpha)
+			}
+		}
+	}
+}
+
+// An AudioCue plays a file from the audio cache.
+type AudioCue struct {
+	At   time.Duration
+	File string
+}
+
+// An AudioTrack plays audio files. Files not already cached are loaded when
+// the track is bound.
+type AudioTrack struct {
+	Cues []AudioCue
+}
+
+// Duration satisfies Track.
+func (at *AudioTrack) Duration() time.Duration {
+	var d time.Duration
+	for _, c := range at.Cues {
+		if c.At > d {
+			d = c.At
+		}
+	}
+	return d
+}
+
+// Bind satisfies Track.
+func (at *AudioTrack) Bind(ctx *scene.Context, cast Cast) (Applier, error) {
+	for _, c := range at.Cues {
+		if _, err := audio.Get(c.File); err != nil {
+			if _, err := audio.Load(c.File); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ApplierFunc(func(from, to time.Duration, seeking bool) {
+		if seeking {
+			return
+		}
+		for _, c := range at.Cues {
+			if !reached(c.At, from, to) {
+				continue
+			}
+			r, err := audio.Get(c.File)
+			if err != nil {
+				dlog.Error("failed to get timeline audio:", err)
+				continue
+			}
+			go func() {
+				if err := audio.Play(ctx, r); err != nil {
+					dlog.Error("failed to play timeline audio:", err)
+				}
+			}()
+		}
+	}), nil
+}
+
+// An EventCue calls a trigger from a cast, or triggers Cued.
+type EventCue struct {
+	At   time.Duration
+	Name string
+	// OnSkip cues are also fired when seeked past.
+	OnSkip bool
+}
+
+// An EventTrack triggers events.
+type EventTrack struct {
+	Cues []EventCue
+}
+
+// Duration satisfies Track.
+func (et *EventTrack) Duration() time.Duration {
+	var d time.Duration
+	for _, c := range et.Cues {
+		if c.At > d {
+			d = c.At
+		}
+	}
+	return d
+}
+
+// Bind satisfies Track.
+func (et *EventTrack) Bind(ctx *scene.Context, cast Cast) (Applier, error) {
+	return ApplierFunc(func(from, to time.Duration, seeking bool) {
+		for _, c := range et.Cues {
+			if !reached(c.At, from, to) || (seeking && !c.OnSkip) {
+				continue
+			}
+			if trigger, ok := cast.Triggers[c.Name]; ok {
+				trigger(ctx)
+			} else {
+				event.TriggerOn(ctx, Cued, c.Name)
+			}
+		}
+	}), nil
+}
+
+// A TextCue shows text for a duration.
+type TextCue struct {
+	At       time.Duration
+	Duration time.Duration
+	Text     string
+	Position floatgeom.Point2
+}
+
+// A TextTrack shows text boxes.
+type TextTrack struct {
+	// Font names a font in the cast. If empty, render.DefaultFont is used.
+	Font string
+	// Layers are the layers text is drawn to. If empty, it is drawn to layer 0.
+	Layers []int
+	Cues   []TextCue
+}
+
+// Duration satisfies Track.
+func (tt *TextTrack) Duration() time.Duration {
+	var d time.Duration
+	for _, c := range tt.Cues {
+		if c.At+c.Duration > d {
+			d = c.At + c.Duration
+		}
+	}
+	return d
+}
+
+// Bind satisfies Track.
+func (tt *TextTrack) Bind(ctx *scene.Context, cast Cast) (Applier, error) {
+	font := render.DefaultFont()
+	if tt.Font != "" {
+		var ok bool
+		font, ok = cast.Fonts[tt.Font]
+		if !ok {
+			return nil, oakerr.NotFound{InputName: "Font:" + tt.Font}
+		}
+	}
+	texts := make([]*render.Text, len(tt.Cues))
+	for i, c := range tt.Cues {
+		texts[i] = font.NewText(c.Text, c.Position.X(), c.Position.Y())
+	}
+	layers := tt.Layers
+	if len(layers) == 0 {
+		layers = []int{0}
+	}
+	return &textApplier{
+		ctx:    ctx,
+		cues:   tt.Cues,
+		layers: layers,
+		texts:  texts,
+		shown:  make([]bool, len(tt.Cues)),
+	}, nil
+}
+
+type textApplier struct {
+	ctx    *scene.Context
+	cues   []TextCue
+	layers []int
+	texts  []*render.Text
+	shown  []bool
+}
+
+func (ta *textApplier) Apply(_, to time.Duration, _ bool) {
+	for i, c := range ta.cues {
+		show := to >= c.At && to < c.At+c.Duration
+		if show == ta.shown[i] {
+			continue
+		}
+		ta.shown[i] = show
+		if show {
+			ta.ctx.DrawStack.Draw(ta.texts[i], ta.layers...)
+		} else {
+			ta.texts[i].Undraw()
+		}
+	}
+}
+
+func (ta *textApplier) Close() {
+	for i, shown := range ta.shown {
+		if shown {
+			ta.texts[i].Undraw()
+			ta.shown[i] = false
+		}
+	}
+}
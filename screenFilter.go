@@ -13,7 +13,8 @@ func (w *Window) SetPalette(palette color.Palette) {
 }
 
 // SetDrawFilter will filter the screen by the given modification function prior
-// to publishing the screen's rgba to be displayed.
+// to publishing the screen's rgba to be displayed. For animated effects like
+// bloom or CRT scanlines, see the render/postfx package.
 func (w *Window) SetDrawFilter(screenFilter mod.Filter) {
 	w.prePublish = func(buf *image.RGBA) {
 		screenFilter(buf)